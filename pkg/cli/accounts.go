@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"context"
+	"log"
+
+	"github.com/pedrokiefer/route53copy/pkg/dns"
+	"github.com/spf13/cobra"
+)
+
+// accountSelector chooses the AWS accounts a command runs in. Without flags
+// it runs in the profile's own account; otherwise the profile's credentials
+// are used to assume a role in each target account.
+type accountSelector struct {
+	RoleARNs []string
+	OrgRole  string
+}
+
+func (s *accountSelector) addFlags(c *cobra.Command) {
+	f := c.Flags()
+	f.StringSliceVar(&s.RoleARNs, "role-arn", nil, "Role to assume in each target account, may be repeated")
+	f.StringVar(&s.OrgRole, "org-role", "", "Role name to assume in every active account of the profile's AWS Organization")
+	c.MarkFlagsMutuallyExclusive("role-arn", "org-role")
+}
+
+func (s *accountSelector) multiple() bool {
+	return len(s.RoleARNs) > 0 || s.OrgRole != ""
+}
+
+// accounts returns the accounts to run in. The zero Account stands for the
+// profile's own credentials.
+func (s *accountSelector) accounts(ctx context.Context, profile string) ([]dns.Account, error) {
+	switch {
+	case len(s.RoleARNs) > 0:
+		accounts := []dns.Account{}
+		for _, roleARN := range s.RoleARNs {
+			a, err := dns.AccountFromRoleARN(roleARN)
+			if err != nil {
+				return nil, err
+			}
+			accounts = append(accounts, a)
+		}
+		return accounts, nil
+	case s.OrgRole != "":
+		accounts, err := listOrgAccounts(ctx, awsOptions(profile), s.OrgRole)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("Found %d active accounts in the organization\n", len(accounts))
+		return accounts, nil
+	default:
+		return []dns.Account{{}}, nil
+	}
+}
+
+// accountLabel names an account in results: its ID, or the profile for the
+// profile's own account.
+func accountLabel(profile string, a dns.Account) string {
+	if a.ID != "" {
+		return a.ID
+	}
+	return profile
+}
+
+// routeManagerFor returns a RouteManager acting in the given account.
+func routeManagerFor(ctx context.Context, profile string, a dns.Account) (RouteManagerAPI, error) {
+	o := awsOptions(profile)
+	o.RoleARN = a.RoleARN
+	return newRouteManager(ctx, o, &dns.RouteManagerOptions{NoWait: noWait})
+}
@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	rtypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/pedrokiefer/route53copy/pkg/dns"
+	"github.com/pedrokiefer/route53copy/pkg/vuln"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountSelector_Accounts(t *testing.T) {
+	old := listOrgAccounts
+	t.Cleanup(func() { listOrgAccounts = old })
+
+	s := accountSelector{}
+	accounts, err := s.accounts(context.Background(), "p")
+	require.NoError(t, err)
+	require.Equal(t, []dns.Account{{}}, accounts)
+	require.Equal(t, "p", accountLabel("p", accounts[0]))
+
+	s = accountSelector{RoleARNs: []string{"arn:aws:iam::111111111111:role/dns"}}
+	accounts, err = s.accounts(context.Background(), "p")
+	require.NoError(t, err)
+	require.Equal(t, "111111111111", accountLabel("p", accounts[0]))
+
+	s = accountSelector{RoleARNs: []string{"not-an-arn"}}
+	_, err = s.accounts(context.Background(), "p")
+	require.Error(t, err)
+
+	var gotRole string
+	listOrgAccounts = func(ctx context.Context, o dns.AWSOptions, roleName string) ([]dns.Account, error) {
+		gotRole = roleName
+		return []dns.Account{{ID: "222222222222", RoleARN: "arn:aws:iam::222222222222:role/dns"}}, nil
+	}
+	s = accountSelector{OrgRole: "dns"}
+	accounts, err = s.accounts(context.Background(), "p")
+	require.NoError(t, err)
+	require.Equal(t, "dns", gotRole)
+	require.Len(t, accounts, 1)
+	require.True(t, s.multiple())
+}
+
+func TestCheckZone_Run_AcrossAccounts(t *testing.T) {
+	oldNewRM, oldDig, oldOut := newRouteManager, getNameserversFor, stdout
+	t.Cleanup(func() { newRouteManager = oldNewRM; getNameserversFor = oldDig; stdout = oldOut })
+
+	zone := rtypes.HostedZone{Id: aws.String("/hostedzone/Z1"), Name: aws.String("example.com.")}
+	fakes := map[string]*fakeRouteManager{
+		"arn:aws:iam::111111111111:role/dns": {
+			ZonesByName: map[string]rtypes.HostedZone{"example.com.": zone},
+			NSByID: map[string]rtypes.ResourceRecordSet{
+				"/hostedzone/Z1": {ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String("ns1.example.net.")}}},
+			},
+		},
+		"arn:aws:iam::222222222222:role/dns": {ZonesByName: map[string]rtypes.HostedZone{}},
+	}
+	newRouteManager = func(ctx context.Context, o dns.AWSOptions, rmo *dns.RouteManagerOptions) (RouteManagerAPI, error) {
+		return fakes[o.RoleARN], nil
+	}
+	getNameserversFor = func(domain string) ([]string, error) { return []string{"ns1.example.net"}, nil }
+	var buf bytes.Buffer
+	stdout = &buf
+
+	a := &checkZoneApp{
+		Profile:  "p",
+		Zones:    zoneSelector{Names: []string{"example.com"}},
+		Accounts: accountSelector{RoleARNs: []string{"arn:aws:iam::111111111111:role/dns", "arn:aws:iam::222222222222:role/dns"}},
+		Output:   outputJSON,
+	}
+	require.Error(t, a.Run(context.Background()))
+
+	var results []zoneCheckResult
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &results))
+	require.Len(t, results, 2)
+	require.Equal(t, "111111111111", results[0].Account)
+	require.Equal(t, zoneMatch, results[0].Status)
+	require.Equal(t, "222222222222", results[1].Account)
+	require.Equal(t, zoneCheckError, results[1].Status)
+}
+
+func TestVulnerabilityScan_Run_AcrossAccounts(t *testing.T) {
+	oldNewRM := newRouteManager
+	t.Cleanup(func() { newRouteManager = oldNewRM })
+	zone := func(id string) rtypes.HostedZone {
+		return rtypes.HostedZone{Id: aws.String(id), Name: aws.String("example.com.")}
+	}
+	fakes := map[string]*fakeRouteManager{
+		"arn:aws:iam::111111111111:role/dns": {ZonesByName: map[string]rtypes.HostedZone{"example.com.": zone("/hostedzone/Z1")}},
+		"arn:aws:iam::222222222222:role/dns": {ZonesByName: map[string]rtypes.HostedZone{"example.com.": zone("/hostedzone/Z2")}},
+	}
+	newRouteManager = func(ctx context.Context, o dns.AWSOptions, rmo *dns.RouteManagerOptions) (RouteManagerAPI, error) {
+		return fakes[o.RoleARN], nil
+	}
+	t.Chdir(t.TempDir())
+
+	a := &vulnerabilityScanApp{
+		Profile:     "p",
+		Zones:       zoneSelector{Names: []string{"example.com"}},
+		Accounts:    accountSelector{RoleARNs: []string{"arn:aws:iam::111111111111:role/dns", "arn:aws:iam::222222222222:role/dns"}},
+		ScanOptions: vuln.ScanOptions{SkipTLS: true},
+	}
+	require.NoError(t, a.Run(context.Background()))
+
+	reports, err := filepath.Glob("vuln-p-*.json")
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+	data, err := os.ReadFile(reports[0])
+	require.NoError(t, err)
+	var findings []vuln.Findings
+	require.NoError(t, json.Unmarshal(data, &findings))
+	accounts := map[string]string{}
+	for _, f := range findings {
+		accounts[f.ZoneID] = f.Account
+	}
+	require.Equal(t, map[string]string{"/hostedzone/Z1": "111111111111", "/hostedzone/Z2": "222222222222"}, accounts)
+}
+
+func TestExport_Run_RejectsOutputWithManyAccounts(t *testing.T) {
+	a := &exportApp{Profile: "p", Zone: "example.com", Output: "out.zone",
+		Accounts: accountSelector{OrgRole: "dns"}}
+	require.Error(t, a.Run(context.Background()))
+}
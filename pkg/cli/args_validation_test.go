@@ -66,6 +66,11 @@ func TestParkCommand_ArgsValidation(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestParkCommand_DualStackIsOptIn(t *testing.T) {
+	// Some alias targets, like S3 website endpoints, only take an A alias.
+	require.Equal(t, "false", newParkCommand().Flags().Lookup("dual-stack").DefValue)
+}
+
 func TestCheckZoneCommand_ArgsValidation(t *testing.T) {
 	c := newCheckZoneCmd()
 	_, err := runCmd(c, []string{})
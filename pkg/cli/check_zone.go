@@ -3,11 +3,15 @@ package cli
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
 	"log"
+	"strconv"
 	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	rtypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/olekukonko/tablewriter"
 	"github.com/pedrokiefer/route53copy/pkg/dig"
 	"github.com/pedrokiefer/route53copy/pkg/dns"
 	"github.com/spf13/cobra"
@@ -15,68 +19,160 @@ import (
 
 type checkZoneApp struct {
 	Profile    string
-	Domain     string
-	AllDomains bool
+	Zones      zoneSelector
+	Accounts   accountSelector
+	Delegation bool
+	Output     string
 
 	routeManager RouteManagerAPI
 }
 
+type zoneCheckStatus string
+
+const (
+	zoneMatch       zoneCheckStatus = "match"
+	zoneMismatch    zoneCheckStatus = "mismatch"
+	zoneUndelegated zoneCheckStatus = "undelegated"
+	zoneCheckError  zoneCheckStatus = "error"
+)
+
+// zoneCheckResult is what check-zone found for a single hosted zone.
+type zoneCheckResult struct {
+	Account    string                `json:"account" yaml:"account"`
+	Zone       string                `json:"zone" yaml:"zone"`
+	ZoneID     string                `json:"zone_id" yaml:"zone_id"`
+	LiveNS     []string              `json:"live_ns" yaml:"live_ns"`
+	Route53NS  []string              `json:"route53_ns" yaml:"route53_ns"`
+	Status     zoneCheckStatus       `json:"status" yaml:"status"`
+	Error      string                `json:"error,omitempty" yaml:"error,omitempty"`
+	Delegation *dig.DelegationReport `json:"delegation,omitempty" yaml:"delegation,omitempty"`
+}
+
+func (zoneCheckResult) columns() []string {
+	return []string{"Account", "Zone", "Zone ID", "Status", "Live NS", "Route53 NS", "Error"}
+}
+
+func (r zoneCheckResult) row() []string {
+	return []string{
+		r.Account,
+		r.Zone,
+		r.ZoneID,
+		string(r.Status),
+		strings.Join(r.LiveNS, " "),
+		strings.Join(r.Route53NS, " "),
+		r.Error,
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(newCheckZoneCmd())
 }
 
 func (a *checkZoneApp) Run(ctx context.Context) error {
-	a.routeManager = newRouteManager(ctx, a.Profile, &dns.RouteManagerOptions{
-		NoWait: noWait,
-	})
+	if a.Output == "" {
+		a.Output = outputTable
+	}
+	if err := validateOutputFormat(a.Output); err != nil {
+		return err
+	}
 
-	if a.Domain != "" {
-		zone, err := a.routeManager.GetHostedZone(ctx, a.Domain)
-		if err != nil {
-			return err
-		}
-		err = a.checkZone(ctx, zone)
+	accounts, err := a.Accounts.accounts(ctx, a.Profile)
+	if err != nil {
 		return err
 	}
 
-	if a.AllDomains {
-		zones, err := a.routeManager.ListHostedZones(ctx)
+	results := []zoneCheckResult{}
+	failed := 0
+	for _, acct := range accounts {
+		label := accountLabel(a.Profile, acct)
+		zones, err := a.selectZones(ctx, acct)
 		if err != nil {
-			return err
+			if !a.Accounts.multiple() {
+				return err
+			}
+			log.Printf("Skipping account %s: %s\n", label, err)
+			results = append(results, zoneCheckResult{
+				Account: label, LiveNS: []string{}, Route53NS: []string{},
+				Status: zoneCheckError, Error: err.Error(),
+			})
+			failed++
+			continue
 		}
 
 		for _, zone := range zones {
-			err := a.checkZone(ctx, zone)
-			if err != nil {
-				var nsr *dig.NSRecordNotFound
-				if errors.As(err, &nsr) {
-					continue
-				} else {
-					return err
-				}
+			r := a.checkZone(ctx, zone)
+			r.Account = label
+			if r.Status == zoneCheckError {
+				failed++
+			}
+			results = append(results, r)
+		}
+	}
+
+	if err := writeResults(stdout, a.Output, results); err != nil {
+		return err
+	}
+	if a.Output == outputTable {
+		for _, r := range results {
+			if r.Delegation != nil {
+				printDelegationReport(stdout, r.Delegation)
 			}
 		}
 	}
 
+	if failed > 0 {
+		return fmt.Errorf("%d of %d zones could not be checked", failed, len(results))
+	}
 	return nil
 }
 
-func (a *checkZoneApp) checkZone(ctx context.Context, zone rtypes.HostedZone) error {
+// selectZones connects to the account and selects its zones.
+func (a *checkZoneApp) selectZones(ctx context.Context, acct dns.Account) ([]rtypes.HostedZone, error) {
+	rm, err := routeManagerFor(ctx, a.Profile, acct)
+	if err != nil {
+		return nil, err
+	}
+	a.routeManager = rm
+	return a.Zones.Select(ctx, rm)
+}
+
+func (a *checkZoneApp) checkZone(ctx context.Context, zone rtypes.HostedZone) zoneCheckResult {
 	domain := aws.ToString(zone.Name)
 	zoneID := aws.ToString(zone.Id)
+	r := zoneCheckResult{Zone: domain, ZoneID: zoneID, LiveNS: []string{}, Route53NS: []string{}}
 
 	log.Printf("Checking %s ...\n", domain)
 
-	digNS, err := getNameserversFor(domain)
+	r53NS, err := a.routeManager.GetNSRecords(ctx, zoneID)
 	if err != nil {
-		log.Printf("no NS records found for %s zone %s", domain, zoneID)
-		return err
+		r.Status, r.Error = zoneCheckError, err.Error()
+		return r
+	}
+	for _, rr := range r53NS.ResourceRecords {
+		r.Route53NS = append(r.Route53NS, aws.ToString(rr.Value))
 	}
 
-	r53NS, err := a.routeManager.GetNSRecords(ctx, zoneID)
+	if a.Delegation {
+		report, err := auditDelegation(ctx, domain, r.Route53NS)
+		if err != nil {
+			r.Status, r.Error = zoneCheckError, err.Error()
+			return r
+		}
+		r.Delegation = report
+	}
+
+	digNS, err := getNameserversFor(domain)
 	if err != nil {
-		return err
+		var nsr *dig.NSRecordNotFound
+		if errors.As(err, &nsr) {
+			log.Printf("no NS records found for %s zone %s", domain, zoneID)
+			r.Status = zoneUndelegated
+			return r
+		}
+		r.Status, r.Error = zoneCheckError, err.Error()
+		return r
 	}
+	r.LiveNS = digNS
 
 	if !dns.MatchNSRecords(digNS, r53NS) {
 		log.Printf("%s zone %s has different NS servers:\n - nameservers: %s\n - zone record: %s",
@@ -85,19 +181,58 @@ func (a *checkZoneApp) checkZone(ctx context.Context, zone rtypes.HostedZone) er
 			strings.Join(digNS, ","),
 			nsRecordsToString(r53NS),
 		)
-		return nil
+		r.Status = zoneMismatch
+		return r
 	}
 
 	log.Printf("DNS entries and Route53 NS records match.")
+	r.Status = zoneMatch
+	return r
+}
 
-	return nil
+func printDelegationReport(w io.Writer, r *dig.DelegationReport) {
+	fmt.Fprintf(w, "Delegation of %s from %s: %s\n", r.Zone, r.ParentZone, strings.Join(r.ParentNS, ", "))
+
+	table := tablewriter.NewWriter(w)
+	table.Header([]string{"Nameserver", "Addresses", "Glue", "Authoritative", "Serial", "NS", "Error"})
+	for _, s := range r.Servers {
+		serial := ""
+		if s.Serial != 0 {
+			serial = strconv.FormatUint(uint64(s.Serial), 10)
+		}
+		glue := "no"
+		if len(r.Glue[s.Name]) > 0 {
+			glue = "yes"
+		}
+		_ = table.Append([]string{
+			s.Name,
+			strings.Join(s.Addresses, "\n"),
+			glue,
+			strconv.FormatBool(s.Authoritative),
+			serial,
+			strings.Join(s.NS, "\n"),
+			s.Error,
+		})
+	}
+	_ = table.Render()
+
+	if len(r.Issues) == 0 {
+		fmt.Fprintln(w, "No delegation issues found.")
+		return
+	}
+	issues := tablewriter.NewWriter(w)
+	issues.Header([]string{"Issue", "Detail"})
+	for _, i := range r.Issues {
+		_ = issues.Append([]string{string(i.Kind), i.Detail})
+	}
+	_ = issues.Render()
 }
 
 func newCheckZoneCmd() *cobra.Command {
 	a := checkZoneApp{}
 
 	c := &cobra.Command{
-		Use:   "check-zone [-d domain | -a] profile",
+		Use:   "check-zone [--zone domain | --all | selectors] profile",
 		Short: "Check if a zone exists",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -108,9 +243,11 @@ func newCheckZoneCmd() *cobra.Command {
 		SilenceUsage:  true,
 	}
 	f := c.Flags()
-	f.BoolVar(&a.AllDomains, "a", true, "Check all domains")
-	f.StringVar(&a.Domain, "d", "", "Check a specific domain")
-	c.MarkFlagsMutuallyExclusive("a", "d")
+	f.StringVarP(&a.Output, "output", "o", outputTable, "Output format: table, json, yaml or csv")
+	f.BoolVar(&a.Delegation, "delegation", false, "Audit the delegation from the parent zone and query each nameserver directly")
+	a.Zones.addFlags(c)
+	a.Accounts.addFlags(c)
+	a.Zones.addLegacyFlags(c, true)
 
 	return c
 }
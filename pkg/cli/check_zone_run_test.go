@@ -1,11 +1,14 @@
 package cli
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"testing"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	rtypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/pedrokiefer/route53copy/pkg/dig"
 	"github.com/pedrokiefer/route53copy/pkg/dns"
 	"github.com/stretchr/testify/require"
 )
@@ -21,11 +24,99 @@ func TestCheckZone_Run_MismatchLogsButNoError(t *testing.T) {
 			"/hostedzone/Z1": {ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String("ns2.example.net.")}}},
 		},
 	}
-	newRouteManager = func(ctx context.Context, profile string, rmo *dns.RouteManagerOptions) RouteManagerAPI { return fake }
+	newRouteManager = func(ctx context.Context, o dns.AWSOptions, rmo *dns.RouteManagerOptions) (RouteManagerAPI, error) {
+		return fake, nil
+	}
 	getNameserversFor = func(domain string) ([]string, error) { return []string{"ns1.example.net"}, nil }
 
-	a := &checkZoneApp{Profile: "p", Domain: "example.com."}
+	a := &checkZoneApp{Profile: "p", Zones: zoneSelector{Names: []string{"example.com."}}}
 	err := a.Run(context.Background())
 	// Mismatch should log and return nil
 	require.NoError(t, err)
 }
+
+func TestCheckZone_Run_DelegationAudit(t *testing.T) {
+	oldNewRM := newRouteManager
+	oldDig := getNameserversFor
+	oldAudit := auditDelegation
+	t.Cleanup(func() { newRouteManager = oldNewRM; getNameserversFor = oldDig; auditDelegation = oldAudit })
+
+	fake := &fakeRouteManager{
+		HostedZone: rtypes.HostedZone{Id: aws.String("/hostedzone/Z1"), Name: aws.String("example.com.")},
+		NSByID: map[string]rtypes.ResourceRecordSet{
+			"/hostedzone/Z1": {ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String("ns1.example.net.")}}},
+		},
+	}
+	newRouteManager = func(ctx context.Context, o dns.AWSOptions, rmo *dns.RouteManagerOptions) (RouteManagerAPI, error) {
+		return fake, nil
+	}
+	getNameserversFor = func(domain string) ([]string, error) { return []string{"ns1.example.net."}, nil }
+
+	var gotNS []string
+	auditDelegation = func(ctx context.Context, zone string, expectedNS []string) (*dig.DelegationReport, error) {
+		gotNS = expectedNS
+		return &dig.DelegationReport{
+			Zone:       zone,
+			ParentZone: "net.",
+			Servers:    []dig.NameserverCheck{{Name: "ns1.example.net.", Authoritative: true, Serial: 1}},
+		}, nil
+	}
+
+	a := &checkZoneApp{Profile: "p", Zones: zoneSelector{Names: []string{"example.com."}}, Delegation: true}
+	require.NoError(t, a.Run(context.Background()))
+	require.Equal(t, []string{"ns1.example.net."}, gotNS)
+}
+
+func TestPrintDelegationReport(t *testing.T) {
+	var buf bytes.Buffer
+	printDelegationReport(&buf, &dig.DelegationReport{
+		Zone:       "example.com.",
+		ParentZone: "com.",
+		ParentNS:   []string{"ns1.example.net."},
+		Servers: []dig.NameserverCheck{
+			{Name: "ns1.example.net.", Addresses: []string{"192.0.2.1"}, Authoritative: true, Serial: 42},
+		},
+		Issues: []dig.DelegationIssue{{Kind: dig.IssueLameServer, Detail: "ns2.example.net. answers REFUSED"}},
+	})
+	out := buf.String()
+	require.Contains(t, out, "Delegation of example.com. from com.")
+	require.Contains(t, out, "42")
+	require.Contains(t, out, "lame_server")
+}
+
+func TestCheckZone_Run_ReportsUndelegatedZones(t *testing.T) {
+	oldNewRM, oldDig, oldOut := newRouteManager, getNameserversFor, stdout
+	t.Cleanup(func() { newRouteManager = oldNewRM; getNameserversFor = oldDig; stdout = oldOut })
+
+	fake := &fakeRouteManager{
+		Zones: []rtypes.HostedZone{
+			{Id: aws.String("/hostedzone/Z1"), Name: aws.String("example.com.")},
+			{Id: aws.String("/hostedzone/Z2"), Name: aws.String("gone.com.")},
+		},
+		NSByID: map[string]rtypes.ResourceRecordSet{
+			"/hostedzone/Z1": {ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String("ns1.example.net.")}}},
+			"/hostedzone/Z2": {ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String("ns1.example.net.")}}},
+		},
+	}
+	newRouteManager = func(ctx context.Context, o dns.AWSOptions, rmo *dns.RouteManagerOptions) (RouteManagerAPI, error) {
+		return fake, nil
+	}
+	getNameserversFor = func(domain string) ([]string, error) {
+		if domain == "gone.com." {
+			return nil, &dig.NSRecordNotFound{Domain: domain}
+		}
+		return []string{"ns1.example.net"}, nil
+	}
+	var buf bytes.Buffer
+	stdout = &buf
+
+	a := &checkZoneApp{Profile: "p", Zones: zoneSelector{All: true}, Output: outputJSON}
+	require.NoError(t, a.Run(context.Background()))
+
+	var results []zoneCheckResult
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &results))
+	require.Len(t, results, 2)
+	require.Equal(t, zoneMatch, results[0].Status)
+	require.Equal(t, zoneUndelegated, results[1].Status)
+	require.Equal(t, "/hostedzone/Z2", results[1].ZoneID)
+}
@@ -27,9 +27,13 @@ func init() {
 
 func (a *cleanupZoneApp) Run(ctx context.Context) error {
 
-	a.routeManager = newRouteManager(ctx, a.Profile, &dns.RouteManagerOptions{
+	var err error
+	a.routeManager, err = newRouteManager(ctx, awsOptions(a.Profile), &dns.RouteManagerOptions{
 		NoWait: noWait,
 	})
+	if err != nil {
+		return err
+	}
 
 	zone, err := a.routeManager.GetHostedZone(ctx, a.Domain)
 	if err != nil {
@@ -186,7 +190,7 @@ func newCleanupZoneCmd() *cobra.Command {
 	a := cleanupZoneApp{}
 
 	c := &cobra.Command{
-		Use:   "cleanup-zone <profile> <domain>",
+		Use:   "cleanup-zone <profile> <domain|zone_id>",
 		Short: "Check if a zone exists",
 		Args:  cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
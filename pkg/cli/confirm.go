@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/manifoldco/promptui"
+)
+
+// errConfirmationNeeded is returned when a command needs a yes but nobody
+// can be asked.
+var errConfirmationNeeded = errors.New("confirmation needed")
+
+// confirmPolicy decides how commands get a yes before changes they cannot
+// undo. Every confirmation goes through it, so --yes and --no-input apply
+// to all commands alike.
+type confirmPolicy struct {
+	// Yes answers every confirmation with yes.
+	Yes bool
+	// NoInput never prompts: confirmations fail unless Yes is set.
+	NoInput bool
+}
+
+// confirmation returns the policy set by the global flags.
+func confirmation() confirmPolicy {
+	return confirmPolicy{Yes: assumeYes, NoInput: noInput}
+}
+
+// Confirm asks label as a yes/no question. It returns false when the answer
+// is no, and errConfirmationNeeded when there is no terminal to ask on or
+// --no-input is set, so unattended runs stop rather than carry on.
+func (p confirmPolicy) Confirm(label string) (bool, error) {
+	if p.Yes {
+		log.Printf("%s yes (--yes)\n", label)
+		return true, nil
+	}
+	if p.NoInput {
+		return false, fmt.Errorf("%w for %q: --no-input is set; pass --yes to go ahead", errConfirmationNeeded, label)
+	}
+	if !stdinIsTerminal() {
+		return false, fmt.Errorf("%w for %q: stdin is not a terminal; pass --yes to go ahead", errConfirmationNeeded, label)
+	}
+
+	result, err := promptConfirm(label, true)
+	if errors.Is(err, promptui.ErrAbort) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("prompt failed: %w", err)
+	}
+	return strings.EqualFold(result, "y"), nil
+}
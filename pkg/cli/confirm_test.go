@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	rtypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/manifoldco/promptui"
+	"github.com/stretchr/testify/require"
+)
+
+// stubPrompt answers confirmations with answer and err on a terminal that
+// is there or not, and counts the prompts shown.
+func stubPrompt(t *testing.T, terminal bool, answer string, err error) *int {
+	t.Helper()
+	oldPrompt, oldTerminal := promptConfirm, stdinIsTerminal
+	t.Cleanup(func() { promptConfirm = oldPrompt; stdinIsTerminal = oldTerminal })
+	prompts := 0
+	promptConfirm = func(label string, isConfirm bool) (string, error) {
+		prompts++
+		return answer, err
+	}
+	stdinIsTerminal = func() bool { return terminal }
+	return &prompts
+}
+
+func TestConfirmPolicy_Confirm(t *testing.T) {
+	prompts := stubPrompt(t, true, "y", nil)
+	ok, err := confirmPolicy{}.Confirm("Go?")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, 1, *prompts)
+
+	ok, err = confirmPolicy{Yes: true, NoInput: true}.Confirm("Go?")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, 1, *prompts, "--yes does not prompt")
+
+	_, err = confirmPolicy{NoInput: true}.Confirm("Go?")
+	require.ErrorIs(t, err, errConfirmationNeeded)
+	require.Equal(t, 1, *prompts)
+
+	stubPrompt(t, true, "", promptui.ErrAbort)
+	ok, err = confirmPolicy{}.Confirm("Go?")
+	require.NoError(t, err, "answering no is not an error")
+	require.False(t, ok)
+
+	stubPrompt(t, true, "", promptui.ErrInterrupt)
+	_, err = confirmPolicy{}.Confirm("Go?")
+	require.ErrorIs(t, err, promptui.ErrInterrupt)
+
+	prompts = stubPrompt(t, false, "y", nil)
+	_, err = confirmPolicy{}.Confirm("Go?")
+	require.EqualError(t, err, `confirmation needed for "Go?": stdin is not a terminal; pass --yes to go ahead`)
+	require.Zero(t, *prompts)
+}
+
+func TestPark_EndToEnd_NoTerminalChangesNothing(t *testing.T) {
+	srv := fakeAccounts(t, "p")["p"]
+	zoneID := srv.AddZone("example.com")
+	require.NoError(t, srv.AddRecordSets(zoneID,
+		rtypes.ResourceRecordSet{Name: aws.String("example.com."), Type: rtypes.RRTypeA, TTL: aws.Int64(300),
+			ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String("192.0.2.1")}}},
+		rtypes.ResourceRecordSet{Name: aws.String("www.example.com."), Type: rtypes.RRTypeCname, TTL: aws.Int64(300),
+			ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String("example.com.")}}},
+	))
+	before := srv.RecordSets(zoneID)
+	stubPrompt(t, false, "y", nil)
+
+	a := &parkApp{
+		Profile: "p", Alias: true, Force: true, Template: "minimal",
+		Hostname: "d111.cloudfront.net.", ZoneID: "Z2FDTNDATAQYW2",
+		Zones: zoneSelector{Names: []string{"example.com"}}, StateDir: t.TempDir(),
+	}
+	err := a.Run(context.Background())
+	require.ErrorIs(t, err, errConfirmationNeeded)
+	require.Equal(t, before, srv.RecordSets(zoneID))
+	require.Empty(t, srv.Tags(zoneID))
+
+	oldYes := assumeYes
+	t.Cleanup(func() { assumeYes = oldYes })
+	assumeYes = true
+	require.NoError(t, a.Run(context.Background()))
+	require.Equal(t, map[string]string{"parked": "true"}, srv.Tags(zoneID))
+}
+
+func TestRootCommand_ConfirmationFlags(t *testing.T) {
+	oldYes, oldNoInput := assumeYes, noInput
+	t.Cleanup(func() { assumeYes, noInput = oldYes, oldNoInput })
+	c := newRootCmd()
+	require.NoError(t, c.ParseFlags([]string{"-y", "--no-input"}))
+	require.Equal(t, confirmPolicy{Yes: true, NoInput: true}, confirmation())
+}
@@ -2,7 +2,10 @@ package cli
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -16,6 +19,13 @@ type copyApp struct {
 	DestinationProfile string
 	Domain             string
 	UpdateNS           bool
+
+	// Source picks the zone to copy when several share the name.
+	Source zoneFilterFlags
+	// VPCMap and RegionMap translate the source zone's VPC associations to
+	// the destination account.
+	VPCMap    map[string]string
+	RegionMap map[string]string
 }
 
 func init() {
@@ -23,30 +33,49 @@ func init() {
 }
 
 func (a *copyApp) Run(ctx context.Context) error {
-	srcService := newRouteManager(ctx, a.SourceProfile, &dns.RouteManagerOptions{
+	srcService, err := newRouteManager(ctx, awsOptions(a.SourceProfile), &dns.RouteManagerOptions{
 		NoWait: noWait,
 	})
-	dstService := newRouteManager(ctx, a.DestinationProfile, &dns.RouteManagerOptions{
+	if err != nil {
+		return err
+	}
+	dstService, err := newRouteManager(ctx, awsOptions(a.DestinationProfile), &dns.RouteManagerOptions{
 		NoWait: noWait,
 	})
+	if err != nil {
+		return err
+	}
 
-	zone, err := srcService.GetHostedZone(ctx, a.Domain)
+	zone, err := srcService.FindHostedZone(ctx, a.Domain, a.Source.zoneFilter())
 	if err != nil {
 		return err
 	}
 	srcZoneID := aws.ToString(zone.Id)
+	// The zone may have been given by ID, from here on use its name.
+	domain := aws.ToString(zone.Name)
+
+	createOpts := dns.CreateZoneOptions{}
+	if dns.IsPrivateZone(zone) {
+		vpcs, err := srcService.GetZoneVPCs(ctx, srcZoneID)
+		if err != nil {
+			return err
+		}
+		createOpts = dns.CreateZoneOptions{Private: true, VPCs: a.mapVPCs(vpcs)}
+		log.Printf("'%s' is a private zone associated with %s; destination will use %s\n",
+			domain, vpcsToString(vpcs), vpcsToString(createOpts.VPCs))
+	}
 
 	recordSets, err := srcService.GetResourceRecords(ctx, srcZoneID)
 	if err != nil {
 		return err
 	}
 
-	changes := srcService.CreateChanges(a.Domain, recordSets)
+	changes := srcService.CreateChanges(domain, recordSets)
 	log.Println("Number of records to copy", len(changes))
 
 	if dryRun {
 		log.Printf("Not copying records to %s since --dry is given\n", a.DestinationProfile)
-		zone, err := dstService.GetHostedZone(ctx, a.Domain)
+		zone, err := dstService.FindHostedZone(ctx, domain, createOpts.Filter())
 		if err != nil {
 			return err
 		}
@@ -54,19 +83,25 @@ func (a *copyApp) Run(ctx context.Context) error {
 		log.Printf("Destination profile contains %d records, including NS and SOA\n",
 			*zone.ResourceRecordSetCount)
 	} else {
-		zone, err := dstService.GetOrCreateZone(ctx, a.Domain)
+		zone, err := dstService.GetOrCreateZone(ctx, domain, createOpts)
 		if err != nil {
 			return err
 		}
 		dstZoneID := aws.ToString(zone.Id)
 
+		if createOpts.Private {
+			if err := associateVPCs(ctx, dstService, dstZoneID, createOpts.VPCs); err != nil {
+				return err
+			}
+		}
+
 		if len(changes) > 0 {
 			changeInfo, err := dstService.UpdateRecords(ctx, "Importing ALL records from "+a.SourceProfile, dstZoneID, changes)
 			if err != nil {
 				return err
 			}
 			log.Printf("%d records in '%s' were copied from %s to %s\n",
-				len(changes), a.Domain, a.SourceProfile, a.DestinationProfile)
+				len(changes), domain, a.SourceProfile, a.DestinationProfile)
 
 			if changeInfo.Status != rtypes.ChangeStatusInsync {
 				start := time.Now()
@@ -74,33 +109,82 @@ func (a *copyApp) Run(ctx context.Context) error {
 				if err != nil {
 					return err
 				}
-				log.Printf("%d records in '%s' are in sync after %s\n", len(changes), a.Domain, time.Since(start))
+				log.Printf("%d records in '%s' are in sync after %s\n", len(changes), domain, time.Since(start))
 			}
 		} else {
-			log.Printf("No records to copy for '%s'\n", a.Domain)
+			log.Printf("No records to copy for '%s'\n", domain)
 		}
 
-		if a.UpdateNS {
+		if a.UpdateNS && createOpts.Private {
+			log.Printf("'%s' is a private zone, registrar NS records are left alone\n", domain)
+		} else if a.UpdateNS {
 			log.Println("Updating NS records")
-			updated, err := dstService.UpdateNSRecords(ctx, a.Domain, dstZoneID)
+			// Route53 Domains wants the name without the trailing dot.
+			updated, err := dstService.UpdateNSRecords(ctx, dns.DenormalizeDomain(domain), dstZoneID)
 			if err != nil {
 				return err
 			}
 
 			if updated {
-				log.Printf("Registrar NS records for '%s' updated\n", a.Domain)
+				log.Printf("Registrar NS records for '%s' updated\n", domain)
 			} else {
-				log.Printf("Registrar NS records for '%s' are already up to date\n", a.Domain)
+				log.Printf("Registrar NS records for '%s' are already up to date\n", domain)
 			}
 		}
 	}
 	return nil
 }
 
+// mapVPCs translates source VPC associations with --vpc-map and
+// --region-map. Unmapped VPCs keep their ID, which only works when the
+// destination account can use the same VPC.
+func (a *copyApp) mapVPCs(vpcs []dns.VPC) []dns.VPC {
+	mapped := []dns.VPC{}
+	for _, v := range vpcs {
+		m := v
+		if r, ok := a.RegionMap[v.Region]; ok {
+			m.Region = r
+		}
+		if id, ok := a.VPCMap[v.ID]; ok {
+			m.ID = id
+		} else if a.SourceProfile != a.DestinationProfile {
+			log.Printf("No --vpc-map entry for %s, associating the same VPC in %s\n", v.ID, a.DestinationProfile)
+		}
+		mapped = append(mapped, m)
+	}
+	return mapped
+}
+
+// associateVPCs associates the VPCs the zone is still missing.
+func associateVPCs(ctx context.Context, rm RouteManagerAPI, zoneID string, vpcs []dns.VPC) error {
+	existing, err := rm.GetZoneVPCs(ctx, zoneID)
+	if err != nil {
+		return err
+	}
+	for _, v := range vpcs {
+		if slices.Contains(existing, v) {
+			continue
+		}
+		log.Printf("Associating %s with %s\n", v, zoneID)
+		if err := rm.AssociateVPC(ctx, zoneID, v); err != nil {
+			return fmt.Errorf("failed to associate %s: %w", v, err)
+		}
+	}
+	return nil
+}
+
+func vpcsToString(vpcs []dns.VPC) string {
+	str := []string{}
+	for _, v := range vpcs {
+		str = append(str, v.String())
+	}
+	return strings.Join(str, ", ")
+}
+
 func newCopyCommand() *cobra.Command {
 	a := &copyApp{}
 	c := &cobra.Command{
-		Use:   "copy <source_profile> <dest_profile> <domain>",
+		Use:   "copy <source_profile> <dest_profile> <domain|zone_id>",
 		Short: "Copy is a tool to copy records from one AWS account to another",
 		Args:  cobra.MinimumNArgs(3),
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -114,5 +198,8 @@ func newCopyCommand() *cobra.Command {
 	}
 	f := c.Flags()
 	f.BoolVar(&a.UpdateNS, "update-ns", false, "Update nameserver records")
+	f.StringToStringVar(&a.VPCMap, "vpc-map", nil, "Destination VPC for each source VPC of a private zone, as src=dst")
+	f.StringToStringVar(&a.RegionMap, "region-map", nil, "Destination region for each source VPC region, as src=dst")
+	a.Source.addFlags(c)
 	return c
 }
@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	rtypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/pedrokiefer/route53copy/pkg/dns"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCopy_Run_PrivateZoneRemapsVPCs(t *testing.T) {
+	oldNewRM := newRouteManager
+	t.Cleanup(func() { newRouteManager = oldNewRM })
+
+	private := &rtypes.HostedZoneConfig{PrivateZone: true}
+	src := &fakeRouteManager{
+		HostedZone: rtypes.HostedZone{Id: aws.String("/hostedzone/SRC"), Name: aws.String("corp.internal."), Config: private},
+		VPCsByID: map[string][]dns.VPC{
+			"/hostedzone/SRC": {{ID: "vpc-src1", Region: "us-east-1"}, {ID: "vpc-src2", Region: "us-west-2"}},
+		},
+	}
+	dst := &fakeRouteManager{
+		HostedZone: rtypes.HostedZone{Id: aws.String("/hostedzone/DST"), Name: aws.String("corp.internal."), Config: private},
+		VPCsByID: map[string][]dns.VPC{
+			"/hostedzone/DST": {{ID: "vpc-dst1", Region: "eu-west-1"}},
+		},
+	}
+	newRouteManager = func(ctx context.Context, o dns.AWSOptions, rmo *dns.RouteManagerOptions) (RouteManagerAPI, error) {
+		if o.Profile == "src" {
+			return src, nil
+		}
+		return dst, nil
+	}
+
+	a := &copyApp{
+		SourceProfile:      "src",
+		DestinationProfile: "dst",
+		Domain:             "corp.internal.",
+		UpdateNS:           true,
+		VPCMap:             map[string]string{"vpc-src1": "vpc-dst1", "vpc-src2": "vpc-dst2"},
+		RegionMap:          map[string]string{"us-east-1": "eu-west-1"},
+	}
+	require.NoError(t, a.Run(context.Background()))
+
+	require.Equal(t, &dns.CreateZoneOptions{Private: true, VPCs: []dns.VPC{
+		{ID: "vpc-dst1", Region: "eu-west-1"},
+		{ID: "vpc-dst2", Region: "us-west-2"},
+	}}, dst.CreateZoneOptions)
+	// vpc-dst1 is already associated, only the second one is added.
+	require.Equal(t, []dns.VPC{{ID: "vpc-dst2", Region: "us-west-2"}}, dst.AssociatedVPCs)
+	// Private zones have no registrar delegation to update.
+	require.Empty(t, dst.UpdatedNSDomains)
+}
+
+func TestCopy_Run_PublicZone(t *testing.T) {
+	oldNewRM := newRouteManager
+	t.Cleanup(func() { newRouteManager = oldNewRM })
+
+	fake := &fakeRouteManager{
+		HostedZone: rtypes.HostedZone{Id: aws.String("/hostedzone/Z1"), Name: aws.String("example.com.")},
+	}
+	newRouteManager = func(ctx context.Context, o dns.AWSOptions, rmo *dns.RouteManagerOptions) (RouteManagerAPI, error) {
+		return fake, nil
+	}
+
+	a := &copyApp{SourceProfile: "src", DestinationProfile: "dst", Domain: "example.com."}
+	require.NoError(t, a.Run(context.Background()))
+	require.Equal(t, &dns.CreateZoneOptions{}, fake.CreateZoneOptions)
+	require.Empty(t, fake.AssociatedVPCs)
+}
+
+func TestCopy_Run_UpdateNS(t *testing.T) {
+	oldNewRM := newRouteManager
+	t.Cleanup(func() { newRouteManager = oldNewRM })
+
+	src := &fakeRouteManager{HostedZone: rtypes.HostedZone{Id: aws.String("/hostedzone/SRC"), Name: aws.String("example.com.")}}
+	dst := &fakeRouteManager{HostedZone: rtypes.HostedZone{Id: aws.String("/hostedzone/DST"), Name: aws.String("example.com.")}}
+	newRouteManager = func(ctx context.Context, o dns.AWSOptions, rmo *dns.RouteManagerOptions) (RouteManagerAPI, error) {
+		if o.Profile == "src" {
+			return src, nil
+		}
+		return dst, nil
+	}
+
+	// Given by ID, so the name comes from the zone, trailing dot included.
+	a := &copyApp{SourceProfile: "src", DestinationProfile: "dst", Domain: "/hostedzone/SRC", UpdateNS: true}
+	require.NoError(t, a.Run(context.Background()))
+	require.Equal(t, []string{"example.com"}, dst.UpdatedNSDomains, "Route53 Domains rejects names with a trailing dot")
+}
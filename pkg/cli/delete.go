@@ -3,7 +3,6 @@ package cli
 import (
 	"context"
 	"errors"
-	"fmt"
 	"log"
 	"strings"
 	"time"
@@ -19,6 +18,8 @@ type deleteApp struct {
 	Profile string
 	Domain  string
 	Force   bool
+
+	Filter zoneFilterFlags
 }
 
 func init() {
@@ -26,26 +27,34 @@ func init() {
 }
 
 func (a *deleteApp) Run(ctx context.Context) error {
-	srcManager := newRouteManager(ctx, a.Profile, &dns.RouteManagerOptions{
+	srcManager, err := newRouteManager(ctx, awsOptions(a.Profile), &dns.RouteManagerOptions{
 		NoWait: noWait,
 	})
+	if err != nil {
+		return err
+	}
 
-	zone, err := srcManager.GetHostedZone(ctx, a.Domain)
+	zone, err := srcManager.FindHostedZone(ctx, a.Domain, a.Filter.zoneFilter())
 	if err != nil {
 		return err
 	}
 	srcZoneID := aws.ToString(zone.Id)
+	// The zone may have been given by ID, from here on use its name.
+	domain := aws.ToString(zone.Name)
+	if err := reportVPCs(ctx, srcManager, zone); err != nil {
+		return err
+	}
 
 	recordSets, err := srcManager.GetResourceRecords(ctx, srcZoneID)
 	if err != nil {
 		return err
 	}
 
-	ns, err := getNameserversFor(a.Domain)
+	ns, err := getNameserversFor(domain)
 	if err != nil {
 		var nsr *dig.NSRecordNotFound
 		if errors.As(err, &nsr) {
-			log.Println("No NS records found for", a.Domain)
+			log.Println("No NS records found for", domain)
 			a.Force = true
 		} else {
 			return err
@@ -60,12 +69,12 @@ func (a *deleteApp) Run(ctx context.Context) error {
 	log.Printf("Route53 has NS servers: %s\n", nsRecordsToString(nsRecords))
 
 	if dns.MatchNSRecords(ns, nsRecords) && !a.Force {
-		log.Printf("Nameservers for %s match, not deleting zone\n", a.Domain)
+		log.Printf("Nameservers for %s match, not deleting zone\n", domain)
 		return nil
 	}
 
 	recordSets = dns.RemoveResourceRecordsWithTypes(recordSets, []rtypes.RRType{rtypes.RRTypeNs, rtypes.RRTypeSoa})
-	log.Printf("Found %d records for domain %s to delete\n", len(recordSets), a.Domain)
+	log.Printf("Found %d records for domain %s to delete\n", len(recordSets), domain)
 	dns.PrintResourceRecords(recordSets)
 
 	if dryRun {
@@ -73,13 +82,11 @@ func (a *deleteApp) Run(ctx context.Context) error {
 		return nil
 	}
 
-	result, err := promptConfirm("Delete all records?", true)
+	ok, err := confirmation().Confirm("Delete all records?")
 	if err != nil {
-		fmt.Printf("Prompt failed %v\n", err)
-		return nil
+		return err
 	}
-
-	if result != "y" {
+	if !ok {
 		log.Printf("Aborting\n")
 		return nil
 	}
@@ -96,9 +103,9 @@ func (a *deleteApp) Run(ctx context.Context) error {
 			return err
 		}
 
-		log.Printf("Deleted all records for domain %s\n", a.Domain)
+		log.Printf("Deleted all records for domain %s\n", domain)
 	} else {
-		log.Printf("No records to delete for domain %s\n", a.Domain)
+		log.Printf("No records to delete for domain %s\n", domain)
 	}
 	log.Printf("Removing zoneId %s...\n", srcZoneID)
 
@@ -121,7 +128,7 @@ func newDeleteCommand() *cobra.Command {
 	a := deleteApp{}
 
 	c := &cobra.Command{
-		Use:   "delete <source_profile> <domain>",
+		Use:   "delete <source_profile> <domain|zone_id>",
 		Short: "Delete is a tool to safely remove a zone and records from Route53",
 		Args:  cobra.MinimumNArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -134,9 +141,23 @@ func newDeleteCommand() *cobra.Command {
 	}
 	f := c.Flags()
 	f.BoolVar(&a.Force, "force", false, "Force delete")
+	a.Filter.addFlags(c)
 	return c
 }
 
+// reportVPCs logs the VPC associations of a private zone.
+func reportVPCs(ctx context.Context, rm RouteManagerAPI, zone rtypes.HostedZone) error {
+	if !dns.IsPrivateZone(zone) {
+		return nil
+	}
+	vpcs, err := rm.GetZoneVPCs(ctx, aws.ToString(zone.Id))
+	if err != nil {
+		return err
+	}
+	log.Printf("%s %s is a private zone associated with: %s\n", aws.ToString(zone.Name), aws.ToString(zone.Id), vpcsToString(vpcs))
+	return nil
+}
+
 func nsRecordsToString(rs rtypes.ResourceRecordSet) string {
 	str := []string{}
 	for _, n := range rs.ResourceRecords {
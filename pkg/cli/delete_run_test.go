@@ -25,7 +25,9 @@ func TestDelete_Run_SkipsWhenNSMatchAndNoForce(t *testing.T) {
 		HostedZone:  rtypes.HostedZone{Id: aws.String("/hostedzone/Z1"), Name: aws.String("example.com.")},
 		RecordsByID: map[string][]rtypes.ResourceRecordSet{"/hostedzone/Z1": {nsRS}},
 	}
-	newRouteManager = func(ctx context.Context, profile string, rmo *dns.RouteManagerOptions) RouteManagerAPI { return fake }
+	newRouteManager = func(ctx context.Context, o dns.AWSOptions, rmo *dns.RouteManagerOptions) (RouteManagerAPI, error) {
+		return fake, nil
+	}
 	getNameserversFor = func(domain string) ([]string, error) { return []string{"ns1.example.net"}, nil }
 
 	a := &deleteApp{Profile: "p", Domain: "example.com."}
@@ -39,7 +41,13 @@ func TestDelete_Run_ForceDeletesWhenNoNS(t *testing.T) {
 	oldNewRM := newRouteManager
 	oldDig := getNameserversFor
 	oldPrompt := promptConfirm
-	t.Cleanup(func() { newRouteManager = oldNewRM; getNameserversFor = oldDig; promptConfirm = oldPrompt })
+	oldTerminal := stdinIsTerminal
+	t.Cleanup(func() {
+		newRouteManager = oldNewRM
+		getNameserversFor = oldDig
+		promptConfirm = oldPrompt
+		stdinIsTerminal = oldTerminal
+	})
 
 	rr := rtypes.ResourceRecordSet{Name: aws.String("example.com."), Type: rtypes.RRTypeA, ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String("1.2.3.4")}}}
 	nsRS := rtypes.ResourceRecordSet{
@@ -51,9 +59,12 @@ func TestDelete_Run_ForceDeletesWhenNoNS(t *testing.T) {
 		HostedZone:  rtypes.HostedZone{Id: aws.String("/hostedzone/Z1"), Name: aws.String("example.com.")},
 		RecordsByID: map[string][]rtypes.ResourceRecordSet{"/hostedzone/Z1": {rr, nsRS}},
 	}
-	newRouteManager = func(ctx context.Context, profile string, rmo *dns.RouteManagerOptions) RouteManagerAPI { return fake }
+	newRouteManager = func(ctx context.Context, o dns.AWSOptions, rmo *dns.RouteManagerOptions) (RouteManagerAPI, error) {
+		return fake, nil
+	}
 	getNameserversFor = func(domain string) ([]string, error) { return nil, &dig.NSRecordNotFound{Domain: domain} }
 	promptConfirm = func(label string, isConfirm bool) (string, error) { return "y", nil }
+	stdinIsTerminal = func() bool { return true }
 
 	// Force skip prompt path by setting Force true
 	a := &deleteApp{Profile: "p", Domain: "example.com.", Force: true}
@@ -0,0 +1,252 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	rtypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/pedrokiefer/route53copy/pkg/dns"
+	"github.com/spf13/cobra"
+)
+
+const liveSide = "live"
+
+type diffApp struct {
+	Left  string
+	Right string
+	// Zone names the zone of live sides and of BIND files without an
+	// r53tool header.
+	Zone     string
+	Output   string
+	ExitCode bool
+
+	Filter zoneFilterFlags
+}
+
+// zoneSide is one side of a diff: a hosted zone, an exported file or live
+// DNS.
+type zoneSide struct {
+	Label   string
+	Zone    string
+	Records []rtypes.ResourceRecordSet
+	live    bool
+}
+
+type recordDiffChange string
+
+const (
+	recordAdded   recordDiffChange = "added"
+	recordRemoved recordDiffChange = "removed"
+	recordChanged recordDiffChange = "changed"
+)
+
+// recordDiff is a record set that differs between the two sides. Left and
+// Right hold dns.RecordSetValue of each side, empty when it has no such set.
+type recordDiff struct {
+	Name          string           `json:"name" yaml:"name"`
+	Type          string           `json:"type" yaml:"type"`
+	SetIdentifier string           `json:"set_identifier,omitempty" yaml:"set_identifier,omitempty"`
+	Change        recordDiffChange `json:"change" yaml:"change"`
+	Left          string           `json:"left" yaml:"left"`
+	Right         string           `json:"right" yaml:"right"`
+}
+
+func (recordDiff) columns() []string {
+	return []string{"Name", "Type", "Set", "Change", "Left", "Right"}
+}
+
+func (r recordDiff) row() []string {
+	return []string{r.Name, r.Type, r.SetIdentifier, string(r.Change), r.Left, r.Right}
+}
+
+func init() {
+	rootCmd.AddCommand(newDiffCommand())
+}
+
+func (a *diffApp) Run(ctx context.Context) error {
+	if a.Output == "" {
+		a.Output = outputTable
+	}
+	if err := validateOutputFormat(a.Output); err != nil {
+		return err
+	}
+
+	left, err := a.load(ctx, a.Left)
+	if err != nil {
+		return err
+	}
+	right, err := a.load(ctx, a.Right)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case left.live && right.live:
+		return errors.New("only one side can be live DNS")
+	case left.live:
+		err = a.resolveLive(ctx, left, right)
+	case right.live:
+		err = a.resolveLive(ctx, right, left)
+	case !strings.EqualFold(left.Zone, right.Zone):
+		return fmt.Errorf("%s holds %s but %s holds %s", left.Label, left.Zone, right.Label, right.Zone)
+	}
+	if err != nil {
+		return err
+	}
+
+	d := dns.CompareRecordSets(left.Zone, left.Records, right.Records)
+	results := []recordDiff{}
+	for _, rs := range d.OnlyLeft {
+		results = append(results, newRecordDiff(recordRemoved, rs, dns.RecordSetValue(rs), ""))
+	}
+	for _, rs := range d.OnlyRight {
+		results = append(results, newRecordDiff(recordAdded, rs, "", dns.RecordSetValue(rs)))
+	}
+	for _, c := range d.Changed {
+		results = append(results, newRecordDiff(recordChanged, c.Left, dns.RecordSetValue(c.Left), dns.RecordSetValue(c.Right)))
+	}
+
+	if len(results) == 0 && a.Output == outputTable {
+		fmt.Fprintf(stdout, "%s and %s have the same records\n", left.Label, right.Label)
+	} else if err := writeResults(stdout, a.Output, results); err != nil {
+		return err
+	}
+	if a.ExitCode && len(results) > 0 {
+		return fmt.Errorf("%d record sets differ", len(results))
+	}
+	return nil
+}
+
+func newRecordDiff(change recordDiffChange, rs rtypes.ResourceRecordSet, left, right string) recordDiff {
+	return recordDiff{
+		Name:          aws.ToString(rs.Name),
+		Type:          string(rs.Type),
+		SetIdentifier: aws.ToString(rs.SetIdentifier),
+		Change:        change,
+		Left:          left,
+		Right:         right,
+	}
+}
+
+// load reads one side: "live" or "live:<zone>", an exported zone file, or
+// "<profile>:<zone|zone_id>".
+func (a *diffApp) load(ctx context.Context, arg string) (*zoneSide, error) {
+	if arg == liveSide || strings.HasPrefix(arg, liveSide+":") {
+		zone := strings.TrimPrefix(strings.TrimPrefix(arg, liveSide), ":")
+		if zone == "" {
+			zone = a.Zone
+		}
+		return &zoneSide{Label: arg, Zone: zone, live: true}, nil
+	}
+
+	if fi, err := os.Stat(arg); err == nil && !fi.IsDir() {
+		zone, records, err := dns.ReadZoneFile(arg, a.Zone)
+		if err != nil {
+			return nil, err
+		}
+		return &zoneSide{Label: arg, Zone: zone, Records: records}, nil
+	}
+
+	profile, zoneName, ok := strings.Cut(arg, ":")
+	if !ok || profile == "" || zoneName == "" {
+		return nil, fmt.Errorf("%q is neither a zone file, %q nor <profile>:<zone>", arg, liveSide)
+	}
+	manager, err := newRouteManager(ctx, awsOptions(profile), &dns.RouteManagerOptions{NoWait: noWait})
+	if err != nil {
+		return nil, err
+	}
+	zone, err := manager.FindHostedZone(ctx, zoneName, a.Filter.zoneFilter())
+	if err != nil {
+		return nil, err
+	}
+	records, err := manager.GetResourceRecords(ctx, aws.ToString(zone.Id))
+	if err != nil {
+		return nil, err
+	}
+	return &zoneSide{Label: arg, Zone: aws.ToString(zone.Name), Records: records}, nil
+}
+
+// resolveLive queries live DNS for the record sets of other. Live DNS
+// cannot be listed, so names only other has are not found, and routed sets
+// are left out since the answer depends on who asks. Aliases of other are
+// flattened, and TTLs are taken from other since resolvers count them down.
+func (a *diffApp) resolveLive(ctx context.Context, live, other *zoneSide) error {
+	if live.Zone == "" {
+		live.Zone = other.Zone
+	}
+	if !strings.EqualFold(dns.NormalizeDomain(live.Zone), dns.NormalizeDomain(other.Zone)) {
+		return fmt.Errorf("%s is compared with %s, which holds %s", live.Label, other.Label, other.Zone)
+	}
+
+	records, err := dns.ResolveAliases(ctx, other.Zone, other.Records, dns.AliasFlatten, lookupAlias)
+	if err != nil {
+		return err
+	}
+	other.Records = []rtypes.ResourceRecordSet{}
+	routed := 0
+	for _, rs := range records {
+		if rs.SetIdentifier != nil {
+			routed++
+			continue
+		}
+		other.Records = append(other.Records, rs)
+	}
+	if routed > 0 {
+		log.Printf("Skipping %d routed record sets, live answers depend on the resolver\n", routed)
+	}
+
+	apex := dns.NormalizeDomain(other.Zone)
+	for _, rs := range other.Records {
+		name := strings.ReplaceAll(aws.ToString(rs.Name), `\052`, "*")
+		// Aliases left after flattening have no answer to compare, and
+		// the apex NS and SOA are not compared.
+		if rs.AliasTarget != nil || (strings.EqualFold(name, apex) && (rs.Type == rtypes.RRTypeNs || rs.Type == rtypes.RRTypeSoa)) {
+			continue
+		}
+		values, _, err := lookupLive(ctx, name, string(rs.Type))
+		if err != nil {
+			return fmt.Errorf("%s %s: %w", name, rs.Type, err)
+		}
+		if len(values) == 0 {
+			continue
+		}
+		found := rtypes.ResourceRecordSet{Name: rs.Name, Type: rs.Type, TTL: rs.TTL}
+		for _, v := range values {
+			found.ResourceRecords = append(found.ResourceRecords, rtypes.ResourceRecord{Value: aws.String(v)})
+		}
+		live.Records = append(live.Records, found)
+	}
+	return nil
+}
+
+func newDiffCommand() *cobra.Command {
+	a := &diffApp{}
+	c := &cobra.Command{
+		Use:   "diff <left> <right>",
+		Short: "Compare the records of a hosted zone, an exported zone file or live DNS",
+		Long: `Compare two versions of a zone record set by record set. Each side is one of:
+
+  <profile>:<zone|zone_id>  the hosted zone in the profile's account
+  <file>                    a zone exported as bind, json or yaml
+  live[:<zone>]             live DNS, queried for the names the other side holds`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			a.Left = args[0]
+			a.Right = args[1]
+			return a.Run(cmd.Context())
+		},
+		SilenceErrors: true,
+		SilenceUsage:  true,
+	}
+	f := c.Flags()
+	f.StringVar(&a.Zone, "zone", "", "Zone of BIND files without an r53tool header and of live sides (default: the other side's zone)")
+	f.StringVarP(&a.Output, "output", "o", outputTable, "Output format: table, json, yaml or csv")
+	f.BoolVar(&a.ExitCode, "exit-code", false, "Fail when the sides differ")
+	a.Filter.addFlags(c)
+	return c
+}
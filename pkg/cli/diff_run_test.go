@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	rtypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/stretchr/testify/require"
+)
+
+func diffZone(t *testing.T, extra ...rtypes.ResourceRecordSet) string {
+	t.Helper()
+	srv := fakeAccounts(t, "p")["p"]
+	zoneID := srv.AddZone("example.com")
+	require.NoError(t, srv.AddRecordSets(zoneID, append([]rtypes.ResourceRecordSet{
+		{Name: aws.String("www.example.com."), Type: rtypes.RRTypeA, TTL: aws.Int64(300),
+			ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String("192.0.2.1")}}},
+		{Name: aws.String("example.com."), Type: rtypes.RRTypeTxt, TTL: aws.Int64(300),
+			ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String(`"v=spf1 -all"`)}}},
+		weightedSet("api.example.com.", "blue", 10, "192.0.2.2"),
+	}, extra...)...))
+
+	file := filepath.Join(t.TempDir(), "example.com.json")
+	require.NoError(t, (&exportApp{Profile: "p", Zone: "example.com", Output: file, Format: "json"}).Run(context.Background()))
+	return file
+}
+
+func weightedSet(name, id string, weight int64, value string) rtypes.ResourceRecordSet {
+	return rtypes.ResourceRecordSet{Name: aws.String(name), Type: rtypes.RRTypeA, TTL: aws.Int64(60),
+		SetIdentifier: aws.String(id), Weight: aws.Int64(weight),
+		ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String(value)}}}
+}
+
+func runDiff(t *testing.T, a *diffApp) ([]recordDiff, error) {
+	t.Helper()
+	oldOut := stdout
+	t.Cleanup(func() { stdout = oldOut })
+	var buf bytes.Buffer
+	stdout = &buf
+
+	a.Output = outputJSON
+	err := a.Run(context.Background())
+	var results []recordDiff
+	if buf.Len() > 0 {
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &results))
+	}
+	return results, err
+}
+
+func TestDiff_Run_FileAgainstAccount(t *testing.T) {
+	file := diffZone(t)
+	results, err := runDiff(t, &diffApp{Left: file, Right: "p:example.com", ExitCode: true})
+	require.NoError(t, err)
+	require.Empty(t, results)
+
+	// The account as it looks after out-of-band changes.
+	srv := fakeAccounts(t, "p")["p"]
+	zoneID := srv.AddZone("example.com")
+	require.NoError(t, srv.AddRecordSets(zoneID,
+		rtypes.ResourceRecordSet{Name: aws.String("www.example.com."), Type: rtypes.RRTypeA, TTL: aws.Int64(60),
+			ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String("192.0.2.1")}}},
+		rtypes.ResourceRecordSet{Name: aws.String("new.example.com."), Type: rtypes.RRTypeCname, TTL: aws.Int64(300),
+			ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String("www.example.com.")}}},
+		weightedSet("api.example.com.", "blue", 20, "192.0.2.2"),
+	))
+
+	results, err = runDiff(t, &diffApp{Left: file, Right: "p:example.com", ExitCode: true})
+	require.EqualError(t, err, "4 record sets differ")
+	require.Equal(t, []recordDiff{
+		{Name: "example.com.", Type: "TXT", Change: recordRemoved, Left: `ttl=300 "v=spf1 -all"`},
+		{Name: "new.example.com.", Type: "CNAME", Change: recordAdded, Right: "ttl=300 www.example.com."},
+		{Name: "api.example.com.", Type: "A", SetIdentifier: "blue", Change: recordChanged,
+			Left: "ttl=60 192.0.2.2 weight=10", Right: "ttl=60 192.0.2.2 weight=20"},
+		{Name: "www.example.com.", Type: "A", Change: recordChanged, Left: "ttl=300 192.0.2.1", Right: "ttl=60 192.0.2.1"},
+	}, results)
+}
+
+func TestDiff_Run_Live(t *testing.T) {
+	// Written the way Route 53 keeps what it is given: no trailing dots, in
+	// mixed case and with a long IPv6 form. Live DNS answers them canonically.
+	diffZone(t,
+		rtypes.ResourceRecordSet{Name: aws.String("docs.example.com."), Type: rtypes.RRTypeCname, TTL: aws.Int64(300),
+			ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String("Pages.Example.NET")}}},
+		rtypes.ResourceRecordSet{Name: aws.String("example.com."), Type: rtypes.RRTypeMx, TTL: aws.Int64(300),
+			ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String("20 MX2.example.com")}, {Value: aws.String("10 mx1.example.com.")}}},
+		rtypes.ResourceRecordSet{Name: aws.String("v6.example.com."), Type: rtypes.RRTypeAaaa, TTL: aws.Int64(300),
+			ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String("2001:DB8:0:0:0:0:0:1")}}},
+	)
+	oldLookup := lookupLive
+	t.Cleanup(func() { lookupLive = oldLookup })
+	queries := []string{}
+	lookupLive = func(ctx context.Context, name, rtype string) ([]string, uint32, error) {
+		queries = append(queries, name+" "+rtype)
+		switch name + " " + rtype {
+		case "www.example.com. A":
+			return []string{"192.0.2.9"}, 12, nil
+		case "docs.example.com. CNAME":
+			return []string{"pages.example.net."}, 300, nil
+		case "example.com. MX":
+			return []string{"10 mx1.example.com.", "20 mx2.example.com."}, 300, nil
+		case "v6.example.com. AAAA":
+			return []string{"2001:db8::1"}, 300, nil
+		}
+		return []string{`"v=spf1 -all"`}, 299, nil
+	}
+
+	results, err := runDiff(t, &diffApp{Left: "p:example.com", Right: "live"})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"www.example.com. A", "example.com. TXT", "docs.example.com. CNAME",
+		"example.com. MX", "v6.example.com. AAAA"}, queries,
+		"routed sets and the apex NS and SOA are not queried")
+	// TTLs counted down by resolvers are not reported.
+	require.Equal(t, []recordDiff{
+		{Name: "www.example.com.", Type: "A", Change: recordChanged, Left: "ttl=300 192.0.2.1", Right: "ttl=300 192.0.2.9"},
+	}, results)
+}
+
+func TestDiff_Run_InvalidSides(t *testing.T) {
+	_, err := runDiff(t, &diffApp{Left: "live", Right: "live:example.com"})
+	require.EqualError(t, err, "only one side can be live DNS")
+
+	_, err = runDiff(t, &diffApp{Left: "no-such-file.zone", Right: "live"})
+	require.ErrorContains(t, err, `"no-such-file.zone" is neither a zone file`)
+
+	file := diffZone(t)
+	_, err = runDiff(t, &diffApp{Left: file, Right: "live:example.org"})
+	require.ErrorContains(t, err, "which holds example.com.")
+}
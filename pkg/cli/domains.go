@@ -21,12 +21,12 @@ func init() {
 }
 
 func (a *domainsApp) Run(ctx context.Context) error {
-	srcManager, err := dns.NewDomainManager(ctx, a.SourceProfile)
+	srcManager, err := dns.NewDomainManager(ctx, awsOptions(a.SourceProfile))
 	if err != nil {
 		return err
 	}
 
-	dstManager, err := dns.NewDomainManager(ctx, a.DestinationProfile)
+	dstManager, err := dns.NewDomainManager(ctx, awsOptions(a.DestinationProfile))
 	if err != nil {
 		return err
 	}
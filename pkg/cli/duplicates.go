@@ -0,0 +1,186 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	rtypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/olekukonko/tablewriter"
+	"github.com/pedrokiefer/route53copy/pkg/dns"
+	"github.com/spf13/cobra"
+)
+
+type duplicatesApp struct {
+	Profile string
+
+	routeManager RouteManagerAPI
+}
+
+// duplicateZone is one of several hosted zones sharing a name.
+type duplicateZone struct {
+	Zone      rtypes.HostedZone
+	Records   []rtypes.ResourceRecordSet
+	Delegated bool
+}
+
+func init() {
+	rootCmd.AddCommand(newDuplicatesCommand())
+}
+
+func (a *duplicatesApp) Run(ctx context.Context) error {
+	var err error
+	a.routeManager, err = newRouteManager(ctx, awsOptions(a.Profile), &dns.RouteManagerOptions{
+		NoWait: noWait,
+	})
+	if err != nil {
+		return err
+	}
+
+	zones, err := a.routeManager.ListHostedZones(ctx)
+	if err != nil {
+		return err
+	}
+
+	groups := groupDuplicateZones(zones)
+	if len(groups) == 0 {
+		log.Printf("No duplicate zones in %s\n", a.Profile)
+		return nil
+	}
+	log.Printf("Found %d zone names with more than one hosted zone\n", len(groups))
+
+	for _, group := range groups {
+		dups, err := a.loadGroup(ctx, group)
+		if err != nil {
+			return err
+		}
+		printDuplicates(stdout, dups)
+	}
+	return nil
+}
+
+// groupDuplicateZones returns the groups of zones sharing a name and
+// privacy, ordered by name. A public zone and its private counterpart are
+// not duplicates.
+func groupDuplicateZones(zones []rtypes.HostedZone) [][]rtypes.HostedZone {
+	byName := map[string][]rtypes.HostedZone{}
+	for _, z := range zones {
+		key := aws.ToString(z.Name)
+		if dns.IsPrivateZone(z) {
+			key += " private"
+		}
+		byName[key] = append(byName[key], z)
+	}
+
+	keys := []string{}
+	for k, zs := range byName {
+		if len(zs) > 1 {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	groups := [][]rtypes.HostedZone{}
+	for _, k := range keys {
+		groups = append(groups, byName[k])
+	}
+	return groups
+}
+
+// loadGroup fetches the records of each zone and works out which one live
+// DNS delegates to. That zone, or the first one, comes first and is the
+// reference the others are compared with.
+func (a *duplicatesApp) loadGroup(ctx context.Context, zones []rtypes.HostedZone) ([]duplicateZone, error) {
+	name := aws.ToString(zones[0].Name)
+	live := []string{}
+	if !dns.IsPrivateZone(zones[0]) {
+		ns, err := getNameserversFor(name)
+		if err != nil {
+			log.Printf("Could not resolve NS for %s: %s\n", name, err)
+		}
+		live = ns
+	}
+
+	dups := []duplicateZone{}
+	for _, z := range zones {
+		records, err := a.routeManager.GetResourceRecords(ctx, aws.ToString(z.Id))
+		if err != nil {
+			return nil, err
+		}
+		d := duplicateZone{Zone: z, Records: records}
+		if nsRecords, err := dns.FindNSRecord(records); err == nil && len(live) > 0 {
+			d.Delegated = dns.MatchNSRecords(live, nsRecords)
+		}
+		dups = append(dups, d)
+	}
+
+	sort.SliceStable(dups, func(i, j int) bool { return dups[i].Delegated && !dups[j].Delegated })
+	return dups, nil
+}
+
+func printDuplicates(w io.Writer, dups []duplicateZone) {
+	ref := dups[0]
+	name := aws.ToString(ref.Zone.Name)
+	fmt.Fprintf(w, "%s has %d hosted zones:\n", name, len(dups))
+
+	table := tablewriter.NewWriter(w)
+	table.Header([]string{"Zone ID", "Records", "Delegated", "Comment"})
+	for _, d := range dups {
+		comment := ""
+		if d.Zone.Config != nil {
+			comment = aws.ToString(d.Zone.Config.Comment)
+		}
+		_ = table.Append([]string{
+			aws.ToString(d.Zone.Id),
+			strconv.Itoa(len(d.Records)),
+			strconv.FormatBool(d.Delegated),
+			comment,
+		})
+	}
+	_ = table.Render()
+
+	refID := aws.ToString(ref.Zone.Id)
+	for _, d := range dups[1:] {
+		id := aws.ToString(d.Zone.Id)
+		diff := dns.CompareRecordSets(name, ref.Records, d.Records)
+		if diff.Empty() {
+			fmt.Fprintf(w, "%s has the same records as %s\n", id, refID)
+			continue
+		}
+
+		fmt.Fprintf(w, "Differences between %s and %s:\n", refID, id)
+		dt := tablewriter.NewWriter(w)
+		dt.Header([]string{"Name", "Type", refID, id})
+		for _, rs := range diff.OnlyLeft {
+			_ = dt.Append([]string{aws.ToString(rs.Name), string(rs.Type), dns.RecordSetValue(rs), ""})
+		}
+		for _, rs := range diff.OnlyRight {
+			_ = dt.Append([]string{aws.ToString(rs.Name), string(rs.Type), "", dns.RecordSetValue(rs)})
+		}
+		for _, c := range diff.Changed {
+			_ = dt.Append([]string{aws.ToString(c.Left.Name), string(c.Left.Type), dns.RecordSetValue(c.Left), dns.RecordSetValue(c.Right)})
+		}
+		_ = dt.Render()
+	}
+}
+
+func newDuplicatesCommand() *cobra.Command {
+	a := duplicatesApp{}
+
+	c := &cobra.Command{
+		Use:   "duplicates <profile>",
+		Short: "Find hosted zones sharing a name and compare their records",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			a.Profile = args[0]
+			return a.Run(cmd.Context())
+		},
+		SilenceErrors: true,
+		SilenceUsage:  true,
+	}
+	return c
+}
@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	rtypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/pedrokiefer/route53copy/pkg/dns"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupDuplicateZones(t *testing.T) {
+	private := &rtypes.HostedZoneConfig{PrivateZone: true}
+	groups := groupDuplicateZones([]rtypes.HostedZone{
+		{Id: aws.String("Z1"), Name: aws.String("b.com.")},
+		{Id: aws.String("Z2"), Name: aws.String("a.com.")},
+		{Id: aws.String("Z3"), Name: aws.String("b.com.")},
+		{Id: aws.String("Z4"), Name: aws.String("a.com."), Config: private},
+		{Id: aws.String("Z5"), Name: aws.String("c.com.")},
+	})
+	require.Len(t, groups, 1)
+	require.Equal(t, "Z1", aws.ToString(groups[0][0].Id))
+	require.Equal(t, "Z3", aws.ToString(groups[0][1].Id))
+}
+
+func TestDuplicates_Run_ComparesAgainstDelegatedZone(t *testing.T) {
+	oldNewRM, oldDig, oldOut := newRouteManager, getNameserversFor, stdout
+	t.Cleanup(func() { newRouteManager = oldNewRM; getNameserversFor = oldDig; stdout = oldOut })
+
+	ns := func(v string) rtypes.ResourceRecordSet {
+		return rtypes.ResourceRecordSet{Name: aws.String("example.com."), Type: rtypes.RRTypeNs,
+			ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String(v)}}}
+	}
+	a := func(name, ip string) rtypes.ResourceRecordSet {
+		return rtypes.ResourceRecordSet{Name: aws.String(name), Type: rtypes.RRTypeA, TTL: aws.Int64(300),
+			ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String(ip)}}}
+	}
+	fake := &fakeRouteManager{
+		Zones: []rtypes.HostedZone{
+			{Id: aws.String("/hostedzone/ZOLD"), Name: aws.String("example.com.")},
+			{Id: aws.String("/hostedzone/ZLIVE"), Name: aws.String("example.com.")},
+		},
+		RecordsByID: map[string][]rtypes.ResourceRecordSet{
+			"/hostedzone/ZOLD":  {ns("ns-1.awsdns-01.com."), a("www.example.com.", "192.0.2.1")},
+			"/hostedzone/ZLIVE": {ns("ns-2.awsdns-02.com."), a("www.example.com.", "192.0.2.2"), a("api.example.com.", "192.0.2.3")},
+		},
+	}
+	newRouteManager = func(ctx context.Context, o dns.AWSOptions, rmo *dns.RouteManagerOptions) (RouteManagerAPI, error) {
+		return fake, nil
+	}
+	getNameserversFor = func(domain string) ([]string, error) { return []string{"ns-2.awsdns-02.com"}, nil }
+	var buf bytes.Buffer
+	stdout = &buf
+
+	require.NoError(t, (&duplicatesApp{Profile: "p"}).Run(context.Background()))
+
+	out := buf.String()
+	require.Contains(t, out, "example.com. has 2 hosted zones:")
+	require.Contains(t, out, "Differences between /hostedzone/ZLIVE and /hostedzone/ZOLD:")
+	require.Contains(t, out, "api.example.com.")
+	require.Contains(t, out, "ttl=300 192.0.2.2")
+}
@@ -0,0 +1,315 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	rtypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/pedrokiefer/route53copy/pkg/dns"
+	"github.com/pedrokiefer/route53copy/pkg/dns/route53test"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAccounts starts one in-memory Route53 per profile and points the real
+// dns.RouteManager at them, so commands run through the SDK end to end.
+func fakeAccounts(t *testing.T, profiles ...string) map[string]*route53test.Server {
+	t.Helper()
+	route53test.SetEnv(t)
+	servers := map[string]*route53test.Server{}
+	for _, p := range profiles {
+		srv := route53test.NewServer(route53test.WithPageSize(2))
+		t.Cleanup(srv.Close)
+		servers[p] = srv
+	}
+
+	oldNewRM := newRouteManager
+	t.Cleanup(func() { newRouteManager = oldNewRM })
+	newRouteManager = func(ctx context.Context, o dns.AWSOptions, rmo *dns.RouteManagerOptions) (RouteManagerAPI, error) {
+		srv, ok := servers[o.Profile]
+		require.True(t, ok, "unexpected profile %q", o.Profile)
+		o.Profile = ""
+		o.EndpointURL = srv.URL
+		return dns.NewRouteManager(ctx, o, rmo)
+	}
+	return servers
+}
+
+func userRecordSets(rrs []rtypes.ResourceRecordSet) []rtypes.ResourceRecordSet {
+	out := []rtypes.ResourceRecordSet{}
+	for _, rs := range rrs {
+		if rs.Type != rtypes.RRTypeNs && rs.Type != rtypes.RRTypeSoa {
+			out = append(out, rs)
+		}
+	}
+	return out
+}
+
+func TestCopy_EndToEnd(t *testing.T) {
+	servers := fakeAccounts(t, "src", "dst")
+	src := servers["src"]
+	zoneID := src.AddZone("example.com")
+	require.NoError(t, src.AddRecordSets(zoneID,
+		rtypes.ResourceRecordSet{Name: aws.String("www.example.com."), Type: rtypes.RRTypeA, TTL: aws.Int64(300),
+			ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String("192.0.2.1")}}},
+		rtypes.ResourceRecordSet{Name: aws.String("api.example.com."), Type: rtypes.RRTypeA, TTL: aws.Int64(60),
+			SetIdentifier: aws.String("blue"), Weight: aws.Int64(10),
+			ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String("192.0.2.2")}}},
+		rtypes.ResourceRecordSet{Name: aws.String("api.example.com."), Type: rtypes.RRTypeA, TTL: aws.Int64(60),
+			SetIdentifier: aws.String("green"), Weight: aws.Int64(90),
+			ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String("192.0.2.3")}}},
+		rtypes.ResourceRecordSet{Name: aws.String("\\052.example.com."), Type: rtypes.RRTypeTxt, TTL: aws.Int64(300),
+			ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String(`"wildcard"`)}}},
+	))
+
+	a := &copyApp{SourceProfile: "src", DestinationProfile: "dst", Domain: "example.com"}
+	require.NoError(t, a.Run(context.Background()))
+
+	dstZones := servers["dst"].Zones()
+	require.Len(t, dstZones, 1)
+	require.Equal(t, "example.com.", aws.ToString(dstZones[0].Name))
+	require.Equal(t,
+		userRecordSets(src.RecordSets(zoneID)),
+		userRecordSets(servers["dst"].RecordSets(aws.ToString(dstZones[0].Id))))
+
+	// Copying again upserts the same records into the existing zone.
+	require.NoError(t, a.Run(context.Background()))
+	require.Len(t, servers["dst"].Zones(), 1)
+}
+
+func TestCopy_EndToEnd_PrivateZone(t *testing.T) {
+	servers := fakeAccounts(t, "src", "dst")
+	src := servers["src"]
+	zoneID := src.AddPrivateZone("corp.internal", "vpc-src1", "us-east-1")
+	require.NoError(t, src.AddRecordSets(zoneID,
+		rtypes.ResourceRecordSet{Name: aws.String("db.corp.internal."), Type: rtypes.RRTypeCname, TTL: aws.Int64(60),
+			ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String("db-1.corp.internal")}}},
+	))
+
+	a := &copyApp{
+		SourceProfile:      "src",
+		DestinationProfile: "dst",
+		Domain:             "corp.internal",
+		UpdateNS:           true,
+		VPCMap:             map[string]string{"vpc-src1": "vpc-dst1"},
+	}
+	require.NoError(t, a.Run(context.Background()))
+
+	dst := servers["dst"]
+	zones := dst.Zones()
+	require.Len(t, zones, 1)
+	require.True(t, dns.IsPrivateZone(zones[0]))
+	require.Len(t, userRecordSets(dst.RecordSets(aws.ToString(zones[0].Id))), 1)
+	require.Zero(t, dst.Calls("AssociateVPCWithHostedZone"), "the VPC was given at creation")
+}
+
+func TestExportImport_EndToEnd_KeepsRouting(t *testing.T) {
+	for _, format := range []string{"bind", "json", "yaml"} {
+		t.Run(format, func(t *testing.T) {
+			servers := fakeAccounts(t, "src", "dst")
+			src := servers["src"]
+			zoneID := src.AddZone("example.com")
+			require.NoError(t, src.AddRecordSets(zoneID,
+				rtypes.ResourceRecordSet{Name: aws.String("api.example.com."), Type: rtypes.RRTypeA, TTL: aws.Int64(60),
+					SetIdentifier: aws.String("blue"), Weight: aws.Int64(10), HealthCheckId: aws.String("hc-1"),
+					ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String("192.0.2.2")}}},
+				rtypes.ResourceRecordSet{Name: aws.String("api.example.com."), Type: rtypes.RRTypeA, TTL: aws.Int64(60),
+					SetIdentifier: aws.String("green"), Weight: aws.Int64(90),
+					ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String("192.0.2.3")}}},
+				rtypes.ResourceRecordSet{Name: aws.String("eu.example.com."), Type: rtypes.RRTypeCname, TTL: aws.Int64(300),
+					SetIdentifier: aws.String("eu"), GeoLocation: &rtypes.GeoLocation{ContinentCode: aws.String("EU")},
+					ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String("eu.example.net.")}}},
+				rtypes.ResourceRecordSet{Name: aws.String("www.example.com."), Type: rtypes.RRTypeA, AliasTarget: &rtypes.AliasTarget{
+					DNSName: aws.String("api.example.com."), HostedZoneId: aws.String(strings.TrimPrefix(zoneID, "/hostedzone/")),
+				}},
+			))
+			dstID := servers["dst"].AddZone("example.com")
+
+			out := filepath.Join(t.TempDir(), "example.com."+format)
+			require.NoError(t, (&exportApp{Profile: "src", Zone: "example.com", Output: out, Format: format}).Run(context.Background()))
+			require.NoError(t, (&importApp{Profile: "dst", File: out}).Run(context.Background()))
+
+			d := dns.CompareRecordSets("example.com", src.RecordSets(zoneID), servers["dst"].RecordSets(dstID))
+			require.True(t, d.Empty(), "%+v", d)
+		})
+	}
+}
+
+func TestImport_RejectsOtherZone(t *testing.T) {
+	servers := fakeAccounts(t, "dst")
+	servers["dst"].AddZone("example.org")
+	out := filepath.Join(t.TempDir(), "example.com.json")
+	require.NoError(t, os.WriteFile(out, []byte(`{"zone": "example.com.", "record_sets": []}`), 0o644))
+
+	err := (&importApp{Profile: "dst", File: out, Zone: "example.org"}).Run(context.Background())
+	require.ErrorContains(t, err, "holds records of example.com., not example.org.")
+}
+
+func TestPark_EndToEnd_Template(t *testing.T) {
+	srv := fakeAccounts(t, "p")["p"]
+	zoneID := srv.AddZone("example.com")
+
+	a := &parkApp{
+		Profile: "p", Alias: true, DualStack: true, Template: dns.DefaultParkingTemplate,
+		Hostname: "d111.cloudfront.net.", ZoneID: "Z2FDTNDATAQYW2",
+		Zones: zoneSelector{Names: []string{"example.com"}}, StateDir: t.TempDir(),
+	}
+	require.NoError(t, a.Run(context.Background()))
+
+	types := []string{}
+	for _, rs := range userRecordSets(srv.RecordSets(zoneID)) {
+		types = append(types, aws.ToString(rs.Name)+" "+string(rs.Type))
+	}
+	require.ElementsMatch(t, []string{
+		"example.com. A", "example.com. AAAA", "example.com. MX", "example.com. TXT", "example.com. CAA",
+		"www.example.com. CNAME", "_dmarc.example.com. TXT", `\052.example.com. CNAME`,
+	}, types)
+	require.Equal(t, map[string]string{"parked": "true"}, srv.Tags(zoneID))
+}
+
+func parkedZone(t *testing.T) (*route53test.Server, string, []rtypes.ResourceRecordSet, string) {
+	t.Helper()
+	srv := fakeAccounts(t, "p")["p"]
+	zoneID := srv.AddZone("example.com")
+	require.NoError(t, srv.AddRecordSets(zoneID,
+		rtypes.ResourceRecordSet{Name: aws.String("example.com."), Type: rtypes.RRTypeA, TTL: aws.Int64(300),
+			ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String("192.0.2.1")}}},
+		rtypes.ResourceRecordSet{Name: aws.String("example.com."), Type: rtypes.RRTypeMx, TTL: aws.Int64(300),
+			ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String("10 mail.example.com.")}}},
+		rtypes.ResourceRecordSet{Name: aws.String("mail.example.com."), Type: rtypes.RRTypeA, TTL: aws.Int64(300),
+			ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String("192.0.2.2")}}},
+	))
+	before := userRecordSets(srv.RecordSets(zoneID))
+
+	stateDir := t.TempDir()
+	park := &parkApp{
+		Profile: "p", Alias: true, DualStack: true, Force: true, Template: dns.DefaultParkingTemplate,
+		Hostname: "d111.cloudfront.net.", ZoneID: "Z2FDTNDATAQYW2",
+		Zones: zoneSelector{Names: []string{"example.com"}}, StateDir: stateDir,
+	}
+	// The template overwrites the apex A and MX, so park asks first.
+	prompts := stubPrompt(t, true, "y", nil)
+	require.NoError(t, park.Run(context.Background()))
+	require.Equal(t, 1, *prompts)
+	return srv, zoneID, before, stateDir
+}
+
+func TestPark_EndToEnd_AsksBeforeOverwritingTemplateSets(t *testing.T) {
+	srv := fakeAccounts(t, "p")["p"]
+	zoneID := srv.AddZone("example.com")
+	// No apex address and no www CNAME, but the template writes the MX.
+	require.NoError(t, srv.AddRecordSets(zoneID,
+		rtypes.ResourceRecordSet{Name: aws.String("example.com."), Type: rtypes.RRTypeMx, TTL: aws.Int64(300),
+			ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String("10 mail.example.com.")}}},
+	))
+	before := srv.RecordSets(zoneID)
+	stubPrompt(t, false, "y", nil)
+
+	a := &parkApp{
+		Profile: "p", Alias: true, Force: true, Template: dns.DefaultParkingTemplate,
+		Hostname: "d111.cloudfront.net.", ZoneID: "Z2FDTNDATAQYW2",
+		Zones: zoneSelector{Names: []string{"example.com"}}, StateDir: t.TempDir(),
+	}
+	err := a.Run(context.Background())
+	require.ErrorIs(t, err, errConfirmationNeeded)
+	require.ErrorContains(t, err, "example.com. MX")
+	require.Equal(t, before, srv.RecordSets(zoneID))
+	require.Empty(t, srv.Tags(zoneID))
+}
+
+func TestPark_EndToEnd_KeepsWildcardOfOtherType(t *testing.T) {
+	srv := fakeAccounts(t, "p")["p"]
+	zoneID := srv.AddZone("example.com")
+	require.NoError(t, srv.AddRecordSets(zoneID,
+		rtypes.ResourceRecordSet{Name: aws.String("*.example.com."), Type: rtypes.RRTypeA, TTL: aws.Int64(300),
+			ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String("192.0.2.1")}}},
+	))
+
+	a := &parkApp{
+		Profile: "p", Alias: true, Force: true, Template: dns.DefaultParkingTemplate,
+		Hostname: "d111.cloudfront.net.", ZoneID: "Z2FDTNDATAQYW2",
+		Zones: zoneSelector{Names: []string{"example.com"}}, StateDir: t.TempDir(),
+	}
+	require.NoError(t, a.Run(context.Background()))
+
+	types := []string{}
+	for _, rs := range userRecordSets(srv.RecordSets(zoneID)) {
+		types = append(types, aws.ToString(rs.Name)+" "+string(rs.Type))
+	}
+	require.ElementsMatch(t, []string{
+		"example.com. A", "example.com. MX", "example.com. TXT", "example.com. CAA",
+		"www.example.com. CNAME", "_dmarc.example.com. TXT", `\052.example.com. A`,
+	}, types, "the * CNAME is left out rather than failing the batch")
+	require.Equal(t, map[string]string{"parked": "true"}, srv.Tags(zoneID))
+}
+
+func TestPark_EndToEnd_KeepsExistingCNAME(t *testing.T) {
+	srv := fakeAccounts(t, "p")["p"]
+	zoneID := srv.AddZone("example.com")
+	dmarc := rtypes.ResourceRecordSet{Name: aws.String("_dmarc.example.com."), Type: rtypes.RRTypeCname, TTL: aws.Int64(300),
+		ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String("example.com.dmarc.example.net.")}}}
+	require.NoError(t, srv.AddRecordSets(zoneID, dmarc))
+
+	a := &parkApp{
+		Profile: "p", Alias: true, Force: true, Template: dns.DefaultParkingTemplate,
+		Hostname: "d111.cloudfront.net.", ZoneID: "Z2FDTNDATAQYW2",
+		Zones: zoneSelector{Names: []string{"example.com"}}, StateDir: t.TempDir(),
+	}
+	require.NoError(t, a.Run(context.Background()))
+
+	types := []string{}
+	for _, rs := range userRecordSets(srv.RecordSets(zoneID)) {
+		types = append(types, aws.ToString(rs.Name)+" "+string(rs.Type))
+	}
+	require.ElementsMatch(t, []string{
+		"example.com. A", "example.com. MX", "example.com. TXT", "example.com. CAA",
+		"www.example.com. CNAME", "_dmarc.example.com. CNAME", `\052.example.com. CNAME`,
+	}, types, "the _dmarc TXT is left out rather than failing the batch")
+	require.Equal(t, map[string]string{"parked": "true"}, srv.Tags(zoneID))
+}
+
+func TestUnpark_EndToEnd_RestoresRecords(t *testing.T) {
+	srv, zoneID, before, stateDir := parkedZone(t)
+	require.Len(t, userRecordSets(srv.RecordSets(zoneID)), 9)
+
+	a := &unparkApp{Profile: "p", Zones: zoneSelector{Names: []string{"example.com"}}, StateDir: stateDir}
+	require.NoError(t, a.Run(context.Background()))
+
+	require.ElementsMatch(t, before, userRecordSets(srv.RecordSets(zoneID)))
+	require.Empty(t, srv.Tags(zoneID))
+	files, err := os.ReadDir(stateDir)
+	require.NoError(t, err)
+	require.Empty(t, files)
+
+	err = a.Run(context.Background())
+	require.ErrorContains(t, err, "no park snapshot for example.com.")
+}
+
+func TestUnpark_EndToEnd_LeavesChangedRecords(t *testing.T) {
+	srv, zoneID, _, stateDir := parkedZone(t)
+	www := rtypes.ResourceRecordSet{Name: aws.String("www.example.com."), Type: rtypes.RRTypeCname, TTL: aws.Int64(60),
+		ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String("shop.example.net.")}}}
+	require.NoError(t, srv.AddRecordSets(zoneID, www))
+
+	a := &unparkApp{Profile: "p", Zones: zoneSelector{Names: []string{"example.com"}}, StateDir: stateDir}
+	err := a.Run(context.Background())
+	require.EqualError(t, err, "left www.example.com. CNAME alone, changed since example.com. was parked")
+
+	names := []string{}
+	for _, rs := range userRecordSets(srv.RecordSets(zoneID)) {
+		names = append(names, aws.ToString(rs.Name)+" "+string(rs.Type))
+	}
+	require.ElementsMatch(t, []string{
+		"example.com. A", "example.com. MX", "mail.example.com. A", "www.example.com. CNAME",
+	}, names)
+	require.Equal(t, map[string]string{"parked": "true"}, srv.Tags(zoneID), "the tag stays until unpark succeeds")
+
+	// Once the changed set is dealt with, unpark finishes the job.
+	require.NoError(t, srv.AddRecordSets(zoneID, rtypes.ResourceRecordSet{Name: aws.String("www.example.com."), Type: rtypes.RRTypeCname,
+		TTL: aws.Int64(3600), ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String("example.com.")}}}))
+	require.NoError(t, a.Run(context.Background()))
+	require.Empty(t, srv.Tags(zoneID))
+}
@@ -2,8 +2,10 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"os"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -15,6 +17,19 @@ type exportApp struct {
 	Profile string
 	Zone    string
 	Output  string
+	// Format is the dns.ExportFormat written.
+	Format string
+	// Aliases is the dns.AliasMode used for alias records.
+	Aliases string
+	// All exports every hosted zone into a directory or tar.gz, with a
+	// manifest.json per account.
+	All bool
+
+	Filter   zoneFilterFlags
+	Accounts accountSelector
+
+	format    dns.ExportFormat
+	aliasMode dns.AliasMode
 }
 
 func init() {
@@ -22,52 +37,164 @@ func init() {
 }
 
 func (a *exportApp) Run(ctx context.Context) error {
-	manager := newRouteManager(ctx, a.Profile, &dns.RouteManagerOptions{NoWait: noWait})
+	a.format = dns.FormatBIND
+	if a.Format != "" {
+		format, err := dns.ParseExportFormat(a.Format)
+		if err != nil {
+			return err
+		}
+		a.format = format
+	}
+	if a.Aliases != "" {
+		mode, err := dns.ParseAliasMode(a.Aliases)
+		if err != nil {
+			return err
+		}
+		a.aliasMode = mode
+	}
+	if a.All && a.Zone != "" {
+		return errors.New("--all exports every zone; drop the zone argument")
+	}
+	if !a.All && a.Zone == "" {
+		return errors.New("a zone is required unless --all is set")
+	}
+	if a.Output != "" && a.Accounts.multiple() && !a.All {
+		return errors.New("--output cannot be used with more than one account; files are named per account")
+	}
 
-	zone, err := manager.GetHostedZone(ctx, a.Zone)
+	accounts, err := a.Accounts.accounts(ctx, a.Profile)
 	if err != nil {
 		return err
 	}
+	if a.All {
+		return a.exportAll(ctx, accounts)
+	}
+
+	for _, acct := range accounts {
+		if err := a.exportFrom(ctx, acct); err != nil {
+			if !a.Accounts.multiple() {
+				return err
+			}
+			log.Printf("Skipping account %s: %s\n", accountLabel(a.Profile, acct), err)
+		}
+	}
+	return nil
+}
+
+func (a *exportApp) exportFrom(ctx context.Context, acct dns.Account) error {
+	manager, err := routeManagerFor(ctx, a.Profile, acct)
+	if err != nil {
+		return err
+	}
+
+	zone, err := manager.FindHostedZone(ctx, a.Zone, a.Filter.zoneFilter())
+	if err != nil {
+		return err
+	}
+	if err := reportVPCs(ctx, manager, zone); err != nil {
+		return err
+	}
 
 	records, err := manager.GetResourceRecords(ctx, aws.ToString(zone.Id))
 	if err != nil {
 		return err
 	}
 
-	if a.Output == "" {
+	output := a.Output
+	if output == "" {
 		name := dns.DenormalizeDomain(aws.ToString(zone.Name))
-		a.Output = fmt.Sprintf("%s-%s.zone", name, time.Now().Format("20060102-150405"))
+		if acct.ID != "" {
+			name = acct.ID + "-" + name
+		}
+		output = fmt.Sprintf("%s-%s.%s", name, time.Now().Format("20060102-150405"), a.format.Extension())
 	}
 
-	log.Printf("Exporting zone %s to %s (records: %d)\n", aws.ToString(zone.Name), a.Output, len(records))
+	log.Printf("Exporting zone %s to %s as %s (records: %d)\n", aws.ToString(zone.Name), output, a.format, len(records))
 
 	if dryRun {
 		log.Printf("--dry provided; not writing file.\n")
 		return nil
 	}
 
-	if err := writeBindZoneFile(a.Output, aws.ToString(zone.Name), records); err != nil {
+	records, err = dns.ResolveAliases(ctx, aws.ToString(zone.Name), records, a.aliasMode, lookupAlias)
+	if err != nil {
+		return err
+	}
+
+	if a.format == dns.FormatBIND {
+		err = writeBindZoneFile(output, aws.ToString(zone.Name), records)
+	} else {
+		err = writeZoneFile(output, a.format, zone, records)
+	}
+	if err != nil {
 		return err
 	}
-	log.Printf("Zone file written to %s\n", a.Output)
+	log.Printf("Zone file written to %s\n", output)
+	return nil
+}
+
+// exportAll writes every zone of accounts to a snapshot. With several
+// accounts each one gets its own <account id>/ subdirectory.
+func (a *exportApp) exportAll(ctx context.Context, accounts []dns.Account) (err error) {
+	output := a.Output
+	if output == "" {
+		output = fmt.Sprintf("%s-%s", a.Profile, time.Now().Format("20060102-150405"))
+	}
+	log.Printf("Exporting all zones to %s as %s\n", output, a.format)
+
+	var w snapshotWriter
+	if !dryRun {
+		if w, err = newSnapshotWriter(output); err != nil {
+			return err
+		}
+		defer func() {
+			err = errors.Join(err, w.Close())
+			if _, ok := w.(*tarSnapshot); ok && err != nil {
+				_ = os.Remove(output)
+			}
+		}()
+	}
+
+	for _, acct := range accounts {
+		dir := ""
+		if a.Accounts.multiple() {
+			dir = acct.ID
+		}
+		if err := a.exportAllFrom(ctx, acct, w, dir); err != nil {
+			if !a.Accounts.multiple() {
+				return err
+			}
+			log.Printf("Skipping account %s: %s\n", accountLabel(a.Profile, acct), err)
+		}
+	}
+	if !dryRun {
+		log.Printf("Snapshot written to %s\n", output)
+	}
 	return nil
 }
 
 func newExportCommand() *cobra.Command {
 	a := &exportApp{}
 	c := &cobra.Command{
-		Use:   "export <profile> <zone>",
-		Short: "Export a Route53 zone to a BIND 9 zone file",
-		Args:  cobra.MinimumNArgs(2),
+		Use:   "export <profile> [zone|zone_id]",
+		Short: "Export a Route53 zone, or all of them, to a BIND 9 zone file or an infrastructure-as-code format",
+		Args:  cobra.RangeArgs(1, 2),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			a.Profile = args[0]
-			a.Zone = args[1]
+			if len(args) > 1 {
+				a.Zone = args[1]
+			}
 			return a.Run(cmd.Context())
 		},
 		SilenceErrors: true,
 		SilenceUsage:  true,
 	}
 	f := c.Flags()
-	f.StringVarP(&a.Output, "output", "o", "", "Output file path for the BIND zone (default: <zone>-<timestamp>.zone)")
+	f.StringVarP(&a.Output, "output", "o", "", "Output file path (default: <zone>-<timestamp>.<extension>); with --all a directory, or an archive when it ends in .tar.gz or .tgz (default: <profile>-<timestamp>)")
+	f.BoolVar(&a.All, "all", false, "Export every hosted zone matching the filters, with a manifest.json of tags and zone metadata")
+	f.StringVar(&a.Format, "format", string(dns.FormatBIND), fmt.Sprintf("Output format, one of %v", dns.ExportFormats))
+	f.StringVar(&a.Aliases, "aliases", string(dns.AliasR53), "How to export alias records: r53_alias (commented dnscontrol R53_ALIAS lines), flatten (resolve to A/AAAA) or cname (non-apex names only)")
+	a.Filter.addFlags(c)
+	a.Accounts.addFlags(c)
 	return c
 }
@@ -0,0 +1,258 @@
+package cli
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	rtypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/pedrokiefer/route53copy/pkg/dns"
+)
+
+const manifestName = "manifest.json"
+
+// exportManifest describes the zones of one account in an export --all
+// snapshot. It holds no timestamps so unchanged zones produce no diff.
+type exportManifest struct {
+	Account string               `json:"account,omitempty"`
+	Format  dns.ExportFormat     `json:"format"`
+	Aliases dns.AliasMode        `json:"aliases"`
+	Zones   []exportManifestZone `json:"zones"`
+}
+
+type exportManifestZone struct {
+	Name       string            `json:"name"`
+	ID         string            `json:"id"`
+	File       string            `json:"file"`
+	Private    bool              `json:"private,omitempty"`
+	Comment    string            `json:"comment,omitempty"`
+	RecordSets int               `json:"record_sets"`
+	Tags       map[string]string `json:"tags,omitempty"`
+	VPCs       []dns.VPC         `json:"vpcs,omitempty"`
+}
+
+// snapshotWriter receives the files of an export --all run.
+type snapshotWriter interface {
+	WriteFile(name string, data []byte) error
+	Close() error
+}
+
+// newSnapshotWriter writes to a tar.gz archive when output ends in .tar.gz
+// or .tgz, to a directory otherwise.
+func newSnapshotWriter(output string) (snapshotWriter, error) {
+	if strings.HasSuffix(output, ".tar.gz") || strings.HasSuffix(output, ".tgz") {
+		f, err := os.Create(output)
+		if err != nil {
+			return nil, err
+		}
+		gz := gzip.NewWriter(f)
+		return &tarSnapshot{f: f, gz: gz, tw: tar.NewWriter(gz)}, nil
+	}
+	if err := os.MkdirAll(output, 0o755); err != nil {
+		return nil, err
+	}
+	return &dirSnapshot{root: output}, nil
+}
+
+type dirSnapshot struct {
+	root string
+}
+
+func (d *dirSnapshot) WriteFile(name string, data []byte) error {
+	p := filepath.Join(d.root, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(p, data, 0o644)
+}
+
+func (d *dirSnapshot) Close() error { return nil }
+
+// prune removes the zone files a previous snapshot of dir listed that this
+// one no longer has, so deleted zones show up as deletions.
+func (d *dirSnapshot) prune(dir string, keep []exportManifestZone) error {
+	b, err := os.ReadFile(filepath.Join(d.root, filepath.FromSlash(dir), manifestName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var old exportManifest
+	if err := json.Unmarshal(b, &old); err != nil {
+		return fmt.Errorf("reading previous %s: %w", manifestName, err)
+	}
+	kept := map[string]bool{}
+	for _, z := range keep {
+		kept[z.File] = true
+	}
+	for _, z := range old.Zones {
+		// Only plain file names are ours to remove.
+		if kept[z.File] || z.File != filepath.Base(z.File) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(d.root, filepath.FromSlash(dir), z.File)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		log.Printf("Removed %s, zone %s is gone\n", path.Join(dir, z.File), z.Name)
+	}
+	return nil
+}
+
+// tarSnapshot writes a reproducible archive: entries carry no timestamps or
+// owners, and the gzip header has no name or time.
+type tarSnapshot struct {
+	f  *os.File
+	gz *gzip.Writer
+	tw *tar.Writer
+}
+
+func (t *tarSnapshot) WriteFile(name string, data []byte) error {
+	hdr := &tar.Header{
+		Name:     name,
+		Mode:     0o644,
+		Size:     int64(len(data)),
+		ModTime:  time.Unix(0, 0).UTC(),
+		Typeflag: tar.TypeReg,
+		Format:   tar.FormatPAX,
+	}
+	if err := t.tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err := t.tw.Write(data)
+	return err
+}
+
+func (t *tarSnapshot) Close() error {
+	return errors.Join(t.tw.Close(), t.gz.Close(), t.f.Close())
+}
+
+// exportAllFrom writes every zone of the account, and its manifest, under
+// dir.
+func (a *exportApp) exportAllFrom(ctx context.Context, acct dns.Account, w snapshotWriter, dir string) error {
+	manager, err := routeManagerFor(ctx, a.Profile, acct)
+	if err != nil {
+		return err
+	}
+	zones, err := manager.ListHostedZones(ctx)
+	if err != nil {
+		return err
+	}
+	sort.Slice(zones, func(i, j int) bool {
+		ni, nj := strings.ToLower(aws.ToString(zones[i].Name)), strings.ToLower(aws.ToString(zones[j].Name))
+		if ni != nj {
+			return ni < nj
+		}
+		return aws.ToString(zones[i].Id) < aws.ToString(zones[j].Id)
+	})
+	names := map[string]int{}
+	for _, z := range zones {
+		names[strings.ToLower(aws.ToString(z.Name))]++
+	}
+
+	manifest := exportManifest{Account: acct.ID, Format: a.format, Aliases: a.aliasMode, Zones: []exportManifestZone{}}
+	zf := a.Filter.zoneFilter()
+	for _, zone := range zones {
+		zoneID := aws.ToString(zone.Id)
+		var vpcs []dns.VPC
+		if dns.IsPrivateZone(zone) {
+			if vpcs, err = manager.GetZoneVPCs(ctx, zoneID); err != nil {
+				return err
+			}
+		}
+		if !zf.Match(zone, vpcs) {
+			continue
+		}
+
+		entry := exportManifestZone{
+			Name:    aws.ToString(zone.Name),
+			ID:      strings.TrimPrefix(zoneID, "/hostedzone/"),
+			File:    snapshotFileName(zone, names, a.format),
+			Private: dns.IsPrivateZone(zone),
+			VPCs:    vpcs,
+		}
+		if zone.Config != nil {
+			entry.Comment = aws.ToString(zone.Config.Comment)
+		}
+		tags, err := manager.GetZoneTags(ctx, zoneID)
+		if err != nil {
+			return err
+		}
+		for _, t := range tags {
+			if entry.Tags == nil {
+				entry.Tags = map[string]string{}
+			}
+			entry.Tags[t.Name] = t.Value
+		}
+
+		records, err := manager.GetResourceRecords(ctx, zoneID)
+		if err != nil {
+			return err
+		}
+		entry.RecordSets = len(records)
+		manifest.Zones = append(manifest.Zones, entry)
+
+		log.Printf("Exporting zone %s to %s (records: %d)\n", entry.Name, path.Join(dir, entry.File), len(records))
+		if dryRun {
+			continue
+		}
+		data, err := a.renderZone(ctx, zone, records)
+		if err != nil {
+			return fmt.Errorf("%s: %w", entry.Name, err)
+		}
+		if err := w.WriteFile(path.Join(dir, entry.File), data); err != nil {
+			return err
+		}
+	}
+
+	if dryRun {
+		log.Printf("--dry provided; not writing %d zones.\n", len(manifest.Zones))
+		return nil
+	}
+	if d, ok := w.(*dirSnapshot); ok {
+		if err := d.prune(dir, manifest.Zones); err != nil {
+			return err
+		}
+	}
+	b, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return w.WriteFile(path.Join(dir, manifestName), append(b, '\n'))
+}
+
+func (a *exportApp) renderZone(ctx context.Context, zone rtypes.HostedZone, records []rtypes.ResourceRecordSet) ([]byte, error) {
+	records, err := dns.ResolveAliases(ctx, aws.ToString(zone.Name), records, a.aliasMode, lookupAlias)
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	if err := dns.WriteZone(&b, a.format, zone, records); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// snapshotFileName names a zone's file after the zone, adding its ID only
+// when several zones share the name.
+func snapshotFileName(zone rtypes.HostedZone, names map[string]int, format dns.ExportFormat) string {
+	name := strings.ToLower(dns.DenormalizeDomain(aws.ToString(zone.Name)))
+	name = strings.ReplaceAll(name, `\052`, "_wildcard_")
+	name = strings.ReplaceAll(name, "/", "_")
+	if names[strings.ToLower(aws.ToString(zone.Name))] > 1 {
+		name += "_" + strings.TrimPrefix(aws.ToString(zone.Id), "/hostedzone/")
+	}
+	return name + "." + format.Extension()
+}
@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	rtypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/pedrokiefer/route53copy/pkg/dns"
+	"github.com/pedrokiefer/route53copy/pkg/dns/route53test"
+	"github.com/stretchr/testify/require"
+)
+
+func snapshotAccount(t *testing.T) *route53test.Server {
+	t.Helper()
+	srv := fakeAccounts(t, "p")["p"]
+	b := srv.AddZone("b.example")
+	require.NoError(t, srv.AddRecordSets(b, rtypes.ResourceRecordSet{
+		Name: aws.String("www.b.example."), Type: rtypes.RRTypeA, TTL: aws.Int64(300),
+		ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String("192.0.2.1")}},
+	}))
+	require.NoError(t, srv.SetTags(b, map[string]string{"team": "web", "env": "prod"}))
+	srv.AddZone("a.example")
+	srv.AddPrivateZone("a.example", "vpc-1", "us-east-1")
+	return srv
+}
+
+func readManifest(t *testing.T, path string) exportManifest {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var m exportManifest
+	require.NoError(t, json.Unmarshal(b, &m))
+	return m
+}
+
+func TestExport_All_Directory(t *testing.T) {
+	srv := snapshotAccount(t)
+	out := filepath.Join(t.TempDir(), "snapshot")
+
+	a := &exportApp{Profile: "p", All: true, Output: out}
+	require.NoError(t, a.Run(context.Background()))
+
+	m := readManifest(t, filepath.Join(out, manifestName))
+	require.Equal(t, dns.FormatBIND, m.Format)
+	require.Len(t, m.Zones, 3)
+	zones := srv.Zones()
+	publicA := strings.TrimPrefix(aws.ToString(zones[1].Id), "/hostedzone/")
+	privateA := strings.TrimPrefix(aws.ToString(zones[2].Id), "/hostedzone/")
+	// Sorted by name then ID; the shared name is told apart by zone ID.
+	require.Equal(t, []string{"a.example_" + publicA + ".zone", "a.example_" + privateA + ".zone", "b.example.zone"},
+		[]string{m.Zones[0].File, m.Zones[1].File, m.Zones[2].File})
+	require.True(t, m.Zones[1].Private)
+	require.Equal(t, []dns.VPC{{ID: "vpc-1", Region: "us-east-1"}}, m.Zones[1].VPCs)
+	require.Equal(t, map[string]string{"team": "web", "env": "prod"}, m.Zones[2].Tags)
+	require.Equal(t, 3, m.Zones[2].RecordSets)
+
+	zone, err := os.ReadFile(filepath.Join(out, "b.example.zone"))
+	require.NoError(t, err)
+	require.Contains(t, string(zone), "192.0.2.1")
+
+	// A second run over an unchanged account rewrites identical files, and
+	// zones that disappeared are removed.
+	before, err := os.ReadFile(filepath.Join(out, manifestName))
+	require.NoError(t, err)
+	require.NoError(t, a.Run(context.Background()))
+	after, err := os.ReadFile(filepath.Join(out, manifestName))
+	require.NoError(t, err)
+	require.Equal(t, string(before), string(after))
+
+	a.Filter.Private = true
+	require.NoError(t, a.Run(context.Background()))
+	require.Len(t, readManifest(t, filepath.Join(out, manifestName)).Zones, 1)
+	require.NoFileExists(t, filepath.Join(out, "b.example.zone"))
+	require.FileExists(t, filepath.Join(out, "a.example_"+privateA+".zone"))
+}
+
+func TestExport_All_TarGz(t *testing.T) {
+	snapshotAccount(t)
+	dir := t.TempDir()
+
+	run := func(name string) []byte {
+		out := filepath.Join(dir, name)
+		a := &exportApp{Profile: "p", All: true, Output: out, Format: "json"}
+		require.NoError(t, a.Run(context.Background()))
+		b, err := os.ReadFile(out)
+		require.NoError(t, err)
+		return b
+	}
+	first := run("one.tar.gz")
+	require.Equal(t, first, run("two.tgz"), "archives of the same zones must be byte-identical")
+
+	gz, err := gzip.NewReader(strings.NewReader(string(first)))
+	require.NoError(t, err)
+	tr := tar.NewReader(gz)
+	names := []string{}
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		names = append(names, hdr.Name)
+	}
+	require.Len(t, names, 4)
+	require.Equal(t, "b.example.json", names[2])
+	require.Equal(t, manifestName, names[3])
+}
+
+func TestExport_All_Args(t *testing.T) {
+	err := (&exportApp{Profile: "p", All: true, Zone: "example.com"}).Run(context.Background())
+	require.ErrorContains(t, err, "drop the zone argument")
+	err = (&exportApp{Profile: "p"}).Run(context.Background())
+	require.ErrorContains(t, err, "a zone is required")
+}
+
+func TestExport_All_DryRunWritesNothing(t *testing.T) {
+	snapshotAccount(t)
+	out := filepath.Join(t.TempDir(), "snapshot")
+	dryRun = true
+	t.Cleanup(func() { dryRun = false })
+
+	require.NoError(t, (&exportApp{Profile: "p", All: true, Output: out}).Run(context.Background()))
+	require.NoDirExists(t, out)
+}
@@ -2,6 +2,8 @@ package cli
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -23,7 +25,9 @@ func TestExport_Run_DryRunSkipsWrite(t *testing.T) {
 			},
 		},
 	}
-	newRouteManager = func(ctx context.Context, profile string, rmo *dns.RouteManagerOptions) RouteManagerAPI { return fake }
+	newRouteManager = func(ctx context.Context, o dns.AWSOptions, rmo *dns.RouteManagerOptions) (RouteManagerAPI, error) {
+		return fake, nil
+	}
 
 	wrote := false
 	writeBindZoneFile = func(outputPath, zone string, records []rtypes.ResourceRecordSet) error {
@@ -38,3 +42,70 @@ func TestExport_Run_DryRunSkipsWrite(t *testing.T) {
 	require.NoError(t, err)
 	require.False(t, wrote, "should not write when dry run")
 }
+
+func TestExport_Run_FlattensAliases(t *testing.T) {
+	oldNewRM := newRouteManager
+	oldWB := writeBindZoneFile
+	oldLookup := lookupAlias
+	t.Cleanup(func() { newRouteManager = oldNewRM; writeBindZoneFile = oldWB; lookupAlias = oldLookup })
+
+	fake := &fakeRouteManager{
+		HostedZone: rtypes.HostedZone{Id: aws.String("/hostedzone/Z1"), Name: aws.String("example.com.")},
+		RecordsByID: map[string][]rtypes.ResourceRecordSet{
+			"/hostedzone/Z1": {
+				{Name: aws.String("example.com."), Type: rtypes.RRTypeA, AliasTarget: &rtypes.AliasTarget{
+					HostedZoneId: aws.String("Z2FDTNDATAQYW2"), DNSName: aws.String("d111.cloudfront.net."),
+				}},
+			},
+		},
+	}
+	newRouteManager = func(ctx context.Context, o dns.AWSOptions, rmo *dns.RouteManagerOptions) (RouteManagerAPI, error) {
+		return fake, nil
+	}
+	lookupAlias = func(ctx context.Context, name, rtype string) ([]string, uint32, error) {
+		return []string{"192.0.2.1"}, 60, nil
+	}
+	var written []rtypes.ResourceRecordSet
+	writeBindZoneFile = func(outputPath, zone string, records []rtypes.ResourceRecordSet) error {
+		written = records
+		return nil
+	}
+
+	a := &exportApp{Profile: "p", Zone: "example.com.", Output: "out.zone", Aliases: "flatten"}
+	require.NoError(t, a.Run(context.Background()))
+	require.Len(t, written, 1)
+	require.Nil(t, written[0].AliasTarget)
+	require.Equal(t, "192.0.2.1", aws.ToString(written[0].ResourceRecords[0].Value))
+
+	a = &exportApp{Profile: "p", Zone: "example.com.", Aliases: "nope"}
+	require.ErrorContains(t, a.Run(context.Background()), "unknown alias mode")
+}
+
+func TestExport_Run_Format(t *testing.T) {
+	oldNewRM := newRouteManager
+	t.Cleanup(func() { newRouteManager = oldNewRM })
+
+	fake := &fakeRouteManager{
+		HostedZone: rtypes.HostedZone{Id: aws.String("/hostedzone/Z1"), Name: aws.String("example.com.")},
+		RecordsByID: map[string][]rtypes.ResourceRecordSet{
+			"/hostedzone/Z1": {
+				{Name: aws.String("www.example.com."), Type: rtypes.RRTypeA, TTL: aws.Int64(300),
+					ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String("192.0.2.1")}}},
+			},
+		},
+	}
+	newRouteManager = func(ctx context.Context, o dns.AWSOptions, rmo *dns.RouteManagerOptions) (RouteManagerAPI, error) {
+		return fake, nil
+	}
+
+	out := filepath.Join(t.TempDir(), "example.tf")
+	a := &exportApp{Profile: "p", Zone: "example.com.", Output: out, Format: "terraform"}
+	require.NoError(t, a.Run(context.Background()))
+	b, err := os.ReadFile(out)
+	require.NoError(t, err)
+	require.Contains(t, string(b), `resource "aws_route53_record" "www_a" {`)
+	require.Contains(t, string(b), `id = "Z1_www.example.com_A"`)
+
+	a = &exportApp{Profile: "p", Zone: "example.com.", Format: "xml"}
+	require.ErrorContains(t, a.Run(context.Background()), `unknown export format "xml"`)
+}
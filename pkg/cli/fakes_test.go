@@ -2,6 +2,7 @@ package cli
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -14,15 +15,47 @@ type fakeRouteManager struct {
 	Zones       []rtypes.HostedZone
 	RecordsByID map[string][]rtypes.ResourceRecordSet
 	NSByID      map[string]rtypes.ResourceRecordSet
+	// ZonesByName, when set, makes GetHostedZone look zones up by name and
+	// return dns.HostedZoneNotFound for unknown ones.
+	ZonesByName map[string]rtypes.HostedZone
+	TagsByID    map[string][]dns.Tag
+	VPCsByID    map[string][]dns.VPC
 
 	UpdateRecordsCalled bool
+	UpdatedNSDomains    []string
+	CreateZoneOptions   *dns.CreateZoneOptions
+	AssociatedVPCs      []dns.VPC
 	DeleteRecordsCalled bool
 	DeleteZoneCalled    bool
 }
 
 func (f *fakeRouteManager) GetHostedZone(ctx context.Context, domain string) (rtypes.HostedZone, error) {
+	if f.ZonesByName != nil {
+		z, ok := f.ZonesByName[dns.NormalizeDomain(domain)]
+		if !ok {
+			return rtypes.HostedZone{}, &dns.HostedZoneNotFound{Zone: domain}
+		}
+		return z, nil
+	}
 	return f.HostedZone, nil
 }
+func (f *fakeRouteManager) FindHostedZone(ctx context.Context, domain string, filter dns.ZoneFilter) (rtypes.HostedZone, error) {
+	z, err := f.GetHostedZone(ctx, domain)
+	if err != nil {
+		return z, err
+	}
+	if !filter.Match(z, f.VPCsByID[aws.ToString(z.Id)]) {
+		return rtypes.HostedZone{}, &dns.HostedZoneNotFound{Zone: domain}
+	}
+	return z, nil
+}
+func (f *fakeRouteManager) GetZoneVPCs(ctx context.Context, zoneID string) ([]dns.VPC, error) {
+	return f.VPCsByID[zoneID], nil
+}
+func (f *fakeRouteManager) AssociateVPC(ctx context.Context, zoneID string, vpc dns.VPC) error {
+	f.AssociatedVPCs = append(f.AssociatedVPCs, vpc)
+	return nil
+}
 func (f *fakeRouteManager) ListHostedZones(ctx context.Context) ([]rtypes.HostedZone, error) {
 	return f.Zones, nil
 }
@@ -39,7 +72,7 @@ func (f *fakeRouteManager) GetNSRecords(ctx context.Context, zoneId string) (rty
 	return f.NSByID[zoneId], nil
 }
 func (f *fakeRouteManager) CreateChanges(domain string, recordSets []rtypes.ResourceRecordSet) []rtypes.Change {
-	return dns.NewRouteManager(context.Background(), "", &dns.RouteManagerOptions{}).CreateChanges(domain, recordSets)
+	return (&dns.RouteManager{}).CreateChanges(domain, recordSets)
 }
 func (f *fakeRouteManager) UpdateRecords(ctx context.Context, comment, zoneId string, changes []rtypes.Change) (*rtypes.ChangeInfo, error) {
 	f.UpdateRecordsCalled = true
@@ -48,11 +81,13 @@ func (f *fakeRouteManager) UpdateRecords(ctx context.Context, comment, zoneId st
 func (f *fakeRouteManager) WaitForChange(ctx context.Context, changeId string, maxWait time.Duration) error {
 	return nil
 }
-func (f *fakeRouteManager) GetOrCreateZone(ctx context.Context, domain string) (rtypes.HostedZone, error) {
+func (f *fakeRouteManager) GetOrCreateZone(ctx context.Context, domain string, o dns.CreateZoneOptions) (rtypes.HostedZone, error) {
+	f.CreateZoneOptions = &o
 	return f.HostedZone, nil
 }
 func (f *fakeRouteManager) UpdateNSRecords(ctx context.Context, domain, zoneId string) (bool, error) {
-	return false, nil
+	f.UpdatedNSDomains = append(f.UpdatedNSDomains, domain)
+	return true, nil
 }
 func (f *fakeRouteManager) DeleteRecords(ctx context.Context, zoneId string, records []rtypes.ResourceRecordSet) (string, error) {
 	f.DeleteRecordsCalled = true
@@ -63,8 +98,27 @@ func (f *fakeRouteManager) DeleteHostedZone(ctx context.Context, zoneId string)
 	return "dzchg", nil
 }
 func (f *fakeRouteManager) GetZoneTags(ctx context.Context, zoneID string) ([]dns.Tag, error) {
-	return nil, nil
+	return f.TagsByID[zoneID], nil
 }
 func (f *fakeRouteManager) UpsertTags(ctx context.Context, zoneID string, tags []dns.Tag) error {
 	return nil
 }
+func (f *fakeRouteManager) DeleteTags(ctx context.Context, zoneID string, keys []string) error {
+	return nil
+}
+
+type fakeDomainManager struct {
+	Domains []string
+	Details map[string]*dns.DomainDetail
+}
+
+func (f *fakeDomainManager) ListRegisteredDomains(ctx context.Context) ([]string, error) {
+	return f.Domains, nil
+}
+func (f *fakeDomainManager) GetDomainDetail(ctx context.Context, domain string) (*dns.DomainDetail, error) {
+	d, ok := f.Details[domain]
+	if !ok {
+		return nil, fmt.Errorf("domain %s not registered", domain)
+	}
+	return d, nil
+}
@@ -14,8 +14,10 @@ import (
 )
 
 type findApp struct {
-	Profile string
-	Key     string
+	Profile  string
+	Key      string
+	Zones    zoneSelector
+	Accounts accountSelector
 }
 
 func init() {
@@ -49,24 +51,47 @@ func resourceRecordsToString(rr []rtypes.ResourceRecord) string {
 }
 
 func (a *findApp) Run(ctx context.Context) error {
-	manager := dns.NewRouteManager(ctx, a.Profile, &dns.RouteManagerOptions{
-		NoWait: noWait,
-	})
-
 	r, err := regexp.Compile(a.Key)
 	if err != nil {
 		return err
 	}
 
-	zones, err := manager.ListHostedZones(ctx)
+	accounts, err := a.Accounts.accounts(ctx, a.Profile)
 	if err != nil {
 		return err
 	}
 
-	log.Printf("Found %d zones in %s\n", len(zones), a.Profile)
+	for _, acct := range accounts {
+		label := accountLabel(a.Profile, acct)
+		if err := a.findInAccount(ctx, r, acct); err != nil {
+			if !a.Accounts.multiple() {
+				return err
+			}
+			log.Printf("Skipping account %s: %s\n", label, err)
+		}
+	}
+	return nil
+}
+
+func (a *findApp) findInAccount(ctx context.Context, r *regexp.Regexp, acct dns.Account) error {
+	manager, err := routeManagerFor(ctx, a.Profile, acct)
+	if err != nil {
+		return err
+	}
+	label := accountLabel(a.Profile, acct)
+
+	zones, err := a.Zones.Select(ctx, manager)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Found %d zones in %s\n", len(zones), label)
 	log.Printf("Fetching records...\n")
 
-	records := map[string][]rtypes.ResourceRecordSet{}
+	prefix := ""
+	if a.Accounts.multiple() {
+		prefix = label + " "
+	}
 
 	for _, zone := range zones {
 		rs, err := manager.GetResourceRecords(ctx, aws.ToString(zone.Id))
@@ -74,10 +99,6 @@ func (a *findApp) Run(ctx context.Context) error {
 			log.Printf("failed to list records for zone %s: %s", aws.ToString(zone.Name), err)
 			continue
 		}
-		records[aws.ToString(zone.Name)] = rs
-	}
-
-	for zone, rs := range records {
 		for _, entry := range rs {
 			if matchInResourceRecord(r, entry) {
 				var value string
@@ -87,16 +108,15 @@ func (a *findApp) Run(ctx context.Context) error {
 				if len(entry.ResourceRecords) > 0 {
 					value = resourceRecordsToString(entry.ResourceRecords)
 				}
-				fmt.Printf("%s: %s -> %s\n", zone, aws.ToString(entry.Name), value)
+				fmt.Fprintf(stdout, "%s%s: %s -> %s\n", prefix, aws.ToString(zone.Name), aws.ToString(entry.Name), value)
 			}
 		}
 	}
-
 	return nil
 }
 
 func newFindCommand() *cobra.Command {
-	a := findApp{}
+	a := findApp{Zones: zoneSelector{defaultAll: true}}
 
 	c := &cobra.Command{
 		Use:   "find <profile> <key>",
@@ -110,5 +130,7 @@ func newFindCommand() *cobra.Command {
 		SilenceErrors: true,
 		SilenceUsage:  true,
 	}
+	a.Zones.addFlags(c)
+	a.Accounts.addFlags(c)
 	return c
 }
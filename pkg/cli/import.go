@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	rtypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/pedrokiefer/route53copy/pkg/dns"
+	"github.com/spf13/cobra"
+)
+
+type importApp struct {
+	Profile string
+	File    string
+	// Zone is the hosted zone to import into, by name or ID. It defaults to
+	// the zone named in the file, and names the origin of BIND files
+	// without an r53tool header.
+	Zone string
+
+	Filter zoneFilterFlags
+}
+
+func init() {
+	rootCmd.AddCommand(newImportCommand())
+}
+
+func (a *importApp) Run(ctx context.Context) error {
+	origin := ""
+	if !dns.IsZoneID(a.Zone) {
+		origin = a.Zone
+	}
+	name, records, err := dns.ReadZoneFile(a.File, origin)
+	if err != nil {
+		return err
+	}
+
+	manager, err := newRouteManager(ctx, awsOptions(a.Profile), &dns.RouteManagerOptions{NoWait: noWait})
+	if err != nil {
+		return err
+	}
+	target := a.Zone
+	if target == "" {
+		target = name
+	}
+	zone, err := manager.FindHostedZone(ctx, target, a.Filter.zoneFilter())
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(aws.ToString(zone.Name), name) {
+		return fmt.Errorf("%s holds records of %s, not %s", a.File, name, aws.ToString(zone.Name))
+	}
+	zoneID := aws.ToString(zone.Id)
+
+	changes := manager.CreateChanges(name, records)
+	log.Printf("Importing %d record sets from %s into %s (%s)\n", len(changes), a.File, name, zoneID)
+	if len(changes) == 0 {
+		return nil
+	}
+	if dryRun {
+		log.Printf("--dry provided; not importing records.\n")
+		return nil
+	}
+
+	changeInfo, err := manager.UpdateRecords(ctx, "Importing records from "+a.File, zoneID, changes)
+	if err != nil {
+		return err
+	}
+	if changeInfo.Status != rtypes.ChangeStatusInsync {
+		start := time.Now()
+		if err := manager.WaitForChange(ctx, aws.ToString(changeInfo.Id), 2*time.Minute); err != nil {
+			return err
+		}
+		log.Printf("%d record sets in '%s' are in sync after %s\n", len(changes), name, time.Since(start))
+	}
+	return nil
+}
+
+func newImportCommand() *cobra.Command {
+	a := &importApp{}
+	c := &cobra.Command{
+		Use:   "import <profile> <file>",
+		Short: "Upsert the records of an exported BIND, JSON or YAML zone file into its hosted zone",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			a.Profile = args[0]
+			a.File = args[1]
+			return a.Run(cmd.Context())
+		},
+		SilenceErrors: true,
+		SilenceUsage:  true,
+	}
+	f := c.Flags()
+	f.StringVar(&a.Zone, "zone", "", "Zone name or ID to import into (default: the zone named in the file)")
+	a.Filter.addFlags(c)
+	return c
+}
@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/olekukonko/tablewriter"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+	outputCSV   = "csv"
+)
+
+var outputFormats = []string{outputTable, outputJSON, outputYAML, outputCSV}
+
+// tabular is implemented by results that can be flattened into a table or
+// CSV row. JSON and YAML output use the struct tags instead.
+type tabular interface {
+	columns() []string
+	row() []string
+}
+
+func validateOutputFormat(format string) error {
+	for _, f := range outputFormats {
+		if f == format {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown output format %q, expected one of %v", format, outputFormats)
+}
+
+// writeResults renders results in the requested format.
+func writeResults[T tabular](w io.Writer, format string, results []T) error {
+	switch format {
+	case outputJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(results)
+	case outputYAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(results); err != nil {
+			return err
+		}
+		return enc.Close()
+	case outputCSV:
+		cw := csv.NewWriter(w)
+		var zero T
+		if err := cw.Write(zero.columns()); err != nil {
+			return err
+		}
+		for _, r := range results {
+			if err := cw.Write(r.row()); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	case outputTable:
+		var zero T
+		table := tablewriter.NewWriter(w)
+		table.Header(zero.columns())
+		for _, r := range results {
+			if err := table.Append(r.row()); err != nil {
+				return err
+			}
+		}
+		return table.Render()
+	}
+	return validateOutputFormat(format)
+}
@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pedrokiefer/route53copy/pkg/dig"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleResults() []zoneCheckResult {
+	return []zoneCheckResult{{
+		Account:   "prod",
+		Zone:      "example.com.",
+		ZoneID:    "/hostedzone/Z1",
+		LiveNS:    []string{"ns1.example.net."},
+		Route53NS: []string{"ns1.example.net.", "ns2.example.net."},
+		Status:    zoneMismatch,
+	}}
+}
+
+func TestWriteResults_Formats(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeResults(&buf, outputJSON, sampleResults()))
+	require.Contains(t, buf.String(), `"zone_id": "/hostedzone/Z1"`)
+	require.Contains(t, buf.String(), `"status": "mismatch"`)
+
+	buf.Reset()
+	require.NoError(t, writeResults(&buf, outputYAML, sampleResults()))
+	require.Contains(t, buf.String(), "- account: prod\n  zone: example.com.\n")
+	require.Contains(t, buf.String(), "    - ns2.example.net.\n")
+
+	buf.Reset()
+	require.NoError(t, writeResults(&buf, outputCSV, sampleResults()))
+	require.Equal(t, "Account,Zone,Zone ID,Status,Live NS,Route53 NS,Error\n"+
+		"prod,example.com.,/hostedzone/Z1,mismatch,ns1.example.net.,ns1.example.net. ns2.example.net.,\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, writeResults(&buf, outputTable, sampleResults()))
+	require.Contains(t, buf.String(), "mismatch")
+}
+
+func TestWriteResults_DelegationYAML(t *testing.T) {
+	results := sampleResults()
+	results[0].Delegation = &dig.DelegationReport{
+		Zone:       "example.com.",
+		ParentZone: "com.",
+		ParentNS:   []string{"a.gtld-servers.net."},
+		ExpectedNS: []string{"ns1.example.net."},
+		Servers:    []dig.NameserverCheck{{Name: "ns1.example.net.", Authoritative: true}},
+		Issues:     []dig.DelegationIssue{{Kind: dig.IssueParentMismatch, Detail: "parent lists ns1 only"}},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeResults(&buf, outputYAML, results))
+	out := buf.String()
+	require.Contains(t, out, "    parent_zone: com.\n    parent_ns:\n      - a.gtld-servers.net.\n")
+	require.Contains(t, out, "    expected_ns:\n")
+	require.Contains(t, out, "      - name: ns1.example.net.\n        authoritative: true\n")
+	require.Contains(t, out, "      - kind: parent_child_mismatch\n")
+	require.NotContains(t, out, "parentzone")
+	require.NotContains(t, out, "glue", "empty fields are left out")
+	require.NotContains(t, out, "serial")
+}
+
+func TestWriteResults_UnknownFormat(t *testing.T) {
+	require.Error(t, writeResults(&bytes.Buffer{}, "xml", sampleResults()))
+	require.Error(t, validateOutputFormat("xml"))
+}
@@ -5,12 +5,13 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"os"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	rtypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
-	"github.com/manifoldco/promptui"
 	"github.com/pedrokiefer/route53copy/pkg/dns"
 	"github.com/spf13/cobra"
 	"inet.af/netaddr"
@@ -25,12 +26,19 @@ type parkApp struct {
 	Hostname string
 	ZoneID   string
 
-	Domain     string
-	AllDomains bool
-	Alias      bool
-	Force      bool
-
-	service RouteManagerAPI
+	Zones zoneSelector
+	Alias bool
+	// DualStack adds an AAAA alias next to the A alias.
+	DualStack bool
+	Force     bool
+	// Template names a built-in dns.ParkingTemplate or a YAML file with the
+	// records added on top of the parking target.
+	Template string
+	// StateDir holds the snapshots unpark restores zones from.
+	StateDir string
+
+	service  RouteManagerAPI
+	template *dns.ParkingTemplate
 }
 
 func init() {
@@ -38,26 +46,21 @@ func init() {
 }
 
 func (a *parkApp) Run(ctx context.Context) error {
-	a.service = newRouteManager(ctx, a.Profile, &dns.RouteManagerOptions{
-		NoWait: noWait,
-	})
-	log.Printf("Parking domains in %s...\n", a.Profile)
-
-	if a.Domain != "" {
-		zone, err := a.service.GetHostedZone(ctx, a.Domain)
-		if err != nil {
+	var err error
+	if a.Template != "" {
+		if a.template, err = dns.LoadParkingTemplate(a.Template); err != nil {
 			return err
 		}
-
-		err = a.parkZone(ctx, zone)
-		return err
 	}
-
-	if !a.AllDomains {
-		return errors.New("-a or -d is required")
+	a.service, err = newRouteManager(ctx, awsOptions(a.Profile), &dns.RouteManagerOptions{
+		NoWait: noWait,
+	})
+	if err != nil {
+		return err
 	}
+	log.Printf("Parking domains in %s...\n", a.Profile)
 
-	zones, err := a.service.ListHostedZones(ctx)
+	zones, err := a.Zones.Select(ctx, a.service)
 	if err != nil {
 		return err
 	}
@@ -65,6 +68,10 @@ func (a *parkApp) Run(ctx context.Context) error {
 	for _, zone := range zones {
 		err := a.parkZone(ctx, zone)
 		if err != nil {
+			// Every other zone would need the same confirmation.
+			if len(zones) == 1 || errors.Is(err, errConfirmationNeeded) {
+				return err
+			}
 			log.Printf("error parking zone %s: %+v", aws.ToString(zone.Name), err)
 		}
 	}
@@ -83,56 +90,45 @@ func (a *parkApp) parkZone(ctx context.Context, zone rtypes.HostedZone) error {
 	parked := hasParkedTag(tags)
 	count := aws.ToInt64(zone.ResourceRecordSetCount)
 
-	if count > 2 {
-		if !a.Force {
-			log.Printf("Skipping %s (has %d records)", zoneName, count)
-			return nil
-		}
-
-		records, err := a.service.GetResourceRecords(ctx, zoneID)
-		if err != nil {
-			return err
-		}
-		_, pt := dns.FindParkedResourceRecord(records, zoneName)
-		if (pt.HasARecord || pt.HasAAAARecord) && pt.HasWWWCnameRecord && !parked {
-			prompt := promptui.Prompt{
-				Label:     "[WARNING] Domain is in use. Do you want to overwrite those entries?",
-				IsConfirm: true,
-			}
-
-			result, err := prompt.Run()
-			if err != nil {
-				fmt.Printf("Prompt failed %v\n", err)
-				return nil
-			}
+	if count > 2 && !a.Force {
+		log.Printf("Skipping %s (has %d records)", zoneName, count)
+		return nil
+	}
 
-			if result != "y" {
-				log.Printf("Aborting\n")
-				return nil
-			}
-		}
+	changes, err := a.createChanges(aws.ToString(zone.Name))
+	if err != nil {
+		return err
 	}
+	records, err := a.service.GetResourceRecords(ctx, zoneID)
+	if err != nil {
+		return err
+	}
+	changes = dropConflictingSets(records, changes)
 
 	if parked {
-		prompt := promptui.Prompt{
-			Label:     "Domain already parked. Do you want to update those entries?",
-			IsConfirm: true,
-		}
-
-		result, err := prompt.Run()
+		ok, err := confirmation().Confirm("Domain already parked. Do you want to update those entries?")
 		if err != nil {
-			fmt.Printf("Prompt failed %v\n", err)
+			return err
+		}
+		if !ok {
+			log.Printf("Aborting\n")
 			return nil
 		}
-
-		if result != "y" {
+	} else if inUse := overwrittenSets(records, changes); len(inUse) > 0 {
+		ok, err := confirmation().Confirm(fmt.Sprintf("[WARNING] Domain is in use: parking overwrites %s. Do you want to overwrite those entries?", strings.Join(inUse, ", ")))
+		if err != nil {
+			return err
+		}
+		if !ok {
 			log.Printf("Aborting\n")
 			return nil
 		}
 	}
 
 	log.Printf("Parking %s...\n", zoneName)
-	changes := a.createChanges(aws.ToString(zone.Name))
+	if err := a.saveSnapshot(zone, records, changes, parked); err != nil {
+		return err
+	}
 
 	info, err := a.service.UpdateRecords(ctx, "parking "+zoneName, zoneID, changes)
 	if err != nil {
@@ -155,7 +151,78 @@ func (a *parkApp) parkZone(ctx context.Context, zone rtypes.HostedZone) error {
 	return nil
 }
 
-func (a *parkApp) createChanges(fqdn string) []rtypes.Change {
+// overwrittenSets lists the sets in records that changes replace, by name
+// and type.
+func overwrittenSets(records []rtypes.ResourceRecordSet, changes []rtypes.Change) []string {
+	written := map[string]bool{}
+	for _, c := range changes {
+		written[parkKey(aws.ToString(c.ResourceRecordSet.Name), string(c.ResourceRecordSet.Type))] = true
+	}
+	inUse := []string{}
+	for _, rs := range records {
+		key := parkKey(aws.ToString(rs.Name), string(rs.Type))
+		if written[key] && !slices.Contains(inUse, key) {
+			inUse = append(inUse, key)
+		}
+	}
+	return inUse
+}
+
+// dropConflictingSets leaves out the changes that would put a CNAME next to
+// sets of other types: a template CNAME on a name that already holds other
+// types, like a "*" A record, or a template set on a name that already is a
+// CNAME, like a "_dmarc" CNAME to a hosted DMARC provider. Route53 rejects
+// either, and with it the whole batch.
+func dropConflictingSets(records []rtypes.ResourceRecordSet, changes []rtypes.Change) []rtypes.Change {
+	existing := map[string]rtypes.RRType{}
+	cnames := map[string]bool{}
+	for _, rs := range records {
+		name := parkKey(aws.ToString(rs.Name), "")
+		if rs.Type == rtypes.RRTypeCname {
+			cnames[name] = true
+		} else {
+			existing[name] = rs.Type
+		}
+	}
+	kept := []rtypes.Change{}
+	for _, c := range changes {
+		rs := c.ResourceRecordSet
+		name := parkKey(aws.ToString(rs.Name), "")
+		if t, ok := existing[name]; ok && rs.Type == rtypes.RRTypeCname {
+			log.Printf("Leaving out %s CNAME: the name already has %s records\n", aws.ToString(rs.Name), t)
+			continue
+		}
+		if cnames[name] && rs.Type != rtypes.RRTypeCname {
+			log.Printf("Leaving out %s %s: the name already is a CNAME\n", aws.ToString(rs.Name), rs.Type)
+			continue
+		}
+		kept = append(kept, c)
+	}
+	return kept
+}
+
+// saveSnapshot writes the record sets changes overwrite to the state
+// directory before they are applied.
+func (a *parkApp) saveSnapshot(zone rtypes.HostedZone, records []rtypes.ResourceRecordSet, changes []rtypes.Change, parked bool) error {
+	path, err := parkSnapshotPath(a.StateDir, zone)
+	if err != nil {
+		return err
+	}
+	var previous *parkSnapshot
+	if parked {
+		previous, err = readParkSnapshot(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	if err := writeParkSnapshot(path, newParkSnapshot(zone, records, changes, previous)); err != nil {
+		return err
+	}
+	log.Printf("Saved the records park replaces in %s\n", path)
+	return nil
+}
+
+func (a *parkApp) createChanges(fqdn string) ([]rtypes.Change, error) {
 	changes := []rtypes.Change{
 		{
 			Action: rtypes.ChangeActionUpsert,
@@ -171,15 +238,21 @@ func (a *parkApp) createChanges(fqdn string) []rtypes.Change {
 	}
 
 	if a.Alias {
-		changes = append(changes, rtypes.Change{
-			Action: rtypes.ChangeActionUpsert,
-			ResourceRecordSet: &rtypes.ResourceRecordSet{
-				Name:        aws.String(fqdn),
-				Type:        rtypes.RRTypeA,
-				AliasTarget: &rtypes.AliasTarget{HostedZoneId: aws.String(a.ZoneID), DNSName: aws.String(a.Hostname)},
-			},
-		})
-		return changes
+		types := []rtypes.RRType{rtypes.RRTypeA}
+		if a.DualStack {
+			types = append(types, rtypes.RRTypeAaaa)
+		}
+		for _, t := range types {
+			changes = append(changes, rtypes.Change{
+				Action: rtypes.ChangeActionUpsert,
+				ResourceRecordSet: &rtypes.ResourceRecordSet{
+					Name:        aws.String(fqdn),
+					Type:        t,
+					AliasTarget: &rtypes.AliasTarget{HostedZoneId: aws.String(a.ZoneID), DNSName: aws.String(a.Hostname)},
+				},
+			})
+		}
+		return a.addTemplateChanges(fqdn, changes)
 	}
 
 	if len(a.IPSv4) > 0 {
@@ -199,14 +272,59 @@ func (a *parkApp) createChanges(fqdn string) []rtypes.Change {
 			Action: rtypes.ChangeActionUpsert,
 			ResourceRecordSet: &rtypes.ResourceRecordSet{
 				Name:            aws.String(fqdn),
-				Type:            rtypes.RRTypeA,
+				Type:            rtypes.RRTypeAaaa,
 				ResourceRecords: a.IPSv6,
 				TTL:             aws.Int64(3600),
 			},
 		})
 	}
 
-	return changes
+	return a.addTemplateChanges(fqdn, changes)
+}
+
+// addTemplateChanges appends the records of the parking template, if any.
+func (a *parkApp) addTemplateChanges(fqdn string, changes []rtypes.Change) ([]rtypes.Change, error) {
+	if a.template == nil {
+		return changes, nil
+	}
+	records, err := a.template.RecordSets(fqdn)
+	if err != nil {
+		return nil, fmt.Errorf("parking template %s: %w", a.template.Name, err)
+	}
+	for _, rs := range records {
+		changes = append(changes, rtypes.Change{Action: rtypes.ChangeActionUpsert, ResourceRecordSet: &rs})
+	}
+	return changes, nil
+}
+
+// parseParkIPs splits the destination addresses into the A and AAAA
+// record values. IPv4-mapped IPv6 addresses count as IPv4, and repeated
+// addresses are dropped since Route53 rejects duplicate values.
+func parseParkIPs(args []string) (v4, v6 []rtypes.ResourceRecord, err error) {
+	v4, v6 = []rtypes.ResourceRecord{}, []rtypes.ResourceRecord{}
+	seen := map[netaddr.IP]bool{}
+	for _, v := range args {
+		ip, err := netaddr.ParseIP(v)
+		if err != nil {
+			return nil, nil, err
+		}
+		if ip.Zone() != "" {
+			return nil, nil, fmt.Errorf("%s: scoped IPv6 addresses cannot be published in DNS", v)
+		}
+		ip = ip.Unmap()
+		if seen[ip] {
+			continue
+		}
+		seen[ip] = true
+
+		r := rtypes.ResourceRecord{Value: aws.String(ip.String())}
+		if ip.Is4() {
+			v4 = append(v4, r)
+		} else {
+			v6 = append(v6, r)
+		}
+	}
+	return v4, v6, nil
 }
 
 func hasParkedTag(tags []dns.Tag) bool {
@@ -226,26 +344,16 @@ func newParkCommand() *cobra.Command {
 
 	c := &cobra.Command{
 		Use:   "park <profile> <destination_ips>",
-		Short: "Park is a tool to park domains in Route53 creating A and www CNAME records",
+		Short: "Park is a tool to park domains in Route53 creating A/AAAA and www CNAME records",
 		Args:  cobra.MinimumNArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			a.Profile = args[0]
 
 			if !a.Alias {
-				for _, v := range args[1:] {
-					ip, err := netaddr.ParseIP(v)
-					if err != nil {
-						return err
-					}
-
-					r := rtypes.ResourceRecord{
-						Value: aws.String(ip.String()),
-					}
-					if ip.Is4() {
-						a.IPSv4 = append(a.IPSv4, r)
-					} else {
-						a.IPSv6 = append(a.IPSv6, r)
-					}
+				var err error
+				a.IPSv4, a.IPSv6, err = parseParkIPs(args[1:])
+				if err != nil {
+					return err
 				}
 			} else {
 				if len(args) < 3 {
@@ -263,9 +371,11 @@ func newParkCommand() *cobra.Command {
 
 	f := c.Flags()
 	f.BoolVar(&a.Force, "force", false, "Force park")
-	f.BoolVar(&a.AllDomains, "a", true, "Check all domains")
 	f.BoolVar(&a.Alias, "alias", true, "Use alias for parked domains: <hostname> <zoneId>")
-	f.StringVar(&a.Domain, "d", "", "Check a specific domain")
-	c.MarkFlagsMutuallyExclusive("a", "d")
+	f.BoolVar(&a.DualStack, "dual-stack", false, "With --alias, also create an AAAA alias to the same target; leave off for targets that only take an A alias, like S3 website endpoints")
+	f.StringVar(&a.StateDir, "state-dir", defaultParkStateDir(), "Directory for the snapshots of replaced records that unpark restores")
+	f.StringVar(&a.Template, "template", dns.DefaultParkingTemplate, fmt.Sprintf("Parking template adding records on top of the target: one of %v or a YAML file", dns.BuiltinParkingTemplates()))
+	a.Zones.addFlags(c)
+	a.Zones.addLegacyFlags(c, true)
 	return c
 }
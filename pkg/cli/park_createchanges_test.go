@@ -5,6 +5,7 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	rtypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/pedrokiefer/route53copy/pkg/dns"
 	"github.com/stretchr/testify/require"
 )
 
@@ -14,7 +15,8 @@ func TestParkCreateChanges_WithAlias(t *testing.T) {
 		Hostname: "alias.example.net.",
 		ZoneID:   "Z12345",
 	}
-	changes := a.createChanges("example.com.")
+	changes, err := a.createChanges("example.com.")
+	require.NoError(t, err)
 
 	require.Len(t, changes, 2)
 	// First change is CNAME for www
@@ -33,12 +35,80 @@ func TestParkCreateChanges_WithIPs(t *testing.T) {
 		IPSv4: []rtypes.ResourceRecord{{Value: aws.String("1.2.3.4")}},
 		IPSv6: []rtypes.ResourceRecord{{Value: aws.String("2001:db8::1")}},
 	}
-	changes := a.createChanges("example.com.")
+	changes, err := a.createChanges("example.com.")
+	require.NoError(t, err)
 
 	require.Len(t, changes, 3)
 	// CNAME www
 	require.Equal(t, rtypes.RRTypeCname, changes[0].ResourceRecordSet.Type)
 	// A and AAAA
 	require.Equal(t, rtypes.RRTypeA, changes[1].ResourceRecordSet.Type)
-	require.Equal(t, rtypes.RRTypeA, changes[2].ResourceRecordSet.Type) // Note: code sets TypeA for both blocks; might be a bug, but we assert current behavior
+	require.Equal(t, rtypes.RRTypeAaaa, changes[2].ResourceRecordSet.Type)
+	require.Equal(t, "2001:db8::1", aws.ToString(changes[2].ResourceRecordSet.ResourceRecords[0].Value))
+}
+
+func TestParkCreateChanges_DualStackAlias(t *testing.T) {
+	a := &parkApp{
+		Alias:     true,
+		DualStack: true,
+		Hostname:  "d111.cloudfront.net.",
+		ZoneID:    "Z2FDTNDATAQYW2",
+	}
+	changes, err := a.createChanges("example.com.")
+	require.NoError(t, err)
+
+	require.Len(t, changes, 3)
+	require.Equal(t, rtypes.RRTypeA, changes[1].ResourceRecordSet.Type)
+	require.Equal(t, rtypes.RRTypeAaaa, changes[2].ResourceRecordSet.Type)
+	require.Equal(t, changes[1].ResourceRecordSet.AliasTarget, changes[2].ResourceRecordSet.AliasTarget)
+}
+
+func TestParkCreateChanges_OnlyIPv6(t *testing.T) {
+	a := &parkApp{IPSv6: []rtypes.ResourceRecord{{Value: aws.String("2001:db8::1")}}}
+	changes, err := a.createChanges("example.com.")
+	require.NoError(t, err)
+
+	require.Len(t, changes, 2)
+	require.Equal(t, rtypes.RRTypeAaaa, changes[1].ResourceRecordSet.Type)
+}
+
+func TestParkCreateChanges_Template(t *testing.T) {
+	tmpl, err := dns.LoadParkingTemplate(dns.DefaultParkingTemplate)
+	require.NoError(t, err)
+	a := &parkApp{IPSv4: []rtypes.ResourceRecord{{Value: aws.String("192.0.2.1")}}, template: tmpl}
+	changes, err := a.createChanges("example.com.")
+	require.NoError(t, err)
+
+	require.Len(t, changes, 7)
+	require.Equal(t, rtypes.RRTypeA, changes[1].ResourceRecordSet.Type)
+	require.Equal(t, rtypes.RRTypeMx, changes[2].ResourceRecordSet.Type)
+	require.Equal(t, "*.example.com.", aws.ToString(changes[6].ResourceRecordSet.Name))
+	for _, c := range changes {
+		require.Equal(t, rtypes.ChangeActionUpsert, c.Action)
+	}
+}
+
+func TestParseParkIPs(t *testing.T) {
+	values := func(rrs []rtypes.ResourceRecord) []string {
+		out := []string{}
+		for _, r := range rrs {
+			out = append(out, aws.ToString(r.Value))
+		}
+		return out
+	}
+
+	v4, v6, err := parseParkIPs([]string{"192.0.2.1", "2001:DB8::1", "::ffff:192.0.2.2", "2001:db8:0::1", "192.0.2.1"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"192.0.2.1", "192.0.2.2"}, values(v4))
+	require.Equal(t, []string{"2001:db8::1"}, values(v6))
+
+	v4, v6, err = parseParkIPs([]string{"2001:db8::2"})
+	require.NoError(t, err)
+	require.Empty(t, v4)
+	require.Equal(t, []string{"2001:db8::2"}, values(v6))
+
+	_, _, err = parseParkIPs([]string{"192.0.2.1", "not-an-ip"})
+	require.Error(t, err)
+	_, _, err = parseParkIPs([]string{"fe80::1%eth0"})
+	require.ErrorContains(t, err, "scoped IPv6")
 }
@@ -0,0 +1,177 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	rtypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/pedrokiefer/route53copy/pkg/dns"
+)
+
+// parkSnapshot is what park saves before it overwrites a zone, so unpark
+// can put the zone back. Tag values are too short to hold it, so it is kept
+// in a local state file.
+type parkSnapshot struct {
+	Zone     string    `json:"zone"`
+	ZoneID   string    `json:"zone_id"`
+	ParkedAt time.Time `json:"parked_at"`
+	// Replaced holds the record sets park overwrote, as they were before
+	// the zone was first parked.
+	Replaced []dns.ExportedRecordSet `json:"replaced"`
+	// Parking holds the record sets park wrote.
+	Parking []dns.ExportedRecordSet `json:"parking"`
+}
+
+// defaultParkStateDir is where park snapshots go unless --state-dir is set.
+func defaultParkStateDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "r53tool", "parked")
+}
+
+func parkSnapshotPath(dir string, zone rtypes.HostedZone) (string, error) {
+	if dir == "" {
+		return "", errors.New("no user config directory to keep park snapshots in; set --state-dir")
+	}
+	name := strings.ToLower(dns.DenormalizeDomain(aws.ToString(zone.Name)))
+	id := strings.TrimPrefix(aws.ToString(zone.Id), "/hostedzone/")
+	return filepath.Join(dir, fmt.Sprintf("%s_%s.json", name, id)), nil
+}
+
+func readParkSnapshot(path string) (*parkSnapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s := &parkSnapshot{}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+func writeParkSnapshot(path string, s *parkSnapshot) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o600)
+}
+
+// newParkSnapshot records the sets in records that changes overwrite. When
+// the zone is parked again, previous keeps the sets from before the first
+// park, and parking sets the new changes leave in place are kept too.
+func newParkSnapshot(zone rtypes.HostedZone, records []rtypes.ResourceRecordSet, changes []rtypes.Change, previous *parkSnapshot) *parkSnapshot {
+	s := &parkSnapshot{
+		Zone:     aws.ToString(zone.Name),
+		ZoneID:   aws.ToString(zone.Id),
+		ParkedAt: time.Now().UTC(),
+		Replaced: []dns.ExportedRecordSet{},
+		Parking:  []dns.ExportedRecordSet{},
+	}
+	// Sets already parked hold parking records, not the originals.
+	replaced := map[string]bool{}
+	if previous != nil {
+		s.Replaced = append(s.Replaced, previous.Replaced...)
+		for _, e := range previous.Parking {
+			replaced[parkKey(e.Name, e.Type)] = true
+		}
+	}
+
+	written := map[string]bool{}
+	for _, c := range changes {
+		rs := *c.ResourceRecordSet
+		key := parkKey(aws.ToString(rs.Name), string(rs.Type))
+		written[key] = true
+		s.Parking = append(s.Parking, dns.NewExportedRecordSet(rs))
+		if replaced[key] {
+			continue
+		}
+		replaced[key] = true
+		for _, r := range records {
+			if parkKey(aws.ToString(r.Name), string(r.Type)) == key {
+				s.Replaced = append(s.Replaced, dns.NewExportedRecordSet(r))
+			}
+		}
+	}
+	if previous != nil {
+		for _, e := range previous.Parking {
+			if !written[parkKey(e.Name, e.Type)] {
+				s.Parking = append(s.Parking, e)
+			}
+		}
+	}
+	return s
+}
+
+// unparkChanges works out how to turn the parking sets of s back into the
+// sets they replaced. Sets changed since the zone was parked are left alone
+// and returned in skipped; sets already restored need no change.
+func unparkChanges(s *parkSnapshot, records []rtypes.ResourceRecordSet) (changes []rtypes.Change, skipped []string) {
+	current := map[string][]rtypes.ResourceRecordSet{}
+	for _, rs := range records {
+		key := parkKey(aws.ToString(rs.Name), string(rs.Type))
+		current[key] = append(current[key], rs)
+	}
+	originals := map[string][]rtypes.ResourceRecordSet{}
+	for _, e := range s.Replaced {
+		key := parkKey(e.Name, e.Type)
+		originals[key] = append(originals[key], e.RecordSet())
+	}
+
+	deletes, restores := []rtypes.Change{}, []rtypes.Change{}
+	for _, p := range s.Parking {
+		key := parkKey(p.Name, p.Type)
+		cur, orig := current[key], originals[key]
+		if sameRecordSets(cur, orig) {
+			continue
+		}
+		if !sameRecordSets(cur, []rtypes.ResourceRecordSet{p.RecordSet()}) {
+			skipped = append(skipped, fmt.Sprintf("%s %s", p.Name, p.Type))
+			continue
+		}
+		if len(orig) == 1 && orig[0].SetIdentifier == nil {
+			restores = append(restores, rtypes.Change{Action: rtypes.ChangeActionUpsert, ResourceRecordSet: &orig[0]})
+			continue
+		}
+		deletes = append(deletes, rtypes.Change{Action: rtypes.ChangeActionDelete, ResourceRecordSet: &cur[0]})
+		for i := range orig {
+			restores = append(restores, rtypes.Change{Action: rtypes.ChangeActionCreate, ResourceRecordSet: &orig[i]})
+		}
+	}
+	// Deletes go first so routed originals can replace a simple parking set
+	// in the same batch.
+	return append(deletes, restores...), skipped
+}
+
+// parkKey identifies the sets park writes by name and type; park never
+// writes routed sets, so set identifiers are not part of it.
+func parkKey(name, rtype string) string {
+	name = strings.ReplaceAll(name, `\052`, "*")
+	return strings.ToLower(dns.NormalizeDomain(name)) + " " + rtype
+}
+
+func sameRecordSets(a, b []rtypes.ResourceRecordSet) bool {
+	values := func(sets []rtypes.ResourceRecordSet) []string {
+		out := []string{}
+		for _, rs := range sets {
+			out = append(out, aws.ToString(rs.SetIdentifier)+" "+dns.RecordSetValue(rs))
+		}
+		sort.Strings(out)
+		return out
+	}
+	return slices.Equal(values(a), values(b))
+}
@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	rtypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewParkSnapshot_ParkAgainKeepsOriginals(t *testing.T) {
+	zone := rtypes.HostedZone{Name: aws.String("example.com."), Id: aws.String("/hostedzone/Z1")}
+	a := &parkApp{IPSv4: []rtypes.ResourceRecord{{Value: aws.String("192.0.2.10")}}}
+	changes, err := a.createChanges("example.com.")
+	require.NoError(t, err)
+
+	original := rtypes.ResourceRecordSet{Name: aws.String("example.com."), Type: rtypes.RRTypeA, TTL: aws.Int64(300),
+		ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String("192.0.2.1")}}}
+	first := newParkSnapshot(zone, []rtypes.ResourceRecordSet{original}, changes, nil)
+	require.Len(t, first.Replaced, 1)
+	require.Len(t, first.Parking, 2)
+
+	// The second park sees the first park's records, which are not the
+	// originals, and writes only the apex A.
+	parkedA := *changes[1].ResourceRecordSet
+	second := newParkSnapshot(zone, []rtypes.ResourceRecordSet{parkedA, *changes[0].ResourceRecordSet}, changes[1:], first)
+	require.Equal(t, first.Replaced, second.Replaced)
+	require.ElementsMatch(t, first.Parking, second.Parking, "the www CNAME of the first park is still there to remove")
+
+	restore, skipped := unparkChanges(second, []rtypes.ResourceRecordSet{parkedA, *changes[0].ResourceRecordSet})
+	require.Empty(t, skipped)
+	require.Len(t, restore, 2)
+	require.Equal(t, rtypes.ChangeActionDelete, restore[0].Action)
+	require.Equal(t, rtypes.RRTypeCname, restore[0].ResourceRecordSet.Type)
+	require.Equal(t, rtypes.ChangeActionUpsert, restore[1].Action)
+	require.Equal(t, "192.0.2.1", aws.ToString(restore[1].ResourceRecordSet.ResourceRecords[0].Value))
+}
@@ -0,0 +1,235 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	rtypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/pedrokiefer/route53copy/pkg/dns"
+	"github.com/spf13/cobra"
+)
+
+type parkedApp struct {
+	Profile string
+	// DomainsProfile is the account the domains are registered in, when it
+	// is not the one holding the zones.
+	DomainsProfile string
+	Output         string
+
+	Zones zoneSelector
+
+	routeManager  RouteManagerAPI
+	domainManager DomainManagerAPI
+}
+
+// parkedZoneReport is a zone tagged parked=true. Resolves says whether live
+// DNS answers the apex with any address of the parking target; registrar
+// fields are empty when the domain is not registered in the account.
+type parkedZoneReport struct {
+	Zone       string     `json:"zone" yaml:"zone"`
+	ZoneID     string     `json:"zone_id" yaml:"zone_id"`
+	Target     string     `json:"target" yaml:"target"`
+	Resolves   bool       `json:"resolves" yaml:"resolves"`
+	Registered bool       `json:"registered" yaml:"registered"`
+	AutoRenew  bool       `json:"auto_renew" yaml:"auto_renew"`
+	Expiry     *time.Time `json:"expiry,omitempty" yaml:"expiry,omitempty"`
+	Detail     string     `json:"detail,omitempty" yaml:"detail,omitempty"`
+}
+
+func (parkedZoneReport) columns() []string {
+	return []string{"Zone", "Zone ID", "Target", "Resolves", "Auto-renew", "Expiry", "Detail"}
+}
+
+func (r parkedZoneReport) row() []string {
+	autoRenew, expiry := "-", "-"
+	if r.Registered {
+		autoRenew = yesNo(r.AutoRenew)
+		if r.Expiry != nil {
+			expiry = r.Expiry.Format(time.DateOnly)
+		}
+	}
+	return []string{r.Zone, r.ZoneID, r.Target, yesNo(r.Resolves), autoRenew, expiry, r.Detail}
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+func init() {
+	rootCmd.AddCommand(newParkedCommand())
+}
+
+func (a *parkedApp) Run(ctx context.Context) error {
+	if a.Output == "" {
+		a.Output = outputTable
+	}
+	if err := validateOutputFormat(a.Output); err != nil {
+		return err
+	}
+	var err error
+	a.routeManager, err = newRouteManager(ctx, awsOptions(a.Profile), &dns.RouteManagerOptions{
+		NoWait: noWait,
+	})
+	if err != nil {
+		return err
+	}
+	domainsProfile := a.DomainsProfile
+	if domainsProfile == "" {
+		domainsProfile = a.Profile
+	}
+	a.domainManager, err = newDomainManager(ctx, awsOptions(domainsProfile))
+	if err != nil {
+		return err
+	}
+
+	zones, err := a.Zones.Select(ctx, a.routeManager)
+	if err != nil {
+		return err
+	}
+	// registered stays nil when the registrar cannot be asked.
+	var registered map[string]bool
+	domains, err := a.domainManager.ListRegisteredDomains(ctx)
+	if err != nil {
+		log.Printf("Cannot list the domains registered in %s, leaving registrar details out: %s\n", domainsProfile, err)
+	} else {
+		registered = map[string]bool{}
+		for _, d := range domains {
+			registered[strings.ToLower(dns.DenormalizeDomain(d))] = true
+		}
+	}
+
+	results := []parkedZoneReport{}
+	for _, zone := range zones {
+		tags, err := a.routeManager.GetZoneTags(ctx, aws.ToString(zone.Id))
+		if err != nil {
+			return err
+		}
+		if !hasParkedTag(tags) {
+			continue
+		}
+		results = append(results, a.report(ctx, zone, registered))
+	}
+	log.Printf("Found %d parked zones in %s\n", len(results), a.Profile)
+
+	return writeResults(stdout, a.Output, results)
+}
+
+func (a *parkedApp) report(ctx context.Context, zone rtypes.HostedZone, registered map[string]bool) parkedZoneReport {
+	name := aws.ToString(zone.Name)
+	r := parkedZoneReport{Zone: dns.DenormalizeDomain(name), ZoneID: aws.ToString(zone.Id)}
+	details := []string{}
+
+	if err := a.checkTarget(ctx, zone, &r); err != nil {
+		details = append(details, err.Error())
+	}
+
+	domain := strings.ToLower(r.Zone)
+	switch {
+	case registered == nil:
+	case registered[domain]:
+		detail, err := a.domainManager.GetDomainDetail(ctx, domain)
+		if err != nil {
+			details = append(details, err.Error())
+		} else {
+			r.Registered = true
+			r.AutoRenew = detail.AutoRenew
+			if !detail.Expiry.IsZero() {
+				r.Expiry = &detail.Expiry
+			}
+		}
+	default:
+		details = append(details, "not registered in this account")
+	}
+
+	r.Detail = strings.Join(details, "; ")
+	return r
+}
+
+// checkTarget fills in the parking target from the apex A and AAAA sets and
+// whether live DNS answers with it.
+func (a *parkedApp) checkTarget(ctx context.Context, zone rtypes.HostedZone, r *parkedZoneReport) error {
+	name := aws.ToString(zone.Name)
+	records, err := a.routeManager.GetResourceRecords(ctx, aws.ToString(zone.Id))
+	if err != nil {
+		return err
+	}
+	apex := []rtypes.ResourceRecordSet{}
+	targets := []string{}
+	for _, rs := range records {
+		if !strings.EqualFold(aws.ToString(rs.Name), name) || (rs.Type != rtypes.RRTypeA && rs.Type != rtypes.RRTypeAaaa) {
+			continue
+		}
+		apex = append(apex, rs)
+		if rs.AliasTarget != nil {
+			targets = append(targets, "alias:"+dns.NormalizeDomain(aws.ToString(rs.AliasTarget.DNSName)))
+		} else {
+			targets = append(targets, resourceRecordsToString(rs.ResourceRecords))
+		}
+	}
+	if len(apex) == 0 {
+		return errors.New("no apex A or AAAA records")
+	}
+	r.Target = strings.Join(slices.Compact(targets), ", ")
+
+	// Aliases point at addresses that change, like CloudFront's, so what the
+	// target answers now is compared with live DNS rather than the alias.
+	expected, err := dns.ResolveAliases(ctx, name, apex, dns.AliasFlatten, lookupAlias)
+	if err != nil {
+		return err
+	}
+	answers := []string{}
+	for _, rs := range expected {
+		values, _, err := lookupLive(ctx, name, string(rs.Type))
+		if err != nil {
+			return fmt.Errorf("%s %s: %w", name, rs.Type, err)
+		}
+		answers = append(answers, values...)
+		live := []string{}
+		for _, v := range values {
+			live = append(live, dns.CanonicalValue(rs.Type, v))
+		}
+		for _, v := range rs.ResourceRecords {
+			if slices.Contains(live, dns.CanonicalValue(rs.Type, aws.ToString(v.Value))) {
+				r.Resolves = true
+			}
+		}
+	}
+	if !r.Resolves {
+		if len(answers) == 0 {
+			return fmt.Errorf("live DNS has no answer for %s", name)
+		}
+		return fmt.Errorf("live DNS answers %s", strings.Join(answers, ", "))
+	}
+	return nil
+}
+
+func newParkedCommand() *cobra.Command {
+	a := parkedApp{Zones: zoneSelector{defaultAll: true}}
+
+	c := &cobra.Command{
+		Use:   "parked <profile>",
+		Short: "List parked zones with their target, whether live DNS resolves to it, and the domain's renewal",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			a.Profile = args[0]
+			return a.Run(cmd.Context())
+		},
+		SilenceErrors: true,
+		SilenceUsage:  true,
+	}
+
+	f := c.Flags()
+	f.StringVar(&a.DomainsProfile, "domains-profile", "", "Profile of the account the domains are registered in (default: <profile>)")
+	f.StringVarP(&a.Output, "output", "o", outputTable, "Output format: table, json, yaml or csv")
+	a.Zones.addFlags(c)
+	return c
+}
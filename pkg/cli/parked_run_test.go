@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	rtypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/pedrokiefer/route53copy/pkg/dns"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParked_Run_ReportsParkedZones(t *testing.T) {
+	srv := fakeAccounts(t, "p")["p"]
+	aliasID := srv.AddZone("alias.com")
+	require.NoError(t, srv.AddRecordSets(aliasID, rtypes.ResourceRecordSet{Name: aws.String("alias.com."), Type: rtypes.RRTypeA,
+		AliasTarget: &rtypes.AliasTarget{HostedZoneId: aws.String("Z2FDTNDATAQYW2"), DNSName: aws.String("d111.cloudfront.net.")}}))
+	require.NoError(t, srv.SetTags(aliasID, map[string]string{"parked": "true"}))
+	movedID := srv.AddZone("moved.com")
+	require.NoError(t, srv.AddRecordSets(movedID, rtypes.ResourceRecordSet{Name: aws.String("moved.com."), Type: rtypes.RRTypeA,
+		TTL: aws.Int64(3600), ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String("192.0.2.10")}}}))
+	require.NoError(t, srv.SetTags(movedID, map[string]string{"parked": "TRUE"}))
+	inUseID := srv.AddZone("in-use.com")
+	require.NoError(t, srv.AddRecordSets(inUseID, rtypes.ResourceRecordSet{Name: aws.String("in-use.com."), Type: rtypes.RRTypeA,
+		TTL: aws.Int64(300), ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String("192.0.2.20")}}}))
+
+	expiry := time.Date(2027, 3, 1, 0, 0, 0, 0, time.UTC)
+	dm := &fakeDomainManager{
+		Domains: []string{"alias.com", "in-use.com"},
+		Details: map[string]*dns.DomainDetail{"alias.com": {AutoRenew: true, Expiry: expiry}},
+	}
+	oldNewDM, oldAlias, oldLive, oldOut := newDomainManager, lookupAlias, lookupLive, stdout
+	t.Cleanup(func() { newDomainManager, lookupAlias, lookupLive, stdout = oldNewDM, oldAlias, oldLive, oldOut })
+	newDomainManager = func(ctx context.Context, o dns.AWSOptions) (DomainManagerAPI, error) { return dm, nil }
+	lookupAlias = func(ctx context.Context, name, rtype string) ([]string, uint32, error) {
+		return []string{"198.51.100.1", "198.51.100.2"}, 60, nil
+	}
+	lookupLive = func(ctx context.Context, name, rtype string) ([]string, uint32, error) {
+		if name == "alias.com." {
+			return []string{"198.51.100.2", "198.51.100.3"}, 60, nil
+		}
+		return []string{"203.0.113.5"}, 300, nil
+	}
+	var buf bytes.Buffer
+	stdout = &buf
+
+	a := &parkedApp{Profile: "p", Output: outputJSON, Zones: zoneSelector{defaultAll: true}}
+	require.NoError(t, a.Run(context.Background()))
+
+	var results []parkedZoneReport
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &results))
+	require.Equal(t, []parkedZoneReport{
+		{Zone: "alias.com", ZoneID: aliasID, Target: "alias:d111.cloudfront.net.", Resolves: true,
+			Registered: true, AutoRenew: true, Expiry: &expiry},
+		{Zone: "moved.com", ZoneID: movedID, Target: "192.0.2.10",
+			Detail: "live DNS answers 203.0.113.5; not registered in this account"},
+	}, results)
+
+	require.Equal(t, []string{"moved.com", movedID, "192.0.2.10", "no", "-", "-", results[1].Detail}, results[1].row())
+	require.Equal(t, []string{"alias.com", aliasID, "alias:d111.cloudfront.net.", "yes", "yes", "2027-03-01", ""}, results[0].row())
+}
+
+func TestParked_Run_ComparesCanonicalValues(t *testing.T) {
+	srv := fakeAccounts(t, "p")["p"]
+	zoneID := srv.AddZone("v6.com")
+	require.NoError(t, srv.AddRecordSets(zoneID, rtypes.ResourceRecordSet{Name: aws.String("v6.com."), Type: rtypes.RRTypeAaaa,
+		TTL: aws.Int64(3600), ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String("2001:DB8:0::1")}}}))
+	require.NoError(t, srv.SetTags(zoneID, map[string]string{"parked": "true"}))
+
+	oldNewDM, oldLive, oldOut := newDomainManager, lookupLive, stdout
+	t.Cleanup(func() { newDomainManager, lookupLive, stdout = oldNewDM, oldLive, oldOut })
+	newDomainManager = func(ctx context.Context, o dns.AWSOptions) (DomainManagerAPI, error) {
+		return &fakeDomainManager{Domains: []string{}}, nil
+	}
+	lookupLive = func(ctx context.Context, name, rtype string) ([]string, uint32, error) {
+		return []string{"2001:db8::1"}, 3600, nil
+	}
+	var buf bytes.Buffer
+	stdout = &buf
+
+	a := &parkedApp{Profile: "p", Output: outputJSON, Zones: zoneSelector{defaultAll: true}}
+	require.NoError(t, a.Run(context.Background()))
+
+	var results []parkedZoneReport
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &results))
+	require.Len(t, results, 1)
+	require.True(t, results[0].Resolves, "2001:DB8:0::1 and 2001:db8::1 are the same address")
+}
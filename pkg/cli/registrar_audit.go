@@ -0,0 +1,229 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/olekukonko/tablewriter"
+	"github.com/pedrokiefer/route53copy/pkg/dns"
+	"github.com/spf13/cobra"
+)
+
+// registrarStatus classifies where a registered domain's nameservers point.
+type registrarStatus string
+
+const (
+	registrarOK registrarStatus = "ok"
+	// registrarMismatch: the zone exists in this account, but the registrar
+	// points at Route53 servers that do not serve it. --fix repairs these.
+	registrarMismatch registrarStatus = "mismatch"
+	// registrarNoZone: no zone in this account and the Route53 servers the
+	// registrar points at refuse the domain; anyone can claim it.
+	registrarNoZone      registrarStatus = "no_zone"
+	registrarOtherAcct   registrarStatus = "other_account"
+	registrarExternal    registrarStatus = "external"
+	registrarAuditFailed registrarStatus = "error"
+)
+
+type registrarAuditResult struct {
+	Domain      string
+	ZoneID      string
+	Status      registrarStatus
+	RegistrarNS []string
+	ZoneNS      []string
+	Detail      string
+	Fixed       bool
+}
+
+type registrarAuditApp struct {
+	Profile string
+	Fix     bool
+
+	routeManager  RouteManagerAPI
+	domainManager DomainManagerAPI
+}
+
+func init() {
+	rootCmd.AddCommand(newRegistrarAuditCmd())
+}
+
+func (a *registrarAuditApp) Run(ctx context.Context) error {
+	var err error
+	a.domainManager, err = newDomainManager(ctx, awsOptions(a.Profile))
+	if err != nil {
+		return err
+	}
+	a.routeManager, err = newRouteManager(ctx, awsOptions(a.Profile), &dns.RouteManagerOptions{
+		NoWait: noWait,
+	})
+	if err != nil {
+		return err
+	}
+
+	domains, err := a.domainManager.ListRegisteredDomains(ctx)
+	if err != nil {
+		return err
+	}
+	log.Printf("Auditing nameservers of %d registered domains\n", len(domains))
+
+	results := []registrarAuditResult{}
+	for _, domain := range domains {
+		r := a.auditDomain(ctx, domain)
+		if a.Fix && r.Status == registrarMismatch {
+			if err := a.fixDomain(ctx, &r); err != nil {
+				return err
+			}
+		}
+		results = append(results, r)
+	}
+
+	printRegistrarAudit(stdout, results)
+	return nil
+}
+
+func (a *registrarAuditApp) auditDomain(ctx context.Context, domain string) registrarAuditResult {
+	r := registrarAuditResult{Domain: dns.DenormalizeDomain(domain)}
+
+	detail, err := a.domainManager.GetDomainDetail(ctx, domain)
+	if err != nil {
+		r.Status, r.Detail = registrarAuditFailed, err.Error()
+		return r
+	}
+	r.RegistrarNS = detail.Nameservers
+
+	zone, err := a.routeManager.GetHostedZone(ctx, domain)
+	var notFound *dns.HostedZoneNotFound
+	switch {
+	case errors.As(err, &notFound):
+	case err != nil:
+		r.Status, r.Detail = registrarAuditFailed, err.Error()
+		return r
+	default:
+		r.ZoneID = aws.ToString(zone.Id)
+		nsRecords, err := a.routeManager.GetNSRecords(ctx, r.ZoneID)
+		if err != nil {
+			r.Status, r.Detail = registrarAuditFailed, err.Error()
+			return r
+		}
+		for _, rr := range nsRecords.ResourceRecords {
+			r.ZoneNS = append(r.ZoneNS, strings.ToLower(dns.DenormalizeDomain(aws.ToString(rr.Value))))
+		}
+		if dns.MatchNSRecords(r.RegistrarNS, nsRecords) {
+			r.Status = registrarOK
+			return r
+		}
+	}
+
+	if !allRoute53Nameservers(r.RegistrarNS) {
+		r.Status = registrarExternal
+		return r
+	}
+
+	// The registrar points at Route53 but not at this account's zone. Ask
+	// those servers directly: a zone elsewhere answers authoritatively, a
+	// deleted one is refused.
+	if server, ok := servingNameserver(ctx, domain, r.RegistrarNS); ok {
+		r.Status = registrarOtherAcct
+		r.Detail = fmt.Sprintf("%s answers authoritatively", server)
+		return r
+	}
+	if r.ZoneID != "" {
+		r.Status = registrarMismatch
+		r.Detail = "registrar nameservers do not serve the zone"
+		return r
+	}
+	r.Status = registrarNoZone
+	r.Detail = "registrar nameservers do not serve the domain"
+	return r
+}
+
+// fixDomain points the registrar at the zone's nameservers once confirmed.
+// It only returns an error when no confirmation can be had, so the run
+// stops before changing any other domain.
+func (a *registrarAuditApp) fixDomain(ctx context.Context, r *registrarAuditResult) error {
+	if dryRun {
+		log.Printf("Dry run... would update %s registrar nameservers to %s\n", r.Domain, strings.Join(r.ZoneNS, ","))
+		return nil
+	}
+	ok, err := confirmation().Confirm(fmt.Sprintf("Point the registrar of %s at %s?", r.Domain, strings.Join(r.ZoneNS, ",")))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		r.Detail = "fix declined"
+		return nil
+	}
+	updated, err := a.routeManager.UpdateNSRecords(ctx, r.Domain, r.ZoneID)
+	if err != nil {
+		r.Detail = fmt.Sprintf("fix failed: %s", err)
+		return nil
+	}
+	r.Fixed = updated
+	return nil
+}
+
+func allRoute53Nameservers(ns []string) bool {
+	if len(ns) == 0 {
+		return false
+	}
+	for _, n := range ns {
+		if !dns.IsRoute53Nameserver(n) {
+			return false
+		}
+	}
+	return true
+}
+
+func servingNameserver(ctx context.Context, domain string, ns []string) (string, bool) {
+	for _, n := range ns {
+		if check := checkNameserver(ctx, domain, n); check.Authoritative {
+			return n, true
+		}
+	}
+	return "", false
+}
+
+func printRegistrarAudit(w io.Writer, results []registrarAuditResult) {
+	table := tablewriter.NewWriter(w)
+	table.Header([]string{"Domain", "Zone", "Status", "Registrar NS", "Zone NS", "Detail"})
+	for _, r := range results {
+		status := string(r.Status)
+		if r.Fixed {
+			status += " (fixed)"
+		}
+		_ = table.Append([]string{
+			r.Domain,
+			r.ZoneID,
+			status,
+			strings.Join(r.RegistrarNS, "\n"),
+			strings.Join(r.ZoneNS, "\n"),
+			r.Detail,
+		})
+	}
+	_ = table.Render()
+}
+
+func newRegistrarAuditCmd() *cobra.Command {
+	a := registrarAuditApp{}
+
+	c := &cobra.Command{
+		Use:   "registrar-audit [--fix] profile",
+		Short: "Compare the registrar nameservers of every registered domain with its hosted zone",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			a.Profile = args[0]
+			return a.Run(cmd.Context())
+		},
+		SilenceErrors: true,
+		SilenceUsage:  true,
+	}
+	f := c.Flags()
+	f.BoolVar(&a.Fix, "fix", false, "Point the registrar at the hosted zone nameservers when they do not serve the zone")
+
+	return c
+}
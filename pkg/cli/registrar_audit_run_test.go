@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	rtypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/manifoldco/promptui"
+	"github.com/pedrokiefer/route53copy/pkg/dig"
+	"github.com/pedrokiefer/route53copy/pkg/dns"
+	"github.com/stretchr/testify/require"
+)
+
+func nsSet(values ...string) rtypes.ResourceRecordSet {
+	rs := rtypes.ResourceRecordSet{Type: rtypes.RRTypeNs}
+	for _, v := range values {
+		rs.ResourceRecords = append(rs.ResourceRecords, rtypes.ResourceRecord{Value: aws.String(v)})
+	}
+	return rs
+}
+
+func TestRegistrarAudit_ClassifiesDomains(t *testing.T) {
+	oldNewRM, oldNewDM, oldCheck := newRouteManager, newDomainManager, checkNameserver
+	t.Cleanup(func() { newRouteManager = oldNewRM; newDomainManager = oldNewDM; checkNameserver = oldCheck })
+
+	rm := &fakeRouteManager{
+		ZonesByName: map[string]rtypes.HostedZone{
+			"ok.com.":       {Id: aws.String("/hostedzone/Z1"), Name: aws.String("ok.com.")},
+			"stale.com.":    {Id: aws.String("/hostedzone/Z2"), Name: aws.String("stale.com.")},
+			"external.com.": {Id: aws.String("/hostedzone/Z3"), Name: aws.String("external.com.")},
+		},
+		NSByID: map[string]rtypes.ResourceRecordSet{
+			"/hostedzone/Z1": nsSet("ns-1.awsdns-01.com."),
+			"/hostedzone/Z2": nsSet("ns-2.awsdns-02.com."),
+			"/hostedzone/Z3": nsSet("ns-3.awsdns-03.com."),
+		},
+	}
+	dm := &fakeDomainManager{
+		Domains: []string{"ok.com", "stale.com", "external.com", "elsewhere.com", "dangling.com"},
+		Details: map[string]*dns.DomainDetail{
+			"ok.com":        {Nameservers: []string{"ns-1.awsdns-01.com"}},
+			"stale.com":     {Nameservers: []string{"ns-9.awsdns-09.com"}},
+			"external.com":  {Nameservers: []string{"ns1.example.net"}},
+			"elsewhere.com": {Nameservers: []string{"ns-7.awsdns-07.com"}},
+			"dangling.com":  {Nameservers: []string{"ns-8.awsdns-08.com"}},
+		},
+	}
+	newRouteManager = func(ctx context.Context, o dns.AWSOptions, rmo *dns.RouteManagerOptions) (RouteManagerAPI, error) {
+		return rm, nil
+	}
+	newDomainManager = func(ctx context.Context, o dns.AWSOptions) (DomainManagerAPI, error) { return dm, nil }
+	checkNameserver = func(ctx context.Context, zone, ns string) dig.NameserverCheck {
+		return dig.NameserverCheck{Name: ns, Authoritative: zone == "elsewhere.com"}
+	}
+
+	a := &registrarAuditApp{Profile: "p", domainManager: dm, routeManager: rm}
+	got := map[string]registrarStatus{}
+	for _, d := range dm.Domains {
+		got[d] = a.auditDomain(context.Background(), d).Status
+	}
+	require.Equal(t, map[string]registrarStatus{
+		"ok.com":        registrarOK,
+		"stale.com":     registrarMismatch,
+		"external.com":  registrarExternal,
+		"elsewhere.com": registrarOtherAcct,
+		"dangling.com":  registrarNoZone,
+	}, got)
+
+	// Without a terminal nothing is fixed unless --yes is given.
+	stubPrompt(t, false, "y", nil)
+	a = &registrarAuditApp{Profile: "p", Fix: true}
+	require.ErrorIs(t, a.Run(context.Background()), errConfirmationNeeded)
+	require.Empty(t, rm.UpdatedNSDomains)
+
+	stubPrompt(t, true, "", promptui.ErrAbort)
+	require.NoError(t, a.Run(context.Background()))
+	require.Empty(t, rm.UpdatedNSDomains, "declined fixes are not applied")
+
+	prompts := stubPrompt(t, true, "y", nil)
+	require.NoError(t, a.Run(context.Background()))
+	require.Equal(t, []string{"stale.com"}, rm.UpdatedNSDomains)
+	require.Equal(t, 1, *prompts)
+}
+
+func TestRegistrarAudit_DryRunDoesNotFix(t *testing.T) {
+	oldNewRM, oldNewDM, oldCheck, oldDry := newRouteManager, newDomainManager, checkNameserver, dryRun
+	t.Cleanup(func() {
+		newRouteManager = oldNewRM
+		newDomainManager = oldNewDM
+		checkNameserver = oldCheck
+		dryRun = oldDry
+	})
+
+	rm := &fakeRouteManager{
+		ZonesByName: map[string]rtypes.HostedZone{
+			"stale.com.": {Id: aws.String("/hostedzone/Z2"), Name: aws.String("stale.com.")},
+		},
+		NSByID: map[string]rtypes.ResourceRecordSet{"/hostedzone/Z2": nsSet("ns-2.awsdns-02.com.")},
+	}
+	dm := &fakeDomainManager{
+		Domains: []string{"stale.com"},
+		Details: map[string]*dns.DomainDetail{"stale.com": {Nameservers: []string{"ns-9.awsdns-09.com"}}},
+	}
+	newRouteManager = func(ctx context.Context, o dns.AWSOptions, rmo *dns.RouteManagerOptions) (RouteManagerAPI, error) {
+		return rm, nil
+	}
+	newDomainManager = func(ctx context.Context, o dns.AWSOptions) (DomainManagerAPI, error) { return dm, nil }
+	checkNameserver = func(ctx context.Context, zone, ns string) dig.NameserverCheck { return dig.NameserverCheck{Name: ns} }
+	dryRun = true
+
+	a := &registrarAuditApp{Profile: "p", Fix: true}
+	require.NoError(t, a.Run(context.Background()))
+	require.Empty(t, rm.UpdatedNSDomains)
+}
+
+func TestPrintRegistrarAudit(t *testing.T) {
+	var buf bytes.Buffer
+	printRegistrarAudit(&buf, []registrarAuditResult{
+		{Domain: "stale.com", ZoneID: "/hostedzone/Z2", Status: registrarMismatch, Fixed: true},
+	})
+	require.Contains(t, buf.String(), "mismatch (fixed)")
+}
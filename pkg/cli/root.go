@@ -1,11 +1,27 @@
 package cli
 
-import "github.com/spf13/cobra"
+import (
+	"time"
+
+	"github.com/pedrokiefer/route53copy/pkg/dig"
+	"github.com/pedrokiefer/route53copy/pkg/dns"
+	"github.com/spf13/cobra"
+)
 
 var (
 	// flags
-	dryRun bool
-	noWait bool
+	dryRun    bool
+	noWait    bool
+	assumeYes bool
+	noInput   bool
+	region    string
+
+	endpointURL string
+	endpoints   map[string]string
+
+	resolvers       []string
+	resolverTimeout time.Duration
+	resolverRetries int
 
 	rootCmd = newRootCmd()
 )
@@ -16,9 +32,35 @@ func newRootCmd() *cobra.Command {
 		Short:         "r53tool is a swiss army knife for Route53",
 		SilenceErrors: true,
 		SilenceUsage:  true,
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			dig.Configure(dig.Config{
+				Servers: resolvers,
+				Timeout: resolverTimeout,
+				Retries: resolverRetries,
+			})
+		},
 	}
 	f := c.PersistentFlags()
 	f.BoolVar(&dryRun, "dry", false, "Dry run")
 	f.BoolVar(&noWait, "no-wait", false, "Don't wait for changes to propagate")
+	f.BoolVarP(&assumeYes, "yes", "y", false, "Answer yes to every confirmation")
+	f.BoolVar(&noInput, "no-input", false, "Never prompt; fail where a confirmation is needed unless --yes is set")
+	f.StringVar(&endpointURL, "endpoint-url", "", "Send AWS requests to this endpoint, e.g. a local Route53 emulator")
+	f.StringToStringVar(&endpoints, "endpoint", nil, "Endpoint for a single service as service=url (route53, route53domains, sts, organizations); may be repeated")
+	f.StringVar(&region, "region", "", "AWS region (default: from the environment or profile, else "+dns.DefaultRegion+")")
+	f.StringSliceVar(&resolvers, "resolver", nil, "DNS resolvers to query in failover order: host[:port], tcp://, tls:// or https:// DoH URL (default: /etc/resolv.conf)")
+	f.DurationVar(&resolverTimeout, "resolver-timeout", 5*time.Second, "Timeout for a single DNS query")
+	f.IntVar(&resolverRetries, "resolver-retries", 3, "Attempts per DNS resolver before failing over")
 	return c
 }
+
+// awsOptions returns how to reach AWS with the given profile, honouring the
+// global flags.
+func awsOptions(profile string) dns.AWSOptions {
+	return dns.AWSOptions{
+		Profile:     profile,
+		Region:      region,
+		EndpointURL: endpointURL,
+		Endpoints:   endpoints,
+	}
+}
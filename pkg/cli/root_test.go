@@ -2,7 +2,9 @@ package cli
 
 import (
 	"testing"
+	"time"
 
+	"github.com/pedrokiefer/route53copy/pkg/dig"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/require"
 )
@@ -15,3 +17,17 @@ func TestRootCmdInit(t *testing.T) {
 	require.NotNil(t, f.Lookup("dry"))
 	require.NotNil(t, f.Lookup("no-wait"))
 }
+
+func TestRootCmd_ConfiguresResolver(t *testing.T) {
+	t.Cleanup(func() { dig.Configure(dig.DefaultConfig()); resolvers = nil; resolverTimeout = 5 * time.Second })
+
+	c := newRootCmd()
+	c.AddCommand(&cobra.Command{Use: "noop", Run: func(cmd *cobra.Command, args []string) {}})
+	_, err := runCmd(c, []string{"--resolver", "10.0.0.2,10.0.0.3:5353", "--resolver-timeout", "2s", "noop"})
+	require.NoError(t, err)
+
+	cfg := dig.CurrentConfig()
+	require.Equal(t, []string{"10.0.0.2", "10.0.0.3:5353"}, cfg.Servers)
+	require.Equal(t, 2*time.Second, cfg.Timeout)
+	require.Equal(t, 3, cfg.Retries)
+}
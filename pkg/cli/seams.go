@@ -2,10 +2,13 @@ package cli
 
 import (
 	"context"
+	"io"
+	"os"
 	"time"
 
 	rtypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
 	"github.com/manifoldco/promptui"
+	"github.com/mattn/go-isatty"
 	"github.com/pedrokiefer/route53copy/pkg/dig"
 	"github.com/pedrokiefer/route53copy/pkg/dns"
 )
@@ -14,34 +17,103 @@ import (
 // Tests can implement this interface to stub AWS interactions.
 type RouteManagerAPI interface {
 	GetHostedZone(ctx context.Context, domain string) (rtypes.HostedZone, error)
+	FindHostedZone(ctx context.Context, domain string, f dns.ZoneFilter) (rtypes.HostedZone, error)
+	GetZoneVPCs(ctx context.Context, zoneID string) ([]dns.VPC, error)
+	AssociateVPC(ctx context.Context, zoneID string, vpc dns.VPC) error
 	ListHostedZones(ctx context.Context) ([]rtypes.HostedZone, error)
 	GetResourceRecords(ctx context.Context, zoneId string) ([]rtypes.ResourceRecordSet, error)
 	GetNSRecords(ctx context.Context, zoneId string) (rtypes.ResourceRecordSet, error)
 	CreateChanges(domain string, recordSets []rtypes.ResourceRecordSet) []rtypes.Change
 	UpdateRecords(ctx context.Context, comment, zoneId string, changes []rtypes.Change) (*rtypes.ChangeInfo, error)
 	WaitForChange(ctx context.Context, changeId string, maxWait time.Duration) error
-	GetOrCreateZone(ctx context.Context, domain string) (rtypes.HostedZone, error)
+	GetOrCreateZone(ctx context.Context, domain string, o dns.CreateZoneOptions) (rtypes.HostedZone, error)
 	UpdateNSRecords(ctx context.Context, domain, zoneId string) (bool, error)
 	DeleteRecords(ctx context.Context, zoneId string, records []rtypes.ResourceRecordSet) (string, error)
 	DeleteHostedZone(ctx context.Context, zoneId string) (string, error)
 	GetZoneTags(ctx context.Context, zoneID string) ([]dns.Tag, error)
 	UpsertTags(ctx context.Context, zoneID string, tags []dns.Tag) error
+	DeleteTags(ctx context.Context, zoneID string, keys []string) error
 }
 
 // newRouteManager is a seam to allow injecting a fake RouteManager in tests.
 // By default, it constructs the real dns.RouteManager, which satisfies RouteManagerAPI.
-var newRouteManager = func(ctx context.Context, profile string, rmo *dns.RouteManagerOptions) RouteManagerAPI {
-	return dns.NewRouteManager(ctx, profile, rmo)
+var newRouteManager = func(ctx context.Context, o dns.AWSOptions, rmo *dns.RouteManagerOptions) (RouteManagerAPI, error) {
+	rm, err := dns.NewRouteManager(ctx, o, rmo)
+	if err != nil {
+		return nil, err
+	}
+	return rm, nil
 }
 
+// DomainManagerAPI declares the subset of dns.DomainManager used by the CLI.
+type DomainManagerAPI interface {
+	ListRegisteredDomains(ctx context.Context) ([]string, error)
+	GetDomainDetail(ctx context.Context, domain string) (*dns.DomainDetail, error)
+}
+
+// newDomainManager is a seam to allow injecting a fake DomainManager in tests.
+var newDomainManager = func(ctx context.Context, o dns.AWSOptions) (DomainManagerAPI, error) {
+	dm, err := dns.NewDomainManager(ctx, o)
+	if err != nil {
+		return nil, err
+	}
+	return dm, nil
+}
+
+// listOrgAccounts is a seam over dns.ListOrganizationAccounts.
+var listOrgAccounts = dns.ListOrganizationAccounts
+
+// whoAmI is a seam over dns.WhoAmI used by whoami.
+var whoAmI = dns.WhoAmI
+
 // getNameserversFor is a seam over dig.GetNameserversFor used by some CLI commands.
 var getNameserversFor = func(domain string) ([]string, error) { return dig.GetNameserversFor(domain) }
 
+// auditDelegation is a seam over dig.AuditDelegation used by check-zone.
+var auditDelegation = dig.AuditDelegation
+
+// checkNameserver is a seam over dig.CheckNameserver used by registrar-audit.
+var checkNameserver = dig.CheckNameserver
+
+// lookupAlias resolves alias targets for export --aliases flatten.
+var lookupAlias dns.AliasResolver = func(ctx context.Context, name, rtype string) ([]string, uint32, error) {
+	resp, err := dig.Resolve(ctx, name, rtype)
+	if err != nil {
+		return nil, 0, err
+	}
+	return resp.Values(), resp.MinTTL(), nil
+}
+
+// lookupLive resolves a record set in live DNS for diff. A name that does
+// not exist has no values.
+var lookupLive = func(ctx context.Context, name, rtype string) ([]string, uint32, error) {
+	resp, err := dig.Resolve(ctx, name, rtype)
+	if resp != nil && resp.NXDomain() {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	return resp.Values(), resp.MinTTL(), nil
+}
+
 // writeBindZoneFile is a seam over dns.WriteBindZoneFile used by export.
 var writeBindZoneFile = func(outputPath, zone string, records []rtypes.ResourceRecordSet) error {
 	return dns.WriteBindZoneFile(outputPath, zone, records)
 }
 
+// writeZoneFile is a seam over dns.WriteZoneFile used by export for formats other than BIND.
+var writeZoneFile = dns.WriteZoneFile
+
+// stdout is where commands write their results; tests can capture it.
+var stdout io.Writer = os.Stdout
+
+// stdinIsTerminal reports whether prompts can be answered; tests can
+// override it.
+var stdinIsTerminal = func() bool {
+	return isatty.IsTerminal(os.Stdin.Fd()) || isatty.IsCygwinTerminal(os.Stdin.Fd())
+}
+
 // promptConfirm wraps a confirm prompt; tests can override to auto-confirm.
 var promptConfirm = func(label string, isConfirm bool) (string, error) {
 	prompt := promptui.Prompt{Label: label, IsConfirm: isConfirm}
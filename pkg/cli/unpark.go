@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	rtypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/pedrokiefer/route53copy/pkg/dns"
+	"github.com/spf13/cobra"
+)
+
+type unparkApp struct {
+	Profile string
+
+	Zones zoneSelector
+	// StateDir holds the snapshots park saved.
+	StateDir string
+
+	service RouteManagerAPI
+}
+
+func init() {
+	rootCmd.AddCommand(newUnparkCommand())
+}
+
+func (a *unparkApp) Run(ctx context.Context) error {
+	var err error
+	a.service, err = newRouteManager(ctx, awsOptions(a.Profile), &dns.RouteManagerOptions{
+		NoWait: noWait,
+	})
+	if err != nil {
+		return err
+	}
+	log.Printf("Unparking domains in %s...\n", a.Profile)
+
+	zones, err := a.Zones.Select(ctx, a.service)
+	if err != nil {
+		return err
+	}
+
+	for _, zone := range zones {
+		err := a.unparkZone(ctx, zone)
+		if err != nil {
+			if len(zones) == 1 {
+				return err
+			}
+			log.Printf("error unparking zone %s: %+v", aws.ToString(zone.Name), err)
+		}
+	}
+
+	return nil
+}
+
+func (a *unparkApp) unparkZone(ctx context.Context, zone rtypes.HostedZone) error {
+	zoneID := aws.ToString(zone.Id)
+	zoneName := aws.ToString(zone.Name)
+	path, err := parkSnapshotPath(a.StateDir, zone)
+	if err != nil {
+		return err
+	}
+	snapshot, err := readParkSnapshot(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("no park snapshot for %s in %s", zoneName, a.StateDir)
+	}
+	if err != nil {
+		return err
+	}
+
+	records, err := a.service.GetResourceRecords(ctx, zoneID)
+	if err != nil {
+		return err
+	}
+	changes, skipped := unparkChanges(snapshot, records)
+	log.Printf("Unparking %s: %d record set changes\n", zoneName, len(changes))
+	if dryRun {
+		log.Printf("--dry provided; not unparking.\n")
+		return nil
+	}
+
+	if len(changes) > 0 {
+		info, err := a.service.UpdateRecords(ctx, "unparking "+zoneName, zoneID, changes)
+		if err != nil {
+			return err
+		}
+		if err := a.service.WaitForChange(ctx, aws.ToString(info.Id), 1*time.Minute); err != nil {
+			return err
+		}
+	}
+	// Keep the tag and snapshot so unpark can run again once the sets that
+	// changed are sorted out.
+	if len(skipped) > 0 {
+		return fmt.Errorf("left %s alone, changed since %s was parked", strings.Join(skipped, ", "), zoneName)
+	}
+
+	if err := a.service.DeleteTags(ctx, zoneID, []string{"parked"}); err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+	log.Printf("Unparked %s\n", zoneName)
+
+	return nil
+}
+
+func newUnparkCommand() *cobra.Command {
+	a := unparkApp{}
+
+	c := &cobra.Command{
+		Use:   "unpark <profile>",
+		Short: "Remove the parking records of zones, restore the records park replaced and clear the parked tag",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			a.Profile = args[0]
+			return a.Run(cmd.Context())
+		},
+		SilenceErrors: true,
+		SilenceUsage:  true,
+	}
+
+	f := c.Flags()
+	f.StringVar(&a.StateDir, "state-dir", defaultParkStateDir(), "Directory park saved its snapshots of replaced records in")
+	a.Zones.addFlags(c)
+	return c
+}
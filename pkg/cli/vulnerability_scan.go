@@ -19,8 +19,10 @@ import (
 
 type vulnerabilityScanApp struct {
 	Profile  string
-	Zone     string
-	AllZones bool
+	Zones    zoneSelector
+	Accounts accountSelector
+
+	ScanOptions vuln.ScanOptions
 }
 
 func init() {
@@ -32,29 +34,56 @@ var MISCONFIG = color.YellowString("[MISCONFIG]")
 var VULN = color.RedString("[VULN]")
 
 func (a *vulnerabilityScanApp) Run(ctx context.Context) error {
-	manager := dns.NewRouteManager(ctx, a.Profile, &dns.RouteManagerOptions{
-		NoWait: noWait,
-	})
+	accounts, err := a.Accounts.accounts(ctx, a.Profile)
+	if err != nil {
+		return err
+	}
 
-	zones := []rtypes.HostedZone{}
-	if a.AllZones {
-		z, err := manager.ListHostedZones(ctx)
-		if err != nil {
-			return err
-		}
-		zones = z
-		log.Printf("Scanning %d zones in %s\n", len(zones), a.Profile)
-	} else {
-		zone, err := manager.GetHostedZone(ctx, a.Zone)
-		if err != nil {
-			return err
+	records := sync.Map{}
+	for _, acct := range accounts {
+		label := accountLabel(a.Profile, acct)
+		if err := a.fetchRecords(ctx, acct, &records); err != nil {
+			if !a.Accounts.multiple() {
+				return err
+			}
+			log.Printf("Skipping account %s: %s\n", label, err)
 		}
-		log.Printf("Scaning zone %s in %s\n", aws.ToString(zone.Name), a.Profile)
-		zones = append(zones, zone)
 	}
 
+	log.Printf("Scanning records...\n")
+	findings := []*vuln.Findings{}
+	records.Range(func(k, v interface{}) bool {
+		zm := k.(vuln.ZoneMeta)
+		rs := v.([]rtypes.ResourceRecordSet)
+		f := vuln.Scan(ctx, zm, rs, a.ScanOptions)
+		findings = append(findings, f)
+		return true
+	})
+
+	err = writeReport(a.Profile, findings)
+	if err != nil {
+		log.Printf("failed to write report: %s", err)
+	}
+
+	return nil
+}
+
+// fetchRecords stores the record sets of every selected zone in the account,
+// keyed by vuln.ZoneMeta.
+func (a *vulnerabilityScanApp) fetchRecords(ctx context.Context, acct dns.Account, records *sync.Map) error {
+	manager, err := routeManagerFor(ctx, a.Profile, acct)
+	if err != nil {
+		return err
+	}
+	label := accountLabel(a.Profile, acct)
+
+	zones, err := a.Zones.Select(ctx, manager)
+	if err != nil {
+		return err
+	}
+	log.Printf("Scanning %d zones in %s\n", len(zones), label)
+
 	log.Printf("Fetching records...\n")
-	records := sync.Map{}
 	concurrentGoroutines := make(chan struct{}, 5)
 	var wg sync.WaitGroup
 	for _, z := range zones {
@@ -69,30 +98,15 @@ func (a *vulnerabilityScanApp) Run(ctx context.Context) error {
 				return
 			}
 			zm := vuln.ZoneMeta{
-				ZoneID: aws.ToString(z.Id),
-				Name:   aws.ToString(z.Name),
+				Account: label,
+				ZoneID:  aws.ToString(z.Id),
+				Name:    aws.ToString(z.Name),
 			}
 			records.Store(zm, rs)
 			<-concurrentGoroutines
 		}(z)
 	}
 	wg.Wait()
-
-	log.Printf("Scanning records...\n")
-	findings := []*vuln.Findings{}
-	records.Range(func(k, v interface{}) bool {
-		zm := k.(vuln.ZoneMeta)
-		rs := v.([]rtypes.ResourceRecordSet)
-		f := vuln.Scan(ctx, zm, rs)
-		findings = append(findings, f)
-		return true
-	})
-
-	err := writeReport(a.Profile, findings)
-	if err != nil {
-		log.Printf("failed to write report: %s", err)
-	}
-
 	return nil
 }
 
@@ -122,7 +136,7 @@ func newVulnerabiltyScanCommand() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			a.Profile = args[0]
 			if len(args) == 2 {
-				a.Zone = args[1]
+				a.Zones.Names = append(a.Zones.Names, args[1])
 			}
 			return a.Run(cmd.Context())
 		},
@@ -130,6 +144,10 @@ func newVulnerabiltyScanCommand() *cobra.Command {
 		SilenceUsage:  true,
 	}
 	f := c.Flags()
-	f.BoolVar(&a.AllZones, "a", false, "Scan all zones on current account")
+	a.Zones.addFlags(c)
+	a.Zones.addLegacyFlags(c, false)
+	a.Accounts.addFlags(c)
+	f.BoolVar(&a.ScanOptions.SkipTLS, "skip-tls", false, "Skip probing HTTPS endpoints")
+	f.IntVar(&a.ScanOptions.TLSExpiryDays, "tls-expiry-days", 30, "Report certificates expiring within this many days")
 	return c
 }
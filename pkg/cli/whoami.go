@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"context"
+
+	"github.com/pedrokiefer/route53copy/pkg/dns"
+	"github.com/spf13/cobra"
+)
+
+type whoamiApp struct {
+	Profile string
+	RoleARN string
+	Output  string
+}
+
+// whoamiResult is the identity the tool resolves for a profile.
+type whoamiResult struct {
+	Profile      string `json:"profile" yaml:"profile"`
+	dns.Identity `yaml:",inline"`
+}
+
+func (whoamiResult) columns() []string {
+	return []string{"Profile", "Account", "ARN", "Region"}
+}
+
+func (r whoamiResult) row() []string {
+	return []string{r.Profile, r.Account, r.ARN, r.Region}
+}
+
+func init() {
+	rootCmd.AddCommand(newWhoamiCommand())
+}
+
+func (a *whoamiApp) Run(ctx context.Context) error {
+	if a.Output == "" {
+		a.Output = outputTable
+	}
+	if err := validateOutputFormat(a.Output); err != nil {
+		return err
+	}
+
+	o := awsOptions(a.Profile)
+	o.RoleARN = a.RoleARN
+	id, err := whoAmI(ctx, o)
+	if err != nil {
+		return err
+	}
+	return writeResults(stdout, a.Output, []whoamiResult{{Profile: a.Profile, Identity: *id}})
+}
+
+func newWhoamiCommand() *cobra.Command {
+	a := &whoamiApp{}
+	c := &cobra.Command{
+		Use:   "whoami [profile]",
+		Short: "Print the AWS account, ARN and region a profile resolves to",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 1 {
+				a.Profile = args[0]
+			}
+			return a.Run(cmd.Context())
+		},
+		SilenceErrors: true,
+		SilenceUsage:  true,
+	}
+	f := c.Flags()
+	f.StringVar(&a.RoleARN, "role-arn", "", "Role to assume with the profile's credentials")
+	f.StringVarP(&a.Output, "output", "o", outputTable, "Output format: table, json, yaml or csv")
+	return c
+}
@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/pedrokiefer/route53copy/pkg/dns"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWhoami_Run(t *testing.T) {
+	oldWho, oldOut, oldRegion := whoAmI, stdout, region
+	t.Cleanup(func() { whoAmI = oldWho; stdout = oldOut; region = oldRegion })
+
+	var got dns.AWSOptions
+	whoAmI = func(ctx context.Context, o dns.AWSOptions) (*dns.Identity, error) {
+		got = o
+		return &dns.Identity{Account: "123456789012", ARN: "arn:aws:iam::123456789012:user/ops", Region: "eu-west-1"}, nil
+	}
+	var buf bytes.Buffer
+	stdout = &buf
+	region = "eu-west-1"
+
+	a := &whoamiApp{Profile: "p", RoleARN: "arn:aws:iam::123456789012:role/dns", Output: outputYAML}
+	require.NoError(t, a.Run(context.Background()))
+	require.Equal(t, dns.AWSOptions{Profile: "p", Region: "eu-west-1", RoleARN: "arn:aws:iam::123456789012:role/dns"}, got)
+	require.Contains(t, buf.String(), "- profile: p\n  account: \"123456789012\"\n")
+	require.Contains(t, buf.String(), "region: eu-west-1")
+}
+
+func TestWhoami_Run_ReturnsCredentialErrors(t *testing.T) {
+	oldWho := whoAmI
+	t.Cleanup(func() { whoAmI = oldWho })
+
+	whoAmI = func(ctx context.Context, o dns.AWSOptions) (*dns.Identity, error) {
+		return nil, errors.New("failed to get shared config profile, nope")
+	}
+	a := &whoamiApp{Profile: "nope"}
+	require.ErrorContains(t, a.Run(context.Background()), "nope")
+}
@@ -0,0 +1,218 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	rtypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/pedrokiefer/route53copy/pkg/dns"
+	"github.com/spf13/cobra"
+)
+
+var errNoZoneSelected = errors.New("no zones selected: use --zone, --all, --zones-file, --tag, --name-regex, --private or --public")
+
+// zoneSelector picks the hosted zones a command works on. Zones named with
+// --zone or --zones-file are looked up directly; otherwise every zone in the
+// account is listed. Tag, name and privacy filters apply to both.
+type zoneSelector struct {
+	Names     []string
+	All       bool
+	ZonesFile string
+	Tags      []string
+	NameRegex string
+	zoneFilterFlags
+
+	// defaultAll selects every zone when no selection flag is given.
+	defaultAll bool
+
+	// legacyDomain and legacyAll back the deprecated -d and -a flags.
+	legacyDomain string
+	legacyAll    bool
+}
+
+func (s *zoneSelector) addFlags(c *cobra.Command) {
+	f := c.Flags()
+	f.StringSliceVarP(&s.Names, "zone", "z", nil, "Zone name or ID to select, may be repeated")
+	f.BoolVar(&s.All, "all", false, "Select all zones in the account")
+	f.StringVar(&s.ZonesFile, "zones-file", "", "File with one zone per line")
+	f.StringSliceVar(&s.Tags, "tag", nil, "Only zones with this tag, as key=value or key; may be repeated")
+	f.StringVar(&s.NameRegex, "name-regex", "", "Only zones whose name matches this regular expression")
+	s.zoneFilterFlags.addFlags(c)
+	c.MarkFlagsMutuallyExclusive("all", "zone")
+	c.MarkFlagsMutuallyExclusive("all", "zones-file")
+}
+
+// addLegacyFlags keeps the old -a and -d flags working for commands that
+// had them.
+func (s *zoneSelector) addLegacyFlags(c *cobra.Command, domain bool) {
+	f := c.Flags()
+	f.BoolVar(&s.legacyAll, "a", false, "Select all zones")
+	_ = f.MarkDeprecated("a", "use --all instead")
+	if domain {
+		f.StringVar(&s.legacyDomain, "d", "", "Select a specific zone")
+		_ = f.MarkDeprecated("d", "use --zone instead")
+	}
+}
+
+func (s *zoneSelector) names() ([]string, error) {
+	names := append([]string{}, s.Names...)
+	if s.legacyDomain != "" {
+		names = append(names, s.legacyDomain)
+	}
+	if s.ZonesFile == "" {
+		return names, nil
+	}
+
+	f, err := os.Open(s.ZonesFile)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		names = append(names, line)
+	}
+	return names, scanner.Err()
+}
+
+func (s *zoneSelector) filtering() bool {
+	return len(s.Tags) > 0 || s.NameRegex != "" || s.Private || s.Public || s.VPCID != ""
+}
+
+// Select resolves the selection against the account behind rm.
+func (s *zoneSelector) Select(ctx context.Context, rm RouteManagerAPI) ([]rtypes.HostedZone, error) {
+	if s.Private && s.Public {
+		return nil, errors.New("--private and --public are mutually exclusive")
+	}
+	names, err := s.names()
+	if err != nil {
+		return nil, err
+	}
+	all := s.All || s.legacyAll
+	if all && len(names) > 0 {
+		return nil, errors.New("--all cannot be combined with --zone or --zones-file")
+	}
+
+	zones := []rtypes.HostedZone{}
+	switch {
+	case len(names) > 0:
+		for _, name := range names {
+			zone, err := rm.FindHostedZone(ctx, name, s.zoneFilter())
+			if err != nil {
+				return nil, err
+			}
+			zones = append(zones, zone)
+		}
+	case all || s.filtering() || s.defaultAll:
+		zones, err = rm.ListHostedZones(ctx)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errNoZoneSelected
+	}
+
+	return s.filter(ctx, rm, zones)
+}
+
+func (s *zoneSelector) filter(ctx context.Context, rm RouteManagerAPI, zones []rtypes.HostedZone) ([]rtypes.HostedZone, error) {
+	var nameRe *regexp.Regexp
+	if s.NameRegex != "" {
+		re, err := regexp.Compile(s.NameRegex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --name-regex: %w", err)
+		}
+		nameRe = re
+	}
+
+	zf := s.zoneFilter()
+	selected := []rtypes.HostedZone{}
+	for _, zone := range zones {
+		var vpcs []dns.VPC
+		if zf.NeedsVPCs() && dns.IsPrivateZone(zone) {
+			v, err := rm.GetZoneVPCs(ctx, aws.ToString(zone.Id))
+			if err != nil {
+				return nil, err
+			}
+			vpcs = v
+		}
+		if !zf.Match(zone, vpcs) {
+			continue
+		}
+		if nameRe != nil && !nameRe.MatchString(aws.ToString(zone.Name)) {
+			continue
+		}
+		if len(s.Tags) > 0 {
+			ok, err := s.matchTags(ctx, rm, aws.ToString(zone.Id))
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+		}
+		selected = append(selected, zone)
+	}
+	return selected, nil
+}
+
+// matchTags reports whether the zone carries every --tag. A bare key matches
+// any value.
+func (s *zoneSelector) matchTags(ctx context.Context, rm RouteManagerAPI, zoneID string) (bool, error) {
+	tags, err := rm.GetZoneTags(ctx, zoneID)
+	if err != nil {
+		return false, err
+	}
+	for _, want := range s.Tags {
+		key, value, hasValue := strings.Cut(want, "=")
+		found := false
+		for _, t := range tags {
+			if t.Name == key && (!hasValue || t.Value == value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// zoneFilterFlags choose between zones that share a name, such as a public
+// zone and its private split-horizon counterpart.
+type zoneFilterFlags struct {
+	Private   bool
+	Public    bool
+	VPCID     string
+	VPCRegion string
+}
+
+func (z *zoneFilterFlags) addFlags(c *cobra.Command) {
+	f := c.Flags()
+	f.BoolVar(&z.Private, "private", false, "Only private zones")
+	f.BoolVar(&z.Public, "public", false, "Only public zones")
+	f.StringVar(&z.VPCID, "vpc", "", "Only private zones associated with this VPC ID")
+	f.StringVar(&z.VPCRegion, "vpc-region", "", "Region of --vpc (default: any)")
+	c.MarkFlagsMutuallyExclusive("private", "public")
+	c.MarkFlagsMutuallyExclusive("public", "vpc")
+}
+
+func (z zoneFilterFlags) zoneFilter() dns.ZoneFilter {
+	return dns.ZoneFilter{
+		PrivateOnly: z.Private,
+		PublicOnly:  z.Public,
+		VPC:         dns.VPC{ID: z.VPCID, Region: z.VPCRegion},
+	}
+}
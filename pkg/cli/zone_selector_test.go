@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	rtypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/pedrokiefer/route53copy/pkg/dns"
+	"github.com/stretchr/testify/require"
+)
+
+func selectorFake() *fakeRouteManager {
+	zone := func(id, name string, private bool) rtypes.HostedZone {
+		return rtypes.HostedZone{
+			Id:     aws.String(id),
+			Name:   aws.String(name),
+			Config: &rtypes.HostedZoneConfig{PrivateZone: private},
+		}
+	}
+	zones := []rtypes.HostedZone{
+		zone("/hostedzone/Z1", "example.com.", false),
+		zone("/hostedzone/Z2", "example.org.", false),
+		zone("/hostedzone/Z3", "internal.example.com.", true),
+	}
+	f := &fakeRouteManager{
+		Zones:       zones,
+		ZonesByName: map[string]rtypes.HostedZone{},
+		TagsByID: map[string][]dns.Tag{
+			"/hostedzone/Z1": {{Name: "env", Value: "prod"}, {Name: "team", Value: "dns"}},
+			"/hostedzone/Z2": {{Name: "env", Value: "dev"}},
+		},
+	}
+	for _, z := range zones {
+		f.ZonesByName[aws.ToString(z.Name)] = z
+	}
+	return f
+}
+
+func selectedNames(t *testing.T, s zoneSelector) []string {
+	t.Helper()
+	zones, err := s.Select(context.Background(), selectorFake())
+	require.NoError(t, err)
+	names := []string{}
+	for _, z := range zones {
+		names = append(names, aws.ToString(z.Name))
+	}
+	return names
+}
+
+func TestZoneSelector_Select(t *testing.T) {
+	require.Equal(t, []string{"example.org."}, selectedNames(t, zoneSelector{Names: []string{"example.org"}}))
+	require.Len(t, selectedNames(t, zoneSelector{All: true}), 3)
+	require.Equal(t, []string{"example.com.", "example.org."}, selectedNames(t, zoneSelector{zoneFilterFlags: zoneFilterFlags{Public: true}}))
+	require.Equal(t, []string{"internal.example.com."}, selectedNames(t, zoneSelector{zoneFilterFlags: zoneFilterFlags{Private: true}}))
+	require.Equal(t, []string{"example.com.", "internal.example.com."}, selectedNames(t, zoneSelector{NameRegex: `example\.com\.$`}))
+	require.Equal(t, []string{"example.com."}, selectedNames(t, zoneSelector{Tags: []string{"env=prod", "team"}}))
+	require.Empty(t, selectedNames(t, zoneSelector{Names: []string{"example.org"}, Tags: []string{"env=prod"}}))
+	require.Len(t, selectedNames(t, zoneSelector{defaultAll: true}), 3)
+	require.Equal(t, []string{"example.com."}, selectedNames(t, zoneSelector{legacyDomain: "example.com"}))
+}
+
+func TestZoneSelector_ZonesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "zones.txt")
+	require.NoError(t, os.WriteFile(path, []byte("# zones to check\nexample.com\n\nexample.org.\n"), 0o644))
+
+	require.Equal(t, []string{"example.com.", "example.org."}, selectedNames(t, zoneSelector{ZonesFile: path}))
+}
+
+func TestZoneSelector_Errors(t *testing.T) {
+	ctx := context.Background()
+	_, err := (&zoneSelector{}).Select(ctx, selectorFake())
+	require.ErrorIs(t, err, errNoZoneSelected)
+
+	_, err = (&zoneSelector{legacyAll: true, Names: []string{"example.com"}}).Select(ctx, selectorFake())
+	require.Error(t, err)
+
+	_, err = (&zoneSelector{NameRegex: "("}).Select(ctx, selectorFake())
+	require.Error(t, err)
+
+	_, err = (&zoneSelector{Names: []string{"missing.com"}}).Select(ctx, selectorFake())
+	var nf *dns.HostedZoneNotFound
+	require.ErrorAs(t, err, &nf)
+}
+
+func TestCheckZoneCommand_RequiresSelection(t *testing.T) {
+	oldNewRM := newRouteManager
+	t.Cleanup(func() { newRouteManager = oldNewRM })
+	newRouteManager = func(ctx context.Context, o dns.AWSOptions, rmo *dns.RouteManagerOptions) (RouteManagerAPI, error) {
+		return selectorFake(), nil
+	}
+
+	_, err := runCmd(newCheckZoneCmd(), []string{"profile"})
+	require.ErrorIs(t, err, errNoZoneSelected)
+
+	_, err = runCmd(newParkCommand(), []string{"profile", "--alias=false", "192.0.2.1"})
+	require.ErrorIs(t, err, errNoZoneSelected)
+
+	_, err = runCmd(newCheckZoneCmd(), []string{"profile", "--all", "--zone", "example.com"})
+	require.Error(t, err)
+}
@@ -0,0 +1,192 @@
+package dig
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// Config controls which servers are queried and how.
+type Config struct {
+	// Servers are tried in order, moving to the next one when a server
+	// times out or fails. Entries are "host" or "host:port", optionally
+	// prefixed by a transport (udp://, tcp://, tls:// or an https:// DoH
+	// URL). When empty the nameservers from /etc/resolv.conf are used.
+	Servers []string
+	// Timeout bounds a single query.
+	Timeout time.Duration
+	// Retries is the number of attempts per server on timeouts.
+	Retries int
+}
+
+// fallbackServers are used only when neither Config.Servers nor
+// /etc/resolv.conf provide a nameserver.
+var fallbackServers = []string{"8.8.8.8", "1.1.1.1"}
+
+var (
+	configMu sync.RWMutex
+	config   = DefaultConfig()
+)
+
+func DefaultConfig() Config {
+	return Config{
+		Timeout: 5 * time.Second,
+		Retries: 3,
+	}
+}
+
+// Configure replaces the configuration used by every query in this package.
+func Configure(c Config) {
+	d := DefaultConfig()
+	if c.Timeout <= 0 {
+		c.Timeout = d.Timeout
+	}
+	if c.Retries <= 0 {
+		c.Retries = d.Retries
+	}
+	configMu.Lock()
+	defer configMu.Unlock()
+	config = c
+}
+
+// CurrentConfig returns the configuration in use.
+func CurrentConfig() Config {
+	configMu.RLock()
+	defer configMu.RUnlock()
+	return config
+}
+
+// errTimeout is returned by exchange when every attempt timed out.
+var errTimeout = errors.New("timeout")
+
+// transports returns a transport per configured server.
+func transports(c Config) ([]Transport, error) {
+	servers := c.Servers
+	port := "53"
+	if len(servers) == 0 {
+		if cc, err := loadClientConfig(); err == nil && len(cc.Servers) > 0 {
+			servers = cc.Servers
+			port = cc.Port
+		} else {
+			servers = fallbackServers
+		}
+	}
+
+	ts := make([]Transport, 0, len(servers))
+	for _, s := range servers {
+		t, err := newTransport(s, port, c.Timeout)
+		if err != nil {
+			return nil, err
+		}
+		ts = append(ts, t)
+	}
+	return ts, nil
+}
+
+func withPort(server, port string) string {
+	if _, _, err := net.SplitHostPort(server); err == nil {
+		return server
+	}
+	return net.JoinHostPort(strings.Trim(server, "[]"), port)
+}
+
+// exchange sends m through the configured transports, failing over on
+// errors and SERVFAIL/REFUSED answers.
+func exchange(ctx context.Context, m *dns.Msg) (*dns.Msg, error) {
+	cfg := CurrentConfig()
+	ts, err := transports(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var lastErr error
+	var lastResp *dns.Msg
+	for _, t := range ts {
+		for attempt := 0; attempt < cfg.Retries; attempt++ {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+
+			r, err := t.Exchange(ctx, m)
+			if err != nil {
+				var ne net.Error
+				if errors.As(err, &ne) && ne.Timeout() {
+					lastErr = errTimeout
+					continue
+				}
+				lastErr = err
+				break
+			}
+
+			if r.Rcode == dns.RcodeServerFailure || r.Rcode == dns.RcodeRefused {
+				lastResp = r
+				break
+			}
+			return r, nil
+		}
+	}
+
+	if lastResp != nil {
+		return lastResp, nil
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no nameservers configured")
+	}
+	return nil, lastErr
+}
+
+// NetResolver returns a net.Resolver whose queries go through exchange, so
+// code using the standard library lookups honours the configured servers and
+// transports.
+func NetResolver() *net.Resolver {
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			client, server := net.Pipe()
+			go serveStream(ctx, server)
+			return client, nil
+		},
+	}
+}
+
+// serveStream answers length-prefixed DNS messages read from conn, the
+// framing the Go resolver uses on connections that are not packet based.
+func serveStream(ctx context.Context, conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+	for {
+		var l [2]byte
+		if _, err := io.ReadFull(conn, l[:]); err != nil {
+			return
+		}
+		buf := make([]byte, binary.BigEndian.Uint16(l[:]))
+		if _, err := io.ReadFull(conn, buf); err != nil {
+			return
+		}
+
+		m := &dns.Msg{}
+		if err := m.Unpack(buf); err != nil {
+			return
+		}
+		r, err := exchange(ctx, m)
+		if err != nil {
+			r = &dns.Msg{}
+			r.SetRcode(m, dns.RcodeServerFailure)
+		}
+		out, err := r.Pack()
+		if err != nil {
+			return
+		}
+		binary.BigEndian.PutUint16(l[:], uint16(len(out)))
+		if _, err := conn.Write(append(l[:], out...)); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package dig
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithPort(t *testing.T) {
+	require.Equal(t, "10.0.0.2:53", withPort("10.0.0.2", "53"))
+	require.Equal(t, "10.0.0.2:5353", withPort("10.0.0.2:5353", "53"))
+	require.Equal(t, "[2001:db8::1]:53", withPort("2001:db8::1", "53"))
+	require.Equal(t, "[2001:db8::1]:53", withPort("[2001:db8::1]", "53"))
+}
+
+func TestConfigure_Defaults(t *testing.T) {
+	t.Cleanup(func() { Configure(DefaultConfig()) })
+
+	Configure(Config{Servers: []string{"10.0.0.2"}})
+	cfg := CurrentConfig()
+	require.Equal(t, 5*time.Second, cfg.Timeout)
+	require.Equal(t, 3, cfg.Retries)
+	ts, err := transports(cfg)
+	require.NoError(t, err)
+	require.Len(t, ts, 1)
+	require.Equal(t, "10.0.0.2:53", ts[0].String())
+}
+
+func TestExchange_FailsOverToNextServer(t *testing.T) {
+	oldEx := exchangeFunc
+	t.Cleanup(func() { exchangeFunc = oldEx; Configure(DefaultConfig()) })
+	Configure(Config{Servers: []string{"10.0.0.2", "10.0.0.3"}, Retries: 2})
+
+	calls := map[string]int{}
+	exchangeFunc = func(ctx context.Context, c *dns.Client, m *dns.Msg, addr string) (*dns.Msg, time.Duration, error) {
+		calls[addr]++
+		if addr == "10.0.0.2:53" {
+			return nil, 0, timeoutError{}
+		}
+		msg := new(dns.Msg)
+		msg.SetReply(m)
+		return msg, 0, nil
+	}
+
+	m := new(dns.Msg)
+	m.SetQuestion("example.com.", dns.TypeA)
+	r, err := exchange(context.Background(), m)
+	require.NoError(t, err)
+	require.Equal(t, dns.RcodeSuccess, r.Rcode)
+	require.Equal(t, map[string]int{"10.0.0.2:53": 2, "10.0.0.3:53": 1}, calls)
+}
+
+func TestExchange_ServfailFailsOverAndKeepsLastAnswer(t *testing.T) {
+	oldEx := exchangeFunc
+	t.Cleanup(func() { exchangeFunc = oldEx; Configure(DefaultConfig()) })
+	Configure(Config{Servers: []string{"10.0.0.2", "10.0.0.3"}})
+
+	calls := 0
+	exchangeFunc = func(ctx context.Context, c *dns.Client, m *dns.Msg, addr string) (*dns.Msg, time.Duration, error) {
+		calls++
+		msg := new(dns.Msg)
+		msg.SetRcode(m, dns.RcodeServerFailure)
+		return msg, 0, nil
+	}
+
+	m := new(dns.Msg)
+	m.SetQuestion("example.com.", dns.TypeA)
+	r, err := exchange(context.Background(), m)
+	require.NoError(t, err)
+	require.Equal(t, dns.RcodeServerFailure, r.Rcode)
+	require.Equal(t, 2, calls)
+}
+
+func TestExchange_TruncatedRetriesOverTCP(t *testing.T) {
+	oldEx := exchangeFunc
+	t.Cleanup(func() { exchangeFunc = oldEx; Configure(DefaultConfig()) })
+	Configure(Config{Servers: []string{"10.0.0.2"}})
+
+	nets := []string{}
+	exchangeFunc = func(ctx context.Context, c *dns.Client, m *dns.Msg, addr string) (*dns.Msg, time.Duration, error) {
+		nets = append(nets, c.Net)
+		msg := new(dns.Msg)
+		msg.SetReply(m)
+		msg.Truncated = c.Net == ""
+		return msg, 0, nil
+	}
+
+	m := new(dns.Msg)
+	m.SetQuestion("example.com.", dns.TypeTXT)
+	r, err := exchange(context.Background(), m)
+	require.NoError(t, err)
+	require.False(t, r.Truncated)
+	require.Equal(t, []string{"", "tcp"}, nets)
+}
+
+func TestResolve_TimeoutOnAllServers(t *testing.T) {
+	oldEx := exchangeFunc
+	t.Cleanup(func() { exchangeFunc = oldEx; Configure(DefaultConfig()) })
+	Configure(Config{Servers: []string{"10.0.0.2"}})
+
+	exchangeFunc = func(ctx context.Context, c *dns.Client, m *dns.Msg, addr string) (*dns.Msg, time.Duration, error) {
+		return nil, 0, timeoutError{}
+	}
+
+	_, err := realResolver{}.Resolve(context.Background(), "example.com", "A")
+	var rerr *ResolveError
+	require.True(t, errors.As(err, &rerr))
+	require.Equal(t, "timeout", rerr.Type)
+}
+
+func TestNetResolver_UsesConfiguredServers(t *testing.T) {
+	oldEx := exchangeFunc
+	t.Cleanup(func() { exchangeFunc = oldEx; Configure(DefaultConfig()) })
+	Configure(Config{Servers: []string{"10.0.0.2"}})
+
+	exchangeFunc = func(ctx context.Context, c *dns.Client, m *dns.Msg, addr string) (*dns.Msg, time.Duration, error) {
+		msg := new(dns.Msg)
+		msg.SetReply(m)
+		if m.Question[0].Qtype == dns.TypeA {
+			msg.Answer = []dns.RR{&dns.A{
+				Hdr: dns.RR_Header{Name: m.Question[0].Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 60},
+				A:   net.ParseIP("192.0.2.10"),
+			}}
+		}
+		return msg, 0, nil
+	}
+
+	addrs, err := NetResolver().LookupHost(context.Background(), "example.com")
+	require.NoError(t, err)
+	require.Equal(t, []string{"192.0.2.10"}, addrs)
+}
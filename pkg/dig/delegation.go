@@ -0,0 +1,295 @@
+package dig
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+type DelegationIssueKind string
+
+const (
+	IssueUndelegated      DelegationIssueKind = "undelegated"
+	IssueParentMismatch   DelegationIssueKind = "parent_child_mismatch"
+	IssueLameServer       DelegationIssueKind = "lame_server"
+	IssueSerialMismatch   DelegationIssueKind = "inconsistent_serial"
+	IssueMissingGlue      DelegationIssueKind = "missing_glue"
+	IssueUnreachableChild DelegationIssueKind = "unreachable_server"
+)
+
+type DelegationIssue struct {
+	Kind   DelegationIssueKind `json:"kind" yaml:"kind"`
+	Detail string              `json:"detail" yaml:"detail"`
+}
+
+// NameserverCheck is what a single nameserver of the zone answered.
+type NameserverCheck struct {
+	Name          string   `json:"name" yaml:"name"`
+	Addresses     []string `json:"addresses,omitempty" yaml:"addresses,omitempty"`
+	Authoritative bool     `json:"authoritative" yaml:"authoritative"`
+	Serial        uint32   `json:"serial,omitempty" yaml:"serial,omitempty"`
+	NS            []string `json:"ns,omitempty" yaml:"ns,omitempty"`
+	Error         string   `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// DelegationReport is the result of walking a zone's delegation from its
+// parent down to each of its nameservers.
+type DelegationReport struct {
+	Zone       string              `json:"zone" yaml:"zone"`
+	ParentZone string              `json:"parent_zone" yaml:"parent_zone"`
+	ParentNS   []string            `json:"parent_ns,omitempty" yaml:"parent_ns,omitempty"`
+	Glue       map[string][]string `json:"glue,omitempty" yaml:"glue,omitempty"`
+	ExpectedNS []string            `json:"expected_ns,omitempty" yaml:"expected_ns,omitempty"`
+	Servers    []NameserverCheck   `json:"servers,omitempty" yaml:"servers,omitempty"`
+	Issues     []DelegationIssue   `json:"issues,omitempty" yaml:"issues,omitempty"`
+}
+
+func (r *DelegationReport) addIssue(kind DelegationIssueKind, format string, args ...any) {
+	r.Issues = append(r.Issues, DelegationIssue{Kind: kind, Detail: fmt.Sprintf(format, args...)})
+}
+
+// AuditDelegation asks the parent zone's servers how zone is delegated and
+// then queries every nameserver in expectedNS directly for the zone's SOA and
+// NS records. Queries to parent and zone servers are sent without recursion
+// over plain DNS, since they must reach those servers themselves.
+func AuditDelegation(ctx context.Context, zone string, expectedNS []string) (*DelegationReport, error) {
+	zone = dns.Fqdn(strings.ToLower(zone))
+	report := &DelegationReport{
+		Zone:       zone,
+		Glue:       map[string][]string{},
+		ExpectedNS: normalizeNames(expectedNS),
+	}
+
+	parent, parentServers, err := findParent(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+	report.ParentZone = parent
+
+	referral, err := queryAny(ctx, parentServers, zone, dns.TypeNS)
+	if err != nil {
+		return nil, fmt.Errorf("querying %s servers for %s: %w", parent, zone, err)
+	}
+	readReferral(report, referral)
+
+	if len(report.ParentNS) == 0 {
+		report.addIssue(IssueUndelegated, "%s servers have no delegation for %s", parent, zone)
+	} else if missing, extra := diffNames(report.ExpectedNS, report.ParentNS); len(missing)+len(extra) > 0 {
+		report.addIssue(IssueParentMismatch, "parent delegates to %s, zone lists %s",
+			strings.Join(report.ParentNS, ","), strings.Join(report.ExpectedNS, ","))
+	}
+
+	for _, ns := range report.ParentNS {
+		if dns.IsSubDomain(zone, ns) && len(report.Glue[ns]) == 0 {
+			report.addIssue(IssueMissingGlue, "%s is inside %s but has no glue at the parent", ns, zone)
+		}
+	}
+
+	serials := map[uint32][]string{}
+	for _, ns := range report.ExpectedNS {
+		check := checkNameserver(ctx, zone, ns, report.Glue[ns])
+		report.Servers = append(report.Servers, check)
+
+		switch {
+		case check.Error != "" && len(check.Addresses) == 0:
+			report.addIssue(IssueUnreachableChild, "%s: %s", ns, check.Error)
+			continue
+		case !check.Authoritative:
+			detail := "does not answer authoritatively"
+			if check.Error != "" {
+				detail = check.Error
+			}
+			report.addIssue(IssueLameServer, "%s %s for %s", ns, detail, zone)
+			continue
+		}
+		serials[check.Serial] = append(serials[check.Serial], ns)
+
+		if missing, extra := diffNames(report.ExpectedNS, check.NS); len(missing)+len(extra) > 0 {
+			report.addIssue(IssueParentMismatch, "%s serves NS %s", ns, strings.Join(check.NS, ","))
+		}
+	}
+
+	if len(serials) > 1 {
+		parts := []string{}
+		for serial, servers := range serials {
+			parts = append(parts, fmt.Sprintf("%d (%s)", serial, strings.Join(servers, ",")))
+		}
+		sort.Strings(parts)
+		report.addIssue(IssueSerialMismatch, "SOA serials differ: %s", strings.Join(parts, ", "))
+	}
+
+	return report, nil
+}
+
+// findParent walks up from zone until a name with NS records is found and
+// returns it with the addresses of its servers.
+func findParent(ctx context.Context, zone string) (string, []string, error) {
+	labels := dns.SplitDomainName(zone)
+	for i := 1; i <= len(labels); i++ {
+		parent := dns.Fqdn(strings.Join(labels[i:], "."))
+		resp, err := Resolve(ctx, parent, "NS")
+		if err != nil || len(resp.Values()) == 0 {
+			continue
+		}
+
+		addrs := []string{}
+		for _, ns := range resp.Values() {
+			addrs = append(addrs, lookupAddrs(ctx, ns)...)
+		}
+		if len(addrs) == 0 {
+			return "", nil, fmt.Errorf("no addresses for %s nameservers", parent)
+		}
+		return parent, addrs, nil
+	}
+	return "", nil, fmt.Errorf("no parent zone found for %s", zone)
+}
+
+func readReferral(report *DelegationReport, m *dns.Msg) {
+	// A referral carries the NS set in the authority section; a parent that
+	// is also authoritative for the child may answer it directly.
+	for _, rr := range append(append([]dns.RR{}, m.Ns...), m.Answer...) {
+		if ns, ok := rr.(*dns.NS); ok && strings.EqualFold(ns.Hdr.Name, report.Zone) {
+			report.ParentNS = appendUnique(report.ParentNS, strings.ToLower(ns.Ns))
+		}
+	}
+	for _, rr := range m.Extra {
+		name := strings.ToLower(rr.Header().Name)
+		switch v := rr.(type) {
+		case *dns.A:
+			report.Glue[name] = append(report.Glue[name], v.A.String())
+		case *dns.AAAA:
+			report.Glue[name] = append(report.Glue[name], v.AAAA.String())
+		}
+	}
+	sort.Strings(report.ParentNS)
+}
+
+// CheckNameserver queries ns directly for the SOA and NS records of zone.
+func CheckNameserver(ctx context.Context, zone, ns string) NameserverCheck {
+	return checkNameserver(ctx, dns.Fqdn(strings.ToLower(zone)), ns, nil)
+}
+
+func checkNameserver(ctx context.Context, zone, ns string, glue []string) NameserverCheck {
+	check := NameserverCheck{Name: ns, Addresses: glue}
+	if len(check.Addresses) == 0 {
+		check.Addresses = lookupAddrs(ctx, ns)
+	}
+	if len(check.Addresses) == 0 {
+		check.Error = "no address found"
+		return check
+	}
+
+	soa, err := queryAny(ctx, check.Addresses, zone, dns.TypeSOA)
+	if err != nil {
+		check.Error = err.Error()
+		return check
+	}
+	if soa.Rcode != dns.RcodeSuccess {
+		check.Error = fmt.Sprintf("answers %s", dns.RcodeToString[soa.Rcode])
+		return check
+	}
+	check.Authoritative = soa.Authoritative
+	for _, rr := range soa.Answer {
+		if s, ok := rr.(*dns.SOA); ok {
+			check.Serial = s.Serial
+		}
+	}
+
+	nsResp, err := queryAny(ctx, check.Addresses, zone, dns.TypeNS)
+	if err != nil {
+		check.Error = err.Error()
+		return check
+	}
+	for _, rr := range nsResp.Answer {
+		if v, ok := rr.(*dns.NS); ok {
+			check.NS = appendUnique(check.NS, strings.ToLower(v.Ns))
+		}
+	}
+	sort.Strings(check.NS)
+	return check
+}
+
+func lookupAddrs(ctx context.Context, host string) []string {
+	addrs := []string{}
+	for _, t := range []string{"A", "AAAA"} {
+		resp, err := Resolve(ctx, host, t)
+		if err != nil {
+			continue
+		}
+		addrs = append(addrs, resp.Values()...)
+	}
+	return addrs
+}
+
+// queryAny sends a non-recursive query to each address in turn and returns
+// the first answer.
+func queryAny(ctx context.Context, addrs []string, name string, qtype uint16) (*dns.Msg, error) {
+	cfg := CurrentConfig()
+	m := &dns.Msg{}
+	m.SetQuestion(name, qtype)
+	m.RecursionDesired = false
+
+	var lastErr error
+	for _, addr := range addrs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		t := &classicTransport{addr: net.JoinHostPort(addr, "53"), timeout: cfg.Timeout}
+		r, err := t.Exchange(ctx, m)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return r, nil
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no servers to query")
+	}
+	return nil, lastErr
+}
+
+func normalizeNames(names []string) []string {
+	out := []string{}
+	for _, n := range names {
+		out = appendUnique(out, dns.Fqdn(strings.ToLower(n)))
+	}
+	sort.Strings(out)
+	return out
+}
+
+func appendUnique(list []string, v string) []string {
+	for _, l := range list {
+		if l == v {
+			return list
+		}
+	}
+	return append(list, v)
+}
+
+// diffNames returns the names in want missing from got and the ones in got
+// that are not wanted.
+func diffNames(want, got []string) (missing, extra []string) {
+	in := func(list []string, v string) bool {
+		for _, l := range list {
+			if strings.EqualFold(l, v) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, w := range want {
+		if !in(got, w) {
+			missing = append(missing, w)
+		}
+	}
+	for _, g := range got {
+		if !in(want, g) {
+			extra = append(extra, g)
+		}
+	}
+	return missing, extra
+}
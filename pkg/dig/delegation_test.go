@@ -0,0 +1,141 @@
+package dig
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/require"
+)
+
+// mapResolver answers recursive lookups from a fixed table.
+type mapResolver map[string][]string
+
+func (m mapResolver) Resolve(ctx context.Context, domain string, t string) (*Response, error) {
+	resp := &Response{Name: dns.Fqdn(domain), Type: t}
+	for _, v := range m[t+" "+dns.Fqdn(domain)] {
+		resp.Answer = append(resp.Answer, Record{Name: resp.Name, Type: t, TTL: 300, Value: v})
+	}
+	return resp, nil
+}
+
+func nsRR(zone, ns string) dns.RR {
+	return &dns.NS{Hdr: dns.RR_Header{Name: zone, Rrtype: dns.TypeNS, Class: dns.ClassINET, Ttl: 300}, Ns: ns}
+}
+
+func soaRR(zone string, serial uint32) dns.RR {
+	return &dns.SOA{Hdr: dns.RR_Header{Name: zone, Rrtype: dns.TypeSOA, Class: dns.ClassINET, Ttl: 300},
+		Ns: "ns1.example.net.", Mbox: "hostmaster.example.com.", Serial: serial}
+}
+
+func TestAuditDelegation(t *testing.T) {
+	oldEx, oldRes := exchangeFunc, CurrentResolver
+	t.Cleanup(func() { exchangeFunc = oldEx; CurrentResolver = oldRes })
+
+	CurrentResolver = mapResolver{
+		"NS com.":                 {"a.gtld.test."},
+		"A a.gtld.test.":          {"192.0.2.1"},
+		"A ns1.example.net.":      {"192.0.2.10"},
+		"A ns2.example.net.":      {"192.0.2.11"},
+		"A ns3.example.net.":      {"192.0.2.12"},
+		"AAAA ns1.example.net.":   {},
+		"NS example.com.":         {"ns1.example.net."},
+		"A ns-stale.example.org.": {"192.0.2.20"},
+	}
+
+	exchangeFunc = func(ctx context.Context, c *dns.Client, m *dns.Msg, addr string) (*dns.Msg, time.Duration, error) {
+		require.False(t, m.RecursionDesired)
+		host, _, _ := net.SplitHostPort(addr)
+		q := m.Question[0]
+		msg := new(dns.Msg)
+		msg.SetReply(m)
+		switch host {
+		case "192.0.2.1":
+			msg.Ns = []dns.RR{nsRR("example.com.", "ns1.example.net."), nsRR("example.com.", "ns-stale.example.org.")}
+		case "192.0.2.10", "192.0.2.11":
+			msg.Authoritative = true
+			serial := uint32(10)
+			if host == "192.0.2.11" {
+				serial = 9
+			}
+			if q.Qtype == dns.TypeSOA {
+				msg.Answer = []dns.RR{soaRR(q.Name, serial)}
+			} else {
+				msg.Answer = []dns.RR{nsRR(q.Name, "ns1.example.net."), nsRR(q.Name, "ns2.example.net."), nsRR(q.Name, "ns3.example.net.")}
+			}
+		case "192.0.2.12":
+			msg.Rcode = dns.RcodeRefused
+		}
+		return msg, 0, nil
+	}
+
+	report, err := AuditDelegation(context.Background(), "example.com",
+		[]string{"ns1.example.net", "ns2.example.net.", "ns3.example.net."})
+	require.NoError(t, err)
+
+	require.Equal(t, "com.", report.ParentZone)
+	require.Equal(t, []string{"ns-stale.example.org.", "ns1.example.net."}, report.ParentNS)
+	require.Len(t, report.Servers, 3)
+	require.Equal(t, uint32(10), report.Servers[0].Serial)
+	require.True(t, report.Servers[0].Authoritative)
+	require.False(t, report.Servers[2].Authoritative)
+
+	kinds := []DelegationIssueKind{}
+	for _, i := range report.Issues {
+		kinds = append(kinds, i.Kind)
+	}
+	require.ElementsMatch(t, []DelegationIssueKind{
+		IssueParentMismatch,
+		IssueLameServer,
+		IssueSerialMismatch,
+	}, kinds)
+}
+
+func TestAuditDelegation_MissingGlueAndUndelegated(t *testing.T) {
+	oldEx, oldRes := exchangeFunc, CurrentResolver
+	t.Cleanup(func() { exchangeFunc = oldEx; CurrentResolver = oldRes })
+
+	CurrentResolver = mapResolver{
+		"NS com.":          {"a.gtld.test."},
+		"A a.gtld.test.":   {"192.0.2.1"},
+		"A ns1.glue.com.":  {"192.0.2.10"},
+		"NS nothere.com.":  {},
+		"A ns1.other.net.": {},
+	}
+
+	exchangeFunc = func(ctx context.Context, c *dns.Client, m *dns.Msg, addr string) (*dns.Msg, time.Duration, error) {
+		q := m.Question[0]
+		msg := new(dns.Msg)
+		msg.SetReply(m)
+		switch {
+		case addr == "192.0.2.1:53" && q.Name == "glue.com.":
+			msg.Ns = []dns.RR{nsRR("glue.com.", "ns1.glue.com.")}
+		case addr == "192.0.2.1:53":
+			msg.Authoritative = true
+			msg.Rcode = dns.RcodeNameError
+		default:
+			msg.Authoritative = true
+			if q.Qtype == dns.TypeSOA {
+				msg.Answer = []dns.RR{soaRR(q.Name, 1)}
+			} else {
+				msg.Answer = []dns.RR{nsRR(q.Name, "ns1.glue.com.")}
+			}
+		}
+		return msg, 0, nil
+	}
+
+	report, err := AuditDelegation(context.Background(), "glue.com.", []string{"ns1.glue.com."})
+	require.NoError(t, err)
+	require.Len(t, report.Issues, 1)
+	require.Equal(t, IssueMissingGlue, report.Issues[0].Kind)
+
+	report, err = AuditDelegation(context.Background(), "nothere.com.", []string{"ns1.other.net."})
+	require.NoError(t, err)
+	kinds := []DelegationIssueKind{}
+	for _, i := range report.Issues {
+		kinds = append(kinds, i.Kind)
+	}
+	require.Equal(t, []DelegationIssueKind{IssueUndelegated, IssueUnreachableChild}, kinds)
+}
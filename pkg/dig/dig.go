@@ -4,7 +4,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"net"
 	"regexp"
 	"strings"
 	"time"
@@ -22,19 +21,23 @@ func (e NSRecordNotFound) Error() string {
 	return fmt.Sprintf("failed to get nameservers for: %s", e.Domain)
 }
 
+// ResolveError is returned when a query is answered with an rcode other
+// than NOERROR, or when no server answered at all. Type holds the rcode
+// name (e.g. "NXDOMAIN") or "timeout".
 type ResolveError struct {
 	Domain string
 	Type   string
 }
 
 func (e ResolveError) Error() string {
-	return fmt.Sprintf("failed to get nameservers for: %s", e.Domain)
+	return fmt.Sprintf("failed to resolve %s: %s", e.Domain, e.Type)
 }
 
 // DNSResolver defines an interface for DNS queries used by this package.
-// It enables overriding in tests.
+// It enables overriding in tests. Resolve returns the Response alongside a
+// *ResolveError when the server answered with a failure rcode.
 type DNSResolver interface {
-	Resolve(ctx context.Context, domain string, t string) error
+	Resolve(ctx context.Context, domain string, t string) (*Response, error)
 }
 
 // CurrentResolver is the pluggable resolver used by Resolve.
@@ -42,7 +45,7 @@ type DNSResolver interface {
 var CurrentResolver DNSResolver = realResolver{}
 
 // Resolve is the public entry point which delegates to CurrentResolver.
-func Resolve(ctx context.Context, domain string, t string) error {
+func Resolve(ctx context.Context, domain string, t string) (*Response, error) {
 	return CurrentResolver.Resolve(ctx, domain, t)
 }
 
@@ -51,96 +54,62 @@ func RealResolverForTest() DNSResolver { return realResolver{} }
 
 // test seams for GetNameserversFor
 var loadClientConfig = func() (*dns.ClientConfig, error) { return dns.ClientConfigFromFile("/etc/resolv.conf") }
-var exchangeFunc = func(c *dns.Client, m *dns.Msg, addr string) (*dns.Msg, time.Duration, error) {
-	return c.Exchange(m, addr)
+var exchangeFunc = func(ctx context.Context, c *dns.Client, m *dns.Msg, addr string) (*dns.Msg, time.Duration, error) {
+	return c.ExchangeContext(ctx, m, addr)
 }
 
 // realResolver contains the production implementation of DNS resolution.
 type realResolver struct{}
 
-func (realResolver) Resolve(ctx context.Context, domain string, t string) error {
+func (realResolver) Resolve(ctx context.Context, domain string, t string) (*Response, error) {
 	_t, ok := dns.StringToType[t]
 	if !ok {
-		return fmt.Errorf("invalid type: %s", t)
-	}
-
-	config := &dns.ClientConfig{
-		Servers: []string{"8.8.8.8", "8.8.4.4"},
-		Search:  []string{""},
-		Port:    "53",
-		Timeout: 5,
+		return nil, fmt.Errorf("invalid type: %s", t)
 	}
-	// config, _ := dns.ClientConfigFromFile("/etc/resolv.conf")
-
-	c := &dns.Client{
-		Timeout: 15 * time.Second,
-	}
-
-	m := &dns.Msg{}
-	m.SetQuestion(dns.Fqdn(domain), _t)
-	m.RecursionDesired = true
-
-	var r *dns.Msg
-	var err error
-	maxRetry := 3
-	retries := 0
-	for {
-		retries++
 
-		if retries > maxRetry {
-			return &ResolveError{Domain: domain, Type: "timeout"}
-		}
+	resp := &Response{Name: dns.Fqdn(domain), Type: t}
+	name := resp.Name
+	for hop := 0; hop <= maxCNAMEHops; hop++ {
+		m := &dns.Msg{}
+		m.SetQuestion(name, _t)
+		m.RecursionDesired = true
 
-		r, _, err = c.ExchangeContext(ctx, m, net.JoinHostPort(config.Servers[0], config.Port))
+		r, err := exchange(ctx, m)
 		if err != nil {
-			var ne net.Error
-			if errors.As(err, &ne) && ne.Timeout() {
-				continue
+			if errors.Is(err, errTimeout) {
+				return nil, &ResolveError{Domain: domain, Type: "timeout"}
 			}
-			return err
+			return nil, err
 		}
-		break
-	}
+		resp.add(r)
 
-	// log.Printf("d: %s, t: %s, r.Rcode: %d", domain, t, r.Rcode)
+		// Recursive servers usually return the whole chain; only chase it
+		// when the answer stops at a CNAME.
+		target := resp.Target()
+		if r.Rcode != dns.RcodeSuccess || _t == dns.TypeCNAME || resp.answered() || strings.EqualFold(target, name) {
+			break
+		}
+		name = target
+	}
 
-	if r.Rcode != dns.RcodeSuccess {
-		code := dns.RcodeToString[r.Rcode]
-		return &ResolveError{Domain: domain, Type: code}
+	if resp.Rcode != dns.RcodeSuccess {
+		return resp, &ResolveError{Domain: domain, Type: resp.RcodeString()}
 	}
 
-	return nil
+	return resp, nil
 }
 
 func GetNameserversFor(domain string) ([]string, error) {
-	config, _ := loadClientConfig()
-
-	c := &dns.Client{Timeout: 15 * time.Second}
-
 	m := &dns.Msg{}
 	m.SetQuestion(dns.Fqdn(domain), dns.TypeNS)
 	m.RecursionDesired = true
 
-	var r *dns.Msg
-	var err error
-	maxRetry := 3
-	retries := 0
-	for {
-		retries++
-
-		if retries > maxRetry {
+	r, err := exchange(context.Background(), m)
+	if err != nil {
+		if errors.Is(err, errTimeout) {
 			return nil, fmt.Errorf("failed to get nameservers for: %s", domain)
 		}
-
-		r, _, err = exchangeFunc(c, m, net.JoinHostPort(config.Servers[0], config.Port))
-		if err != nil {
-			var ne net.Error
-			if errors.As(err, &ne) && ne.Timeout() {
-				continue
-			}
-			return nil, err
-		}
-		break
+		return nil, err
 	}
 
 	if r.Rcode != dns.RcodeSuccess {
@@ -10,8 +10,8 @@ import (
 
 type fakeResolver struct{ err error }
 
-func (f fakeResolver) Resolve(ctx context.Context, domain string, t string) error {
-	return f.err
+func (f fakeResolver) Resolve(ctx context.Context, domain string, t string) (*Response, error) {
+	return nil, f.err
 }
 
 func TestResolve_DelegatesToCurrentResolver(t *testing.T) {
@@ -20,13 +20,13 @@ func TestResolve_DelegatesToCurrentResolver(t *testing.T) {
 	want := errors.New("boom")
 	CurrentResolver = fakeResolver{err: want}
 
-	got := Resolve(context.Background(), "example.com", "A")
+	_, got := Resolve(context.Background(), "example.com", "A")
 	require.Equal(t, want, got)
 }
 
 func TestResolve_RealResolver_InvalidType(t *testing.T) {
 	// Keep real resolver, check invalid type error
-	err := realResolver{}.Resolve(context.Background(), "example.com", "INVALID")
+	_, err := realResolver{}.Resolve(context.Background(), "example.com", "INVALID")
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "invalid type")
 }
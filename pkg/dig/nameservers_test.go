@@ -1,6 +1,7 @@
 package dig
 
 import (
+	"context"
 	"errors"
 	"net"
 	"testing"
@@ -19,7 +20,7 @@ func TestGetNameserversFor_Success(t *testing.T) {
 	loadClientConfig = func() (*dns.ClientConfig, error) {
 		return &dns.ClientConfig{Servers: []string{"127.0.0.1"}, Port: "53"}, nil
 	}
-	exchangeFunc = func(c *dns.Client, m *dns.Msg, addr string) (*dns.Msg, time.Duration, error) {
+	exchangeFunc = func(ctx context.Context, c *dns.Client, m *dns.Msg, addr string) (*dns.Msg, time.Duration, error) {
 		msg := new(dns.Msg)
 		msg.SetReply(m)
 		msg.Rcode = dns.RcodeSuccess
@@ -44,7 +45,7 @@ func TestGetNameserversFor_NotFound(t *testing.T) {
 	loadClientConfig = func() (*dns.ClientConfig, error) {
 		return &dns.ClientConfig{Servers: []string{"127.0.0.1"}, Port: "53"}, nil
 	}
-	exchangeFunc = func(c *dns.Client, m *dns.Msg, addr string) (*dns.Msg, time.Duration, error) {
+	exchangeFunc = func(ctx context.Context, c *dns.Client, m *dns.Msg, addr string) (*dns.Msg, time.Duration, error) {
 		msg := new(dns.Msg)
 		msg.SetReply(m)
 		msg.Rcode = dns.RcodeNameError // NXDOMAIN
@@ -74,7 +75,7 @@ func TestGetNameserversFor_TimeoutRetriesThenFail(t *testing.T) {
 	}
 
 	calls := 0
-	exchangeFunc = func(c *dns.Client, m *dns.Msg, addr string) (*dns.Msg, time.Duration, error) {
+	exchangeFunc = func(ctx context.Context, c *dns.Client, m *dns.Msg, addr string) (*dns.Msg, time.Duration, error) {
 		calls++
 		return nil, 0, timeoutError{}
 	}
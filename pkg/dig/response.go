@@ -0,0 +1,140 @@
+package dig
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// maxCNAMEHops bounds how many CNAMEs Resolve follows.
+const maxCNAMEHops = 8
+
+// Record is a single resource record from an answer section.
+type Record struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	TTL   uint32 `json:"ttl"`
+	Value string `json:"value"`
+}
+
+// Response is the outcome of Resolve. Answer holds every record received
+// while following the CNAME chain, in order.
+type Response struct {
+	Name          string   `json:"name"`
+	Type          string   `json:"type"`
+	Rcode         int      `json:"rcode"`
+	Authoritative bool     `json:"authoritative"`
+	Answer        []Record `json:"answer,omitempty"`
+	CNAMEChain    []string `json:"cname_chain,omitempty"`
+}
+
+func (r *Response) RcodeString() string {
+	return dns.RcodeToString[r.Rcode]
+}
+
+// NXDomain reports whether the final name in the chain does not exist.
+func (r *Response) NXDomain() bool {
+	return r.Rcode == dns.RcodeNameError
+}
+
+// NoData reports whether the final name exists but has no records of the
+// queried type.
+func (r *Response) NoData() bool {
+	return r.Rcode == dns.RcodeSuccess && len(r.Values()) == 0
+}
+
+// Target returns the name the CNAME chain ends at, or Name when there is no
+// chain.
+func (r *Response) Target() string {
+	if len(r.CNAMEChain) == 0 {
+		return r.Name
+	}
+	return r.CNAMEChain[len(r.CNAMEChain)-1]
+}
+
+// Values returns the data of the records of the queried type.
+func (r *Response) Values() []string {
+	values := []string{}
+	for _, rr := range r.Answer {
+		if rr.Type == r.Type {
+			values = append(values, rr.Value)
+		}
+	}
+	return values
+}
+
+// MinTTL returns the smallest TTL in the answer, 0 when it is empty.
+func (r *Response) MinTTL() uint32 {
+	var ttl uint32
+	for i, rr := range r.Answer {
+		if i == 0 || rr.TTL < ttl {
+			ttl = rr.TTL
+		}
+	}
+	return ttl
+}
+
+// Evidence describes how the answer was reached, one line per CNAME hop
+// followed by the outcome for the final name.
+func (r *Response) Evidence() []string {
+	lines := []string{}
+	name := r.Name
+	for _, target := range r.CNAMEChain {
+		lines = append(lines, fmt.Sprintf("%s CNAME %s", name, target))
+		name = target
+	}
+	switch {
+	case r.Rcode != dns.RcodeSuccess:
+		lines = append(lines, fmt.Sprintf("%s %s %s", name, r.Type, r.RcodeString()))
+	case r.NoData():
+		lines = append(lines, fmt.Sprintf("%s %s NODATA", name, r.Type))
+	default:
+		lines = append(lines, fmt.Sprintf("%s %s %s", name, r.Type, strings.Join(r.Values(), ",")))
+	}
+	return lines
+}
+
+// add merges a message received while resolving r.Name into r.
+func (r *Response) add(m *dns.Msg) {
+	r.Rcode = m.Rcode
+	r.Authoritative = m.Authoritative
+	for _, rr := range m.Answer {
+		h := rr.Header()
+		r.Answer = append(r.Answer, Record{
+			Name:  h.Name,
+			Type:  dns.TypeToString[h.Rrtype],
+			TTL:   h.Ttl,
+			Value: strings.TrimPrefix(rr.String(), h.String()),
+		})
+	}
+
+	r.CNAMEChain = r.CNAMEChain[:0]
+	name := r.Name
+	for hop := 0; hop < maxCNAMEHops; hop++ {
+		next := ""
+		for _, rr := range r.Answer {
+			if rr.Type == "CNAME" && strings.EqualFold(rr.Name, name) {
+				next = rr.Value
+				break
+			}
+		}
+		if next == "" {
+			break
+		}
+		r.CNAMEChain = append(r.CNAMEChain, next)
+		name = next
+	}
+}
+
+// answered reports whether the answer holds records of the queried type for
+// the end of the chain.
+func (r *Response) answered() bool {
+	target := r.Target()
+	for _, rr := range r.Answer {
+		if rr.Type == r.Type && strings.EqualFold(rr.Name, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package dig
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/require"
+)
+
+func cname(name, target string) dns.RR {
+	return &dns.CNAME{Hdr: dns.RR_Header{Name: name, Rrtype: dns.TypeCNAME, Class: dns.ClassINET, Ttl: 300}, Target: target}
+}
+
+func a(name, ip string, ttl uint32) dns.RR {
+	return &dns.A{Hdr: dns.RR_Header{Name: name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: ttl}, A: net.ParseIP(ip)}
+}
+
+func TestResolve_FullChainInOneAnswer(t *testing.T) {
+	oldEx := exchangeFunc
+	t.Cleanup(func() { exchangeFunc = oldEx; Configure(DefaultConfig()) })
+	Configure(Config{Servers: []string{"10.0.0.2"}})
+
+	exchangeFunc = func(ctx context.Context, c *dns.Client, m *dns.Msg, addr string) (*dns.Msg, time.Duration, error) {
+		msg := new(dns.Msg)
+		msg.SetReply(m)
+		msg.Answer = []dns.RR{
+			cname("www.example.com.", "lb.example.net."),
+			a("lb.example.net.", "192.0.2.1", 60),
+			a("lb.example.net.", "192.0.2.2", 30),
+		}
+		return msg, 0, nil
+	}
+
+	resp, err := Resolve(context.Background(), "www.example.com", "A")
+	require.NoError(t, err)
+	require.Equal(t, []string{"lb.example.net."}, resp.CNAMEChain)
+	require.Equal(t, "lb.example.net.", resp.Target())
+	require.Equal(t, []string{"192.0.2.1", "192.0.2.2"}, resp.Values())
+	require.Equal(t, uint32(30), resp.MinTTL())
+	require.False(t, resp.NoData())
+}
+
+func TestResolve_ChasesIncompleteChain(t *testing.T) {
+	oldEx := exchangeFunc
+	t.Cleanup(func() { exchangeFunc = oldEx; Configure(DefaultConfig()) })
+	Configure(Config{Servers: []string{"10.0.0.2"}})
+
+	exchangeFunc = func(ctx context.Context, c *dns.Client, m *dns.Msg, addr string) (*dns.Msg, time.Duration, error) {
+		msg := new(dns.Msg)
+		msg.SetReply(m)
+		switch m.Question[0].Name {
+		case "www.example.com.":
+			msg.Answer = []dns.RR{cname("www.example.com.", "gone.example.net.")}
+		default:
+			msg.Rcode = dns.RcodeNameError
+			msg.Authoritative = true
+		}
+		return msg, 0, nil
+	}
+
+	resp, err := Resolve(context.Background(), "www.example.com", "A")
+	var rerr *ResolveError
+	require.ErrorAs(t, err, &rerr)
+	require.Equal(t, "NXDOMAIN", rerr.Type)
+	require.Equal(t, "failed to resolve www.example.com: NXDOMAIN", err.Error())
+	require.True(t, resp.NXDomain())
+	require.True(t, resp.Authoritative)
+	require.Equal(t, []string{
+		"www.example.com. CNAME gone.example.net.",
+		"gone.example.net. A NXDOMAIN",
+	}, resp.Evidence())
+}
+
+func TestResolve_NoData(t *testing.T) {
+	oldEx := exchangeFunc
+	t.Cleanup(func() { exchangeFunc = oldEx; Configure(DefaultConfig()) })
+	Configure(Config{Servers: []string{"10.0.0.2"}})
+
+	exchangeFunc = func(ctx context.Context, c *dns.Client, m *dns.Msg, addr string) (*dns.Msg, time.Duration, error) {
+		msg := new(dns.Msg)
+		msg.SetReply(m)
+		return msg, 0, nil
+	}
+
+	resp, err := Resolve(context.Background(), "example.com", "AAAA")
+	require.NoError(t, err)
+	require.True(t, resp.NoData())
+	require.False(t, resp.NXDomain())
+	require.Equal(t, []string{"example.com. AAAA NODATA"}, resp.Evidence())
+}
@@ -0,0 +1,130 @@
+package dig
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// Transport sends a single DNS message to one server.
+type Transport interface {
+	Exchange(ctx context.Context, m *dns.Msg) (*dns.Msg, error)
+	String() string
+}
+
+// newTransport builds the transport for a configured server entry:
+//
+//	host, host:port, udp://host  plain DNS over UDP, retried over TCP when truncated
+//	tcp://host[:port]            plain DNS over TCP
+//	tls://host[:port]            DNS over TLS (RFC 7858), port 853 by default
+//	https://host/path            DNS over HTTPS (RFC 8484)
+func newTransport(server, defaultPort string, timeout time.Duration) (Transport, error) {
+	scheme, rest, found := strings.Cut(server, "://")
+	if !found {
+		scheme, rest = "udp", server
+	}
+
+	switch scheme {
+	case "udp":
+		return &classicTransport{addr: withPort(rest, defaultPort), timeout: timeout}, nil
+	case "tcp":
+		return &classicTransport{addr: withPort(rest, "53"), net: "tcp", timeout: timeout}, nil
+	case "tls":
+		addr := withPort(rest, "853")
+		host, _, _ := net.SplitHostPort(addr)
+		return &classicTransport{addr: addr, net: "tcp-tls", timeout: timeout, tlsConfig: &tls.Config{ServerName: host}}, nil
+	case "https":
+		return &dohTransport{url: server, timeout: timeout}, nil
+	}
+	return nil, fmt.Errorf("unsupported resolver transport: %s", server)
+}
+
+// classicTransport speaks RFC 1035 DNS over UDP, TCP or TLS.
+type classicTransport struct {
+	addr      string
+	net       string
+	timeout   time.Duration
+	tlsConfig *tls.Config
+}
+
+func (t *classicTransport) Exchange(ctx context.Context, m *dns.Msg) (*dns.Msg, error) {
+	c := &dns.Client{Net: t.net, Timeout: t.timeout, TLSConfig: t.tlsConfig}
+	r, _, err := exchangeFunc(ctx, c, m, t.addr)
+	if err == nil && r.Truncated && t.net == "" {
+		tcp := &dns.Client{Net: "tcp", Timeout: t.timeout}
+		r, _, err = exchangeFunc(ctx, tcp, m, t.addr)
+	}
+	return r, err
+}
+
+func (t *classicTransport) String() string {
+	switch t.net {
+	case "tcp":
+		return "tcp://" + t.addr
+	case "tcp-tls":
+		return "tls://" + t.addr
+	}
+	return t.addr
+}
+
+// dohClient is the HTTP client used for DNS over HTTPS. It is a seam for tests.
+var dohClient = &http.Client{}
+
+const dnsMessageType = "application/dns-message"
+
+// dohTransport speaks DNS over HTTPS using the POST method of RFC 8484.
+type dohTransport struct {
+	url     string
+	timeout time.Duration
+}
+
+func (t *dohTransport) Exchange(ctx context.Context, m *dns.Msg) (*dns.Msg, error) {
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+
+	// RFC 8484 asks for ID 0 so answers are cache friendly.
+	q := m.Copy()
+	q.Id = 0
+	body, err := q.Pack()
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", dnsMessageType)
+	req.Header.Set("Accept", dnsMessageType)
+
+	resp, err := dohClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("doh server %s returned %s", t.url, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, dns.MaxMsgSize))
+	if err != nil {
+		return nil, err
+	}
+
+	r := &dns.Msg{}
+	if err := r.Unpack(data); err != nil {
+		return nil, err
+	}
+	r.Id = m.Id
+	return r, nil
+}
+
+func (t *dohTransport) String() string { return t.url }
@@ -0,0 +1,102 @@
+package dig
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTransport(t *testing.T) {
+	cases := map[string]string{
+		"10.0.0.2":                          "10.0.0.2:53",
+		"udp://10.0.0.2:5353":               "10.0.0.2:5353",
+		"tcp://10.0.0.2":                    "tcp://10.0.0.2:53",
+		"tls://dns.example.net":             "tls://dns.example.net:853",
+		"https://dns.example.net/dns-query": "https://dns.example.net/dns-query",
+	}
+	for in, want := range cases {
+		tr, err := newTransport(in, "53", time.Second)
+		require.NoError(t, err, in)
+		require.Equal(t, want, tr.String(), in)
+	}
+
+	_, err := newTransport("quic://10.0.0.2", "53", time.Second)
+	require.Error(t, err)
+}
+
+func TestClassicTransport_HonoursContext(t *testing.T) {
+	// A server that reads queries and never answers.
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	tr, err := newTransport(conn.LocalAddr().String(), "53", time.Minute)
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err = tr.Exchange(ctx, new(dns.Msg).SetQuestion("example.com.", dns.TypeA))
+	require.Error(t, err)
+	require.Less(t, time.Since(start), 5*time.Second, "the context deadline stops the query, not the transport timeout")
+}
+
+func TestTLSTransport_SetsServerName(t *testing.T) {
+	oldEx := exchangeFunc
+	t.Cleanup(func() { exchangeFunc = oldEx })
+
+	var got *dns.Client
+	exchangeFunc = func(ctx context.Context, c *dns.Client, m *dns.Msg, addr string) (*dns.Msg, time.Duration, error) {
+		got = c
+		msg := new(dns.Msg)
+		msg.SetReply(m)
+		return msg, 0, nil
+	}
+
+	tr, err := newTransport("tls://dns.example.net", "53", time.Second)
+	require.NoError(t, err)
+	m := new(dns.Msg)
+	m.SetQuestion("example.com.", dns.TypeA)
+	_, err = tr.Exchange(context.Background(), m)
+	require.NoError(t, err)
+	require.Equal(t, "tcp-tls", got.Net)
+	require.Equal(t, "dns.example.net", got.TLSConfig.ServerName)
+}
+
+func TestDoHTransport_Exchange(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, dnsMessageType, r.Header.Get("Content-Type"))
+
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		q := new(dns.Msg)
+		require.NoError(t, q.Unpack(body))
+		require.Equal(t, uint16(0), q.Id)
+
+		resp := new(dns.Msg)
+		resp.SetRcode(q, dns.RcodeNameError)
+		out, err := resp.Pack()
+		require.NoError(t, err)
+		w.Header().Set("Content-Type", dnsMessageType)
+		_, _ = w.Write(out)
+	}))
+	defer srv.Close()
+
+	oldClient := dohClient
+	t.Cleanup(func() { dohClient = oldClient; Configure(DefaultConfig()) })
+	dohClient = srv.Client()
+	Configure(Config{Servers: []string{srv.URL + "/dns-query"}})
+
+	_, err := realResolver{}.Resolve(context.Background(), "missing.example.com", "A")
+	var rerr *ResolveError
+	require.ErrorAs(t, err, &rerr)
+	require.Equal(t, "NXDOMAIN", rerr.Type)
+}
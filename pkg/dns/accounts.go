@@ -0,0 +1,85 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
+	otypes "github.com/aws/aws-sdk-go-v2/service/organizations/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+const assumeRoleSessionName = "r53tool"
+
+// Account is an AWS account reached by assuming RoleARN.
+type Account struct {
+	ID      string
+	Name    string
+	RoleARN string
+}
+
+// AccountFromRoleARN returns the account a role ARN belongs to.
+func AccountFromRoleARN(roleARN string) (Account, error) {
+	a, err := arn.Parse(roleARN)
+	if err != nil {
+		return Account{}, fmt.Errorf("invalid role ARN %q: %w", roleARN, err)
+	}
+	return Account{ID: a.AccountID, RoleARN: roleARN}, nil
+}
+
+// ListOrganizationAccounts lists the active accounts of the organization the
+// credentials in o belong to, with the ARN of roleName in each. The profile must be
+// allowed to call organizations:ListAccounts, usually from the management
+// account.
+func ListOrganizationAccounts(ctx context.Context, o AWSOptions, roleName string) ([]Account, error) {
+	cfg, err := LoadAWSConfig(ctx, o)
+	if err != nil {
+		return nil, err
+	}
+
+	paginator := organizations.NewListAccountsPaginator(organizations.NewFromConfig(cfg, func(oo *organizations.Options) {
+		if ep := o.endpoint(ServiceOrganizations); ep != nil {
+			oo.BaseEndpoint = ep
+		}
+	}), &organizations.ListAccountsInput{})
+	accounts := []Account{}
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range page.Accounts {
+			if a.Status != otypes.AccountStatusActive {
+				continue
+			}
+			partition := "aws"
+			if parsed, err := arn.Parse(aws.ToString(a.Arn)); err == nil {
+				partition = parsed.Partition
+			}
+			accounts = append(accounts, Account{
+				ID:      aws.ToString(a.Id),
+				Name:    aws.ToString(a.Name),
+				RoleARN: fmt.Sprintf("arn:%s:iam::%s:role/%s", partition, aws.ToString(a.Id), roleName),
+			})
+		}
+	}
+	return accounts, nil
+}
+
+// assumeRole makes cfg use temporary credentials for roleARN, obtained with
+// cfg's own credentials from STS at stsEndpoint, if set.
+func assumeRole(cfg aws.Config, roleARN string, stsEndpoint *string) aws.Config {
+	client := sts.NewFromConfig(cfg, func(so *sts.Options) {
+		if stsEndpoint != nil {
+			so.BaseEndpoint = stsEndpoint
+		}
+	})
+	provider := stscreds.NewAssumeRoleProvider(client, roleARN, func(o *stscreds.AssumeRoleOptions) {
+		o.RoleSessionName = assumeRoleSessionName
+	})
+	cfg.Credentials = aws.NewCredentialsCache(provider)
+	return cfg
+}
@@ -0,0 +1,16 @@
+package dns
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountFromRoleARN(t *testing.T) {
+	a, err := AccountFromRoleARN("arn:aws:iam::123456789012:role/dns-audit")
+	require.NoError(t, err)
+	require.Equal(t, Account{ID: "123456789012", RoleARN: "arn:aws:iam::123456789012:role/dns-audit"}, a)
+
+	_, err = AccountFromRoleARN("dns-audit")
+	require.Error(t, err)
+}
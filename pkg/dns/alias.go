@@ -0,0 +1,209 @@
+package dns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	rtypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
+)
+
+// AliasMode selects how Route53 alias records are exported.
+type AliasMode string
+
+const (
+	// AliasR53 keeps aliases as dnscontrol R53_ALIAS pseudo-records.
+	AliasR53 AliasMode = "r53_alias"
+	// AliasFlatten replaces aliases with the records their target resolves to.
+	AliasFlatten AliasMode = "flatten"
+	// AliasCNAME replaces non-apex aliases with a CNAME to their target.
+	AliasCNAME AliasMode = "cname"
+)
+
+// AliasModes lists the accepted alias modes.
+var AliasModes = []AliasMode{AliasR53, AliasFlatten, AliasCNAME}
+
+// AliasTTL is the TTL given to CNAMEs that replace an alias, which has none.
+const AliasTTL = 300
+
+// maxAliasHops bounds how many in-zone aliases are followed when flattening.
+const maxAliasHops = 8
+
+// ParseAliasMode returns the AliasMode named s.
+func ParseAliasMode(s string) (AliasMode, error) {
+	for _, m := range AliasModes {
+		if string(m) == strings.ToLower(s) {
+			return m, nil
+		}
+	}
+	return "", fmt.Errorf("unknown alias mode %q, expected one of %v", s, AliasModes)
+}
+
+// AliasResolver looks up the values and TTL of the rtype records at name.
+// An empty result means the name has no such records.
+type AliasResolver func(ctx context.Context, name, rtype string) ([]string, uint32, error)
+
+// ResolveAliases rewrites the alias record sets of zone according to mode.
+// Aliases that cannot be rewritten, like a CNAME at the apex, are left as
+// they are and exported as R53_ALIAS.
+func ResolveAliases(ctx context.Context, zone string, records []rtypes.ResourceRecordSet, mode AliasMode, resolve AliasResolver) ([]rtypes.ResourceRecordSet, error) {
+	switch mode {
+	case AliasR53, "":
+		return records, nil
+	case AliasCNAME:
+		return aliasesToCNAME(zone, records), nil
+	case AliasFlatten:
+		if resolve == nil {
+			return nil, errors.New("flattening aliases needs a resolver")
+		}
+		return flattenAliases(ctx, records, resolve)
+	}
+	return nil, fmt.Errorf("unknown alias mode %q", mode)
+}
+
+func aliasesToCNAME(zone string, records []rtypes.ResourceRecordSet) []rtypes.ResourceRecordSet {
+	apex := strings.ToLower(NormalizeDomain(zone))
+	byName := map[string][]rtypes.ResourceRecordSet{}
+	for _, rs := range records {
+		name := strings.ToLower(aws.ToString(rs.Name))
+		byName[name] = append(byName[name], rs)
+	}
+
+	// A name can take a CNAME when it only holds aliases to one target.
+	targets := map[string]string{}
+	for name, sets := range byName {
+		target := ""
+		for _, rs := range sets {
+			if rs.AliasTarget == nil {
+				target = ""
+				break
+			}
+			t := strings.ToLower(aws.ToString(rs.AliasTarget.DNSName))
+			if target != "" && target != t {
+				target = ""
+				break
+			}
+			target = t
+		}
+		switch {
+		case target == "":
+			continue
+		case name == apex:
+			log.Printf("Keeping alias at the apex %s, a CNAME is not allowed there\n", name)
+		default:
+			targets[name] = target
+		}
+	}
+
+	out := []rtypes.ResourceRecordSet{}
+	done := map[string]bool{}
+	for _, rs := range records {
+		name := strings.ToLower(aws.ToString(rs.Name))
+		target, ok := targets[name]
+		if !ok {
+			if rs.AliasTarget != nil && name != apex {
+				log.Printf("Keeping alias %s %s, the name holds other records\n", aws.ToString(rs.Name), rs.Type)
+			}
+			out = append(out, rs)
+			continue
+		}
+		if done[name] {
+			continue
+		}
+		done[name] = true
+		out = append(out, rtypes.ResourceRecordSet{
+			Name:            rs.Name,
+			Type:            rtypes.RRTypeCname,
+			TTL:             aws.Int64(AliasTTL),
+			ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String(target)}},
+		})
+	}
+	return out
+}
+
+type aliasKey struct {
+	name  string
+	rtype rtypes.RRType
+}
+
+func flattenAliases(ctx context.Context, records []rtypes.ResourceRecordSet, resolve AliasResolver) ([]rtypes.ResourceRecordSet, error) {
+	index := map[aliasKey][]rtypes.ResourceRecordSet{}
+	for _, rs := range records {
+		k := aliasKey{strings.ToLower(aws.ToString(rs.Name)), rs.Type}
+		index[k] = append(index[k], rs)
+	}
+
+	out := []rtypes.ResourceRecordSet{}
+	for _, rs := range records {
+		if rs.AliasTarget == nil {
+			out = append(out, rs)
+			continue
+		}
+		values, ttl, err := flattenAlias(ctx, index, rs.Type, aws.ToString(rs.AliasTarget.DNSName), resolve, 0)
+		if err != nil {
+			return nil, fmt.Errorf("flattening %s %s: %w", aws.ToString(rs.Name), rs.Type, err)
+		}
+		if len(values) == 0 {
+			log.Printf("Keeping alias %s %s, %s has no %s records\n",
+				aws.ToString(rs.Name), rs.Type, aws.ToString(rs.AliasTarget.DNSName), rs.Type)
+			out = append(out, rs)
+			continue
+		}
+
+		flat := rs
+		flat.AliasTarget = nil
+		flat.TTL = aws.Int64(int64(ttl))
+		flat.ResourceRecords = nil
+		for _, v := range values {
+			flat.ResourceRecords = append(flat.ResourceRecords, rtypes.ResourceRecord{Value: aws.String(v)})
+		}
+		out = append(out, flat)
+	}
+	return out, nil
+}
+
+// flattenAlias returns the values target holds for rtype. Targets inside
+// the zone are read from the exported records, following aliases between
+// them, anything else is resolved.
+func flattenAlias(ctx context.Context, index map[aliasKey][]rtypes.ResourceRecordSet, rtype rtypes.RRType, target string, resolve AliasResolver, hops int) ([]string, uint32, error) {
+	if hops > maxAliasHops {
+		return nil, 0, fmt.Errorf("more than %d aliases to follow from %s", maxAliasHops, target)
+	}
+	sets, ok := index[aliasKey{strings.ToLower(NormalizeDomain(target)), rtype}]
+	if !ok {
+		return resolve(ctx, target, string(rtype))
+	}
+
+	values := []string{}
+	var ttl uint32
+	seen := map[string]bool{}
+	for _, rs := range sets {
+		var vs []string
+		var t uint32
+		if rs.AliasTarget != nil {
+			var err error
+			vs, t, err = flattenAlias(ctx, index, rtype, aws.ToString(rs.AliasTarget.DNSName), resolve, hops+1)
+			if err != nil {
+				return nil, 0, err
+			}
+		} else {
+			t = uint32(aws.ToInt64(rs.TTL))
+			for _, rr := range rs.ResourceRecords {
+				vs = append(vs, aws.ToString(rr.Value))
+			}
+		}
+		if len(vs) > 0 && (ttl == 0 || t < ttl) {
+			ttl = t
+		}
+		for _, v := range vs {
+			if !seen[v] {
+				seen[v] = true
+				values = append(values, v)
+			}
+		}
+	}
+	return values, ttl, nil
+}
@@ -0,0 +1,119 @@
+package dns
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	rtypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/stretchr/testify/require"
+)
+
+func alias(name string, t rtypes.RRType, target string) rtypes.ResourceRecordSet {
+	return rtypes.ResourceRecordSet{
+		Name: aws.String(name),
+		Type: t,
+		AliasTarget: &rtypes.AliasTarget{
+			HostedZoneId: aws.String("Z2FDTNDATAQYW2"),
+			DNSName:      aws.String(target),
+		},
+	}
+}
+
+func plain(name string, t rtypes.RRType, ttl int64, values ...string) rtypes.ResourceRecordSet {
+	rs := rtypes.ResourceRecordSet{Name: aws.String(name), Type: t, TTL: aws.Int64(ttl)}
+	for _, v := range values {
+		rs.ResourceRecords = append(rs.ResourceRecords, rtypes.ResourceRecord{Value: aws.String(v)})
+	}
+	return rs
+}
+
+func TestParseAliasMode(t *testing.T) {
+	m, err := ParseAliasMode("CNAME")
+	require.NoError(t, err)
+	require.Equal(t, AliasCNAME, m)
+	_, err = ParseAliasMode("bogus")
+	require.ErrorContains(t, err, `unknown alias mode "bogus"`)
+}
+
+func TestResolveAliases_CNAME(t *testing.T) {
+	records := []rtypes.ResourceRecordSet{
+		alias("example.com.", rtypes.RRTypeA, "d111.cloudfront.net."),
+		alias("www.example.com.", rtypes.RRTypeA, "d111.cloudfront.net."),
+		alias("www.example.com.", rtypes.RRTypeAaaa, "D111.cloudfront.net."),
+		alias("api.example.com.", rtypes.RRTypeA, "lb.elb.amazonaws.com."),
+		plain("api.example.com.", rtypes.RRTypeTxt, 300, `"v=1"`),
+	}
+	got, err := ResolveAliases(context.Background(), "example.com", records, AliasCNAME, nil)
+	require.NoError(t, err)
+	require.Equal(t, []rtypes.ResourceRecordSet{
+		// The apex and names with other records keep their alias.
+		records[0],
+		plain("www.example.com.", rtypes.RRTypeCname, AliasTTL, "d111.cloudfront.net."),
+		records[3],
+		records[4],
+	}, got)
+}
+
+func TestResolveAliases_Flatten(t *testing.T) {
+	records := []rtypes.ResourceRecordSet{
+		alias("example.com.", rtypes.RRTypeA, "www.example.com."),
+		alias("www.example.com.", rtypes.RRTypeA, "d111.cloudfront.net."),
+		alias("www.example.com.", rtypes.RRTypeAaaa, "d111.cloudfront.net."),
+		plain("mail.example.com.", rtypes.RRTypeA, 3600, "192.0.2.25"),
+		alias("smtp.example.com.", rtypes.RRTypeA, "mail.example.com"),
+		alias("gone.example.com.", rtypes.RRTypeA, "gone.elb.amazonaws.com."),
+	}
+	lookups := []string{}
+	resolve := func(ctx context.Context, name, rtype string) ([]string, uint32, error) {
+		lookups = append(lookups, name+" "+rtype)
+		switch {
+		case name == "d111.cloudfront.net." && rtype == "A":
+			return []string{"192.0.2.1", "192.0.2.2"}, 60, nil
+		case name == "d111.cloudfront.net." && rtype == "AAAA":
+			return []string{"2001:db8::1"}, 60, nil
+		}
+		return nil, 0, nil
+	}
+
+	got, err := ResolveAliases(context.Background(), "example.com", records, AliasFlatten, resolve)
+	require.NoError(t, err)
+	require.Equal(t, []rtypes.ResourceRecordSet{
+		plain("example.com.", rtypes.RRTypeA, 60, "192.0.2.1", "192.0.2.2"),
+		plain("www.example.com.", rtypes.RRTypeA, 60, "192.0.2.1", "192.0.2.2"),
+		plain("www.example.com.", rtypes.RRTypeAaaa, 60, "2001:db8::1"),
+		records[3],
+		// In-zone targets are read from the records, not resolved.
+		plain("smtp.example.com.", rtypes.RRTypeA, 3600, "192.0.2.25"),
+		// Targets without records stay aliases.
+		records[5],
+	}, got)
+	require.NotContains(t, lookups, "mail.example.com A")
+}
+
+func TestResolveAliases_FlattenLoop(t *testing.T) {
+	records := []rtypes.ResourceRecordSet{
+		alias("a.example.com.", rtypes.RRTypeA, "b.example.com."),
+		alias("b.example.com.", rtypes.RRTypeA, "a.example.com."),
+	}
+	_, err := ResolveAliases(context.Background(), "example.com", records, AliasFlatten,
+		func(context.Context, string, string) ([]string, uint32, error) { return nil, 0, nil })
+	require.ErrorContains(t, err, "aliases to follow")
+}
+
+func TestWriteBindZoneFile_R53Alias(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "example.com.zone")
+	require.NoError(t, WriteBindZoneFile(out, "example.com.", []rtypes.ResourceRecordSet{
+		plain("www.example.com.", rtypes.RRTypeA, 600, "192.0.2.1"),
+		alias("example.com.", rtypes.RRTypeA, "d111.cloudfront.net"),
+	}))
+	b, err := os.ReadFile(out)
+	require.NoError(t, err)
+	zone := string(b)
+	require.Contains(t, zone, "$TTL 600")
+	require.Contains(t, zone, "1 AWS Alias records are kept")
+	require.Regexp(t, `;@\s+IN R53_ALIAS d111\.cloudfront\.net\. atype=A zone_id=Z2FDTNDATAQYW2 evaluate_target_health=false`, zone)
+	require.Regexp(t, `www\s+IN A\s+192\.0\.2\.1`, zone)
+}
@@ -2,8 +2,10 @@ package dns
 
 import (
 	"fmt"
+	"io"
 	"os"
-	"sort"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/StackExchange/dnscontrol/v4/models"
@@ -15,41 +17,53 @@ import (
 // WriteBindZoneFile writes a BIND 9-compatible zone file at outputPath for the given zone and record sets.
 // - zone should be the zone/apex name (with or without trailing dot). It will be normalized automatically.
 // - records are the Route53 ResourceRecordSets to export.
-// Unsupported AWS-specific constructs (like AliasTarget) are skipped and added as comments at the top.
+// AliasTarget records are written as commented-out R53_ALIAS lines in dnscontrol syntax; use
+// ResolveAliases first to turn them into plain records. Record sets with a routing policy or
+// health check are also listed in full as R53_SET comments, which ReadZone reads back.
 func WriteBindZoneFile(outputPath string, zone string, records []rtypes.ResourceRecordSet) error {
+	// Create/overwrite the output file
+	f, err := os.Create(outputPath)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	return writeBind(f, zone, records)
+}
+
+func writeBind(w io.Writer, zone string, records []rtypes.ResourceRecordSet) error {
 	origin := DenormalizeDomain(zone) // origin without trailing dot
 
 	recs, comments := route53ToModelRecords(origin, records)
 
-	// Compute a reasonable default TTL (most common across records, excluding NS per dnscontrol's logic)
-	defaultTTL := prettyzone.MostCommonTTL(recs)
+	// Compute a reasonable default TTL (most common across records, excluding NS per dnscontrol's logic).
+	// Aliases have no TTL and are left out.
+	defaultTTL := prettyzone.MostCommonTTL(slices.DeleteFunc(slices.Clone(recs), isR53Alias))
 
 	// Ensure stable/pretty order (optional but produces nicer output)
 	_ = prettyzone.PrettySort(recs, origin, defaultTTL, comments)
 
-	// Create/overwrite the output file
-	f, err := os.Create(outputPath)
-	if err != nil {
-		return err
-	}
-	defer func() { _ = f.Close() }()
-
 	// Write the zone file
-	if err := prettyzone.WriteZoneFileRC(f, recs, origin, defaultTTL, comments); err != nil {
-		return err
-	}
-	return nil
+	return prettyzone.WriteZoneFileRC(w, recs, origin, defaultTTL, comments)
 }
 
 func route53ToModelRecords(origin string, rs []rtypes.ResourceRecordSet) (models.Records, []string) {
 	records := models.Records{}
-	comments := []string{fmt.Sprintf("; Exported by r53tool. Zone: %s", origin)}
-	skippedAlias := []string{}
+	comments := []string{"; " + bindHeader + origin}
+	aliases := 0
+	routed := []string{}
 
 	for _, rrset := range rs {
-		// Skip AWS AliasTarget records (not representable in BIND zone files in many cases)
+		// Routing policies and health checks have no BIND syntax. The records
+		// are still written, the full set is kept in a comment for import.
+		if hasRouting(rrset) || rrset.HealthCheckId != nil {
+			routed = append(routed, routedSetComment(rrset))
+		}
+
+		// AWS AliasTarget records have no BIND equivalent, keep them as dnscontrol pseudo-records
 		if rrset.AliasTarget != nil {
-			skippedAlias = append(skippedAlias, fmt.Sprintf("%s %s -> %s", aws.ToString(rrset.Name), rrset.Type, aws.ToString(rrset.AliasTarget.DNSName)))
+			records = append(records, aliasToModelRecord(origin, rrset))
+			aliases++
 			continue
 		}
 
@@ -113,6 +127,15 @@ func route53ToModelRecords(origin string, rs []rtypes.ResourceRecordSet) (models
 				_ = rc.PopulateFromString(rtype, models.StripQuotes(val), origin)
 			}
 
+			// Route53 names are fully qualified even without the trailing dot,
+			// keep CanonicalizeTargets from appending the origin to them.
+			switch rtype {
+			case "CNAME", "NS", "PTR", "MX", "SRV":
+				if t := rc.GetTargetField(); !strings.HasSuffix(t, ".") {
+					_ = rc.SetTarget(t + ".")
+				}
+			}
+
 			records = append(records, rc)
 		}
 	}
@@ -120,14 +143,31 @@ func route53ToModelRecords(origin string, rs []rtypes.ResourceRecordSet) (models
 	// Post-processing: canonicalize targets (turn relative into FQDNs)
 	models.CanonicalizeTargets(records, origin)
 
-	// Add comments for skipped alias records (sorted for stability)
-	if len(skippedAlias) > 0 {
-		sort.Strings(skippedAlias)
-		comments = append(comments, "; NOTE: The following AWS Alias records were skipped (not supported in BIND):")
-		for _, s := range skippedAlias {
-			comments = append(comments, ";   "+s)
-		}
+	if aliases > 0 {
+		comments = append(comments, fmt.Sprintf("; NOTE: %d AWS Alias records are kept as commented R53_ALIAS lines (dnscontrol syntax).", aliases))
+	}
+	if len(routed) > 0 {
+		comments = append(comments, fmt.Sprintf("; NOTE: %d record sets with routing policies or health checks are listed as %s lines (JSON); their records below are merged.", len(routed), routedSetTag))
+		comments = append(comments, routed...)
 	}
 
 	return records, comments
 }
+
+func aliasToModelRecord(origin string, rrset rtypes.ResourceRecordSet) *models.RecordConfig {
+	rc := &models.RecordConfig{
+		Type: "R53_ALIAS",
+		R53Alias: map[string]string{
+			"type":                   string(rrset.Type),
+			"zone_id":                aws.ToString(rrset.AliasTarget.HostedZoneId),
+			"evaluate_target_health": strconv.FormatBool(rrset.AliasTarget.EvaluateTargetHealth),
+		},
+	}
+	rc.SetLabelFromFQDN(aws.ToString(rrset.Name), origin)
+	_ = rc.SetTarget(NormalizeDomain(aws.ToString(rrset.AliasTarget.DNSName)))
+	return rc
+}
+
+func isR53Alias(rc *models.RecordConfig) bool {
+	return rc.Type == "R53_ALIAS"
+}
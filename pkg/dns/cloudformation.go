@@ -0,0 +1,167 @@
+package dns
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	rtypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
+)
+
+var cfnIDUnsafe = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+type cfnTemplate struct {
+	AWSTemplateFormatVersion string
+	Description              string
+	Resources                map[string]cfnResource
+}
+
+type cfnResource struct {
+	Type       string
+	Properties cfnRecordSet
+}
+
+type cfnRecordSet struct {
+	HostedZoneId         string
+	Name                 string
+	Type                 string
+	TTL                  string           `json:",omitempty"`
+	ResourceRecords      []string         `json:",omitempty"`
+	AliasTarget          *cfnAliasTarget  `json:",omitempty"`
+	SetIdentifier        string           `json:",omitempty"`
+	Weight               *int64           `json:",omitempty"`
+	Region               string           `json:",omitempty"`
+	Failover             string           `json:",omitempty"`
+	GeoLocation          *cfnGeoLocation  `json:",omitempty"`
+	GeoProximityLocation *cfnGeoProximity `json:",omitempty"`
+	CidrRoutingConfig    *cfnCidrRouting  `json:",omitempty"`
+	MultiValueAnswer     *bool            `json:",omitempty"`
+	HealthCheckId        string           `json:",omitempty"`
+}
+
+type cfnAliasTarget struct {
+	DNSName              string
+	HostedZoneId         string
+	EvaluateTargetHealth bool
+}
+
+type cfnGeoLocation struct {
+	ContinentCode   string `json:",omitempty"`
+	CountryCode     string `json:",omitempty"`
+	SubdivisionCode string `json:",omitempty"`
+}
+
+type cfnGeoProximity struct {
+	AWSRegion      string          `json:",omitempty"`
+	LocalZoneGroup string          `json:",omitempty"`
+	Bias           *int32          `json:",omitempty"`
+	Coordinates    *cfnCoordinates `json:",omitempty"`
+}
+
+type cfnCoordinates struct {
+	Latitude  string
+	Longitude string
+}
+
+type cfnCidrRouting struct {
+	CollectionId string
+	LocationName string
+}
+
+// writeCloudFormation renders a template with one AWS::Route53::RecordSet
+// per record set.
+func writeCloudFormation(w io.Writer, zone rtypes.HostedZone, records []rtypes.ResourceRecordSet) error {
+	origin := DenormalizeDomain(strings.ToLower(aws.ToString(zone.Name)))
+	zoneID := strings.TrimPrefix(aws.ToString(zone.Id), "/hostedzone/")
+
+	t := cfnTemplate{
+		AWSTemplateFormatVersion: "2010-09-09",
+		Description:              fmt.Sprintf("Route53 records of %s (%s), exported by r53tool", origin, zoneID),
+		Resources:                map[string]cfnResource{},
+	}
+	ids := map[string]int{}
+	for _, rs := range managedRecordSets(origin, records) {
+		id := cfnLogicalID(origin, rs)
+		if ids[id]++; ids[id] > 1 {
+			id = fmt.Sprintf("%s%d", id, ids[id])
+		}
+		t.Resources[id] = cfnResource{Type: "AWS::Route53::RecordSet", Properties: cfnRecordSetFor(zoneID, rs)}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(t)
+}
+
+func cfnRecordSetFor(zoneID string, rs rtypes.ResourceRecordSet) cfnRecordSet {
+	p := cfnRecordSet{
+		HostedZoneId:     zoneID,
+		Name:             unescapeName(aws.ToString(rs.Name)),
+		Type:             string(rs.Type),
+		SetIdentifier:    aws.ToString(rs.SetIdentifier),
+		Weight:           rs.Weight,
+		Region:           string(rs.Region),
+		Failover:         string(rs.Failover),
+		MultiValueAnswer: rs.MultiValueAnswer,
+		HealthCheckId:    aws.ToString(rs.HealthCheckId),
+	}
+	if rs.TTL != nil {
+		p.TTL = strconv.FormatInt(aws.ToInt64(rs.TTL), 10)
+	}
+	for _, v := range rs.ResourceRecords {
+		p.ResourceRecords = append(p.ResourceRecords, aws.ToString(v.Value))
+	}
+	if a := rs.AliasTarget; a != nil {
+		p.AliasTarget = &cfnAliasTarget{
+			DNSName:              aws.ToString(a.DNSName),
+			HostedZoneId:         aws.ToString(a.HostedZoneId),
+			EvaluateTargetHealth: a.EvaluateTargetHealth,
+		}
+	}
+	if g := rs.GeoLocation; g != nil {
+		p.GeoLocation = &cfnGeoLocation{
+			ContinentCode:   aws.ToString(g.ContinentCode),
+			CountryCode:     aws.ToString(g.CountryCode),
+			SubdivisionCode: aws.ToString(g.SubdivisionCode),
+		}
+	}
+	if g := rs.GeoProximityLocation; g != nil {
+		p.GeoProximityLocation = &cfnGeoProximity{
+			AWSRegion:      aws.ToString(g.AWSRegion),
+			LocalZoneGroup: aws.ToString(g.LocalZoneGroup),
+			Bias:           g.Bias,
+		}
+		if c := g.Coordinates; c != nil {
+			p.GeoProximityLocation.Coordinates = &cfnCoordinates{Latitude: aws.ToString(c.Latitude), Longitude: aws.ToString(c.Longitude)}
+		}
+	}
+	if c := rs.CidrRoutingConfig; c != nil {
+		p.CidrRoutingConfig = &cfnCidrRouting{CollectionId: aws.ToString(c.CollectionId), LocationName: aws.ToString(c.LocationName)}
+	}
+	return p
+}
+
+// cfnLogicalID derives an alphanumeric logical ID such as WwwA or ApexMX.
+func cfnLogicalID(origin string, rs rtypes.ResourceRecordSet) string {
+	label := octoLabel(origin, aws.ToString(rs.Name))
+	if label == "" {
+		label = "apex"
+	}
+	label = strings.ReplaceAll(label, "*", "wildcard")
+	id := ""
+	for _, part := range cfnIDUnsafe.Split(label+" "+aws.ToString(rs.SetIdentifier), -1) {
+		if part != "" {
+			id += strings.ToUpper(part[:1]) + part[1:]
+		}
+	}
+	id += string(rs.Type)
+	if id[0] >= '0' && id[0] <= '9' {
+		id = "Record" + id
+	}
+	return id
+}
@@ -0,0 +1,154 @@
+package dns
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	rtypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	mdns "github.com/miekg/dns"
+)
+
+// RecordSetChange pairs the two versions of a record set that differ.
+type RecordSetChange struct {
+	Left  rtypes.ResourceRecordSet
+	Right rtypes.ResourceRecordSet
+}
+
+// RecordSetDiff is the record-level difference between two zones.
+type RecordSetDiff struct {
+	OnlyLeft  []rtypes.ResourceRecordSet
+	OnlyRight []rtypes.ResourceRecordSet
+	Changed   []RecordSetChange
+}
+
+func (d RecordSetDiff) Empty() bool {
+	return len(d.OnlyLeft) == 0 && len(d.OnlyRight) == 0 && len(d.Changed) == 0
+}
+
+// CompareRecordSets compares two zones' record sets by name, type and set
+// identifier. The apex NS and SOA records are skipped since every zone has
+// its own. zone is the apex name; both sides are expected to use it.
+func CompareRecordSets(zone string, left, right []rtypes.ResourceRecordSet) RecordSetDiff {
+	zone = NormalizeDomain(strings.ToLower(zone))
+	l := indexRecordSets(zone, left)
+	r := indexRecordSets(zone, right)
+
+	d := RecordSetDiff{}
+	for _, k := range sortedKeys(l) {
+		rrs, ok := r[k]
+		switch {
+		case !ok:
+			d.OnlyLeft = append(d.OnlyLeft, l[k])
+		case RecordSetValue(l[k]) != RecordSetValue(rrs):
+			d.Changed = append(d.Changed, RecordSetChange{Left: l[k], Right: rrs})
+		}
+	}
+	for _, k := range sortedKeys(r) {
+		if _, ok := l[k]; !ok {
+			d.OnlyRight = append(d.OnlyRight, r[k])
+		}
+	}
+	return d
+}
+
+func indexRecordSets(zone string, records []rtypes.ResourceRecordSet) map[string]rtypes.ResourceRecordSet {
+	idx := map[string]rtypes.ResourceRecordSet{}
+	for _, rs := range records {
+		name := NormalizeDomain(strings.ToLower(unescapeName(aws.ToString(rs.Name))))
+		if name == zone && (rs.Type == rtypes.RRTypeNs || rs.Type == rtypes.RRTypeSoa) {
+			continue
+		}
+		idx[recordSetKey(name, rs)] = rs
+	}
+	return idx
+}
+
+func recordSetKey(name string, rs rtypes.ResourceRecordSet) string {
+	return fmt.Sprintf("%s %s %s", name, rs.Type, aws.ToString(rs.SetIdentifier))
+}
+
+func sortedKeys(m map[string]rtypes.ResourceRecordSet) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// unescapeName turns Route53's octal escape for "*" back into the asterisk.
+func unescapeName(name string) string {
+	return strings.ReplaceAll(name, `\052`, "*")
+}
+
+// CanonicalValue rewrites a record value the way DNS compares it, so the
+// same answer written differently, like a target without its trailing dot,
+// in other case or an IPv6 address not in its shortest form, reads the same.
+// Values that do not parse are returned as they are.
+func CanonicalValue(rtype rtypes.RRType, value string) string {
+	rr, err := mdns.NewRR(". 0 IN " + string(rtype) + " " + value)
+	if err != nil || rr == nil {
+		return value
+	}
+	switch r := rr.(type) {
+	case *mdns.CNAME:
+		r.Target = mdns.CanonicalName(r.Target)
+	case *mdns.MX:
+		r.Mx = mdns.CanonicalName(r.Mx)
+	case *mdns.NS:
+		r.Ns = mdns.CanonicalName(r.Ns)
+	case *mdns.PTR:
+		r.Ptr = mdns.CanonicalName(r.Ptr)
+	case *mdns.SRV:
+		r.Target = mdns.CanonicalName(r.Target)
+	}
+	return strings.TrimPrefix(rr.String(), rr.Header().String())
+}
+
+// RecordSetValue renders everything that makes up a record set's answer,
+// with values sorted, so two sets can be compared as strings.
+func RecordSetValue(rs rtypes.ResourceRecordSet) string {
+	parts := []string{}
+	if rs.AliasTarget != nil {
+		parts = append(parts, fmt.Sprintf("alias=%s/%s eval=%t",
+			aws.ToString(rs.AliasTarget.HostedZoneId),
+			strings.ToLower(NormalizeDomain(aws.ToString(rs.AliasTarget.DNSName))),
+			rs.AliasTarget.EvaluateTargetHealth))
+	} else {
+		values := []string{}
+		for _, v := range rs.ResourceRecords {
+			values = append(values, CanonicalValue(rs.Type, aws.ToString(v.Value)))
+		}
+		sort.Strings(values)
+		parts = append(parts, fmt.Sprintf("ttl=%d", aws.ToInt64(rs.TTL)), strings.Join(values, " "))
+	}
+
+	if rs.Weight != nil {
+		parts = append(parts, fmt.Sprintf("weight=%d", aws.ToInt64(rs.Weight)))
+	}
+	if rs.Region != "" {
+		parts = append(parts, fmt.Sprintf("region=%s", rs.Region))
+	}
+	if rs.Failover != "" {
+		parts = append(parts, fmt.Sprintf("failover=%s", rs.Failover))
+	}
+	if g := rs.GeoLocation; g != nil {
+		parts = append(parts, fmt.Sprintf("geo=%s/%s/%s",
+			aws.ToString(g.ContinentCode), aws.ToString(g.CountryCode), aws.ToString(g.SubdivisionCode)))
+	}
+	if g := rs.GeoProximityLocation; g != nil {
+		parts = append(parts, "geoproximity="+geoProximityDescription(g))
+	}
+	if c := rs.CidrRoutingConfig; c != nil {
+		parts = append(parts, fmt.Sprintf("cidr=%s/%s", aws.ToString(c.CollectionId), aws.ToString(c.LocationName)))
+	}
+	if aws.ToBool(rs.MultiValueAnswer) {
+		parts = append(parts, "multivalue")
+	}
+	if rs.HealthCheckId != nil {
+		parts = append(parts, fmt.Sprintf("healthcheck=%s", aws.ToString(rs.HealthCheckId)))
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,70 @@
+package dns
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	rtypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/stretchr/testify/require"
+)
+
+func rrset(name string, t rtypes.RRType, ttl int64, values ...string) rtypes.ResourceRecordSet {
+	rs := rtypes.ResourceRecordSet{Name: aws.String(name), Type: t, TTL: aws.Int64(ttl)}
+	for _, v := range values {
+		rs.ResourceRecords = append(rs.ResourceRecords, rtypes.ResourceRecord{Value: aws.String(v)})
+	}
+	return rs
+}
+
+func TestCompareRecordSets(t *testing.T) {
+	left := []rtypes.ResourceRecordSet{
+		rrset("example.com.", rtypes.RRTypeNs, 172800, "ns-1.awsdns-01.com."),
+		rrset("example.com.", rtypes.RRTypeSoa, 900, "ns-1.awsdns-01.com. awsdns-hostmaster.amazon.com. 1 7200 900 1209600 86400"),
+		rrset("example.com.", rtypes.RRTypeA, 300, "192.0.2.1", "192.0.2.2"),
+		rrset("www.example.com.", rtypes.RRTypeCname, 300, "example.com."),
+		rrset(`\052.example.com.`, rtypes.RRTypeA, 300, "192.0.2.9"),
+		rrset("old.example.com.", rtypes.RRTypeA, 300, "192.0.2.3"),
+	}
+	right := []rtypes.ResourceRecordSet{
+		rrset("example.com.", rtypes.RRTypeNs, 172800, "ns-2.awsdns-02.com."),
+		rrset("example.com.", rtypes.RRTypeA, 300, "192.0.2.2", "192.0.2.1"),
+		rrset("www.example.com.", rtypes.RRTypeCname, 60, "example.com."),
+		rrset("*.example.com.", rtypes.RRTypeA, 300, "192.0.2.9"),
+		rrset("new.example.com.", rtypes.RRTypeTxt, 300, `"hello"`),
+	}
+
+	d := CompareRecordSets("example.com", left, right)
+	require.False(t, d.Empty())
+	require.Len(t, d.OnlyLeft, 1)
+	require.Equal(t, "old.example.com.", aws.ToString(d.OnlyLeft[0].Name))
+	require.Len(t, d.OnlyRight, 1)
+	require.Equal(t, "new.example.com.", aws.ToString(d.OnlyRight[0].Name))
+	require.Len(t, d.Changed, 1)
+	require.Equal(t, "www.example.com.", aws.ToString(d.Changed[0].Left.Name))
+
+	require.True(t, CompareRecordSets("example.com.", left, left).Empty())
+}
+
+func TestRecordSetValue_RoutingAndAlias(t *testing.T) {
+	weighted := rrset("api.example.com.", rtypes.RRTypeA, 60, "192.0.2.1")
+	weighted.SetIdentifier = aws.String("blue")
+	weighted.Weight = aws.Int64(10)
+	weighted.HealthCheckId = aws.String("hc-1")
+	require.Equal(t, "ttl=60 192.0.2.1 weight=10 healthcheck=hc-1", RecordSetValue(weighted))
+
+	alias := rtypes.ResourceRecordSet{
+		Name:        aws.String("example.com."),
+		Type:        rtypes.RRTypeA,
+		AliasTarget: &rtypes.AliasTarget{HostedZoneId: aws.String("Z2FDTNDATAQYW2"), DNSName: aws.String("D1.CloudFront.net")},
+	}
+	require.Equal(t, "alias=Z2FDTNDATAQYW2/d1.cloudfront.net. eval=false", RecordSetValue(alias))
+}
+
+func TestRecordSetValue_Canonical(t *testing.T) {
+	require.Equal(t, "ttl=300 pages.example.net.", RecordSetValue(rrset("docs.example.com.", rtypes.RRTypeCname, 300, "Pages.Example.NET")))
+	require.Equal(t, "ttl=300 10 mx1.example.com. 20 mx2.example.com.",
+		RecordSetValue(rrset("example.com.", rtypes.RRTypeMx, 300, "20 MX2.example.com", "10 mx1.example.com.")))
+	require.Equal(t, "ttl=300 2001:db8::1", RecordSetValue(rrset("v6.example.com.", rtypes.RRTypeAaaa, 300, "2001:DB8:0:0:0:0:0:1")))
+	require.Equal(t, "ttl=300 not an address", RecordSetValue(rrset("bad.example.com.", rtypes.RRTypeA, 300, "not an address")),
+		"values that do not parse are kept")
+}
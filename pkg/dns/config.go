@@ -0,0 +1,138 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// DefaultRegion is used when neither the options, the environment nor the
+// profile name a region. Route53 is a global service, so any region works.
+const DefaultRegion = "us-east-1"
+
+// AWSOptions says how to reach AWS. The zero value uses the default
+// credential chain.
+type AWSOptions struct {
+	Profile string
+	// Region overrides the environment and profile.
+	Region string
+	// EndpointURL sends every request to this endpoint instead of AWS, e.g.
+	// a local emulator.
+	EndpointURL string
+	// Endpoints overrides EndpointURL for single services, keyed by the
+	// Service* names.
+	Endpoints map[string]string
+	// RoleARN, when set, is assumed with the profile's credentials.
+	RoleARN string
+}
+
+// Services whose endpoint can be overridden in AWSOptions.Endpoints.
+const (
+	ServiceRoute53        = "route53"
+	ServiceRoute53Domains = "route53domains"
+	ServiceSTS            = "sts"
+	ServiceOrganizations  = "organizations"
+)
+
+func validateEndpoints(endpoints map[string]string) error {
+	for service := range endpoints {
+		switch service {
+		case ServiceRoute53, ServiceRoute53Domains, ServiceSTS, ServiceOrganizations:
+		default:
+			return fmt.Errorf("unknown service %q in endpoint overrides; use %s, %s, %s or %s",
+				service, ServiceRoute53, ServiceRoute53Domains, ServiceSTS, ServiceOrganizations)
+		}
+	}
+	return nil
+}
+
+// endpoint returns the override for service, or nil to keep the config's
+// endpoint.
+func (o AWSOptions) endpoint(service string) *string {
+	if ep, ok := o.Endpoints[service]; ok && ep != "" {
+		return aws.String(ep)
+	}
+	return nil
+}
+
+// LoadAWSConfig resolves the SDK configuration for o. It fails instead of
+// falling back when the profile or its credentials are broken.
+func LoadAWSConfig(ctx context.Context, o AWSOptions) (aws.Config, error) {
+	if err := validateEndpoints(o.Endpoints); err != nil {
+		return aws.Config{}, err
+	}
+
+	opts := []func(*config.LoadOptions) error{
+		config.WithRetryer(func() aws.Retryer {
+			return retry.NewAdaptiveMode(func(amo *retry.AdaptiveModeOptions) {
+				amo.StandardOptions = []func(*retry.StandardOptions){
+					func(so *retry.StandardOptions) {
+						so.MaxAttempts = 5
+						so.MaxBackoff = 60 * time.Second
+						so.Backoff = retry.NewExponentialJitterBackoff(so.MaxBackoff)
+					},
+				}
+			})
+		}),
+	}
+	if o.Profile != "" {
+		opts = append(opts, config.WithSharedConfigProfile(o.Profile))
+	}
+	if o.Region != "" {
+		opts = append(opts, config.WithRegion(o.Region))
+	}
+	if o.EndpointURL != "" {
+		opts = append(opts, config.WithBaseEndpoint(o.EndpointURL))
+	}
+
+	cfg, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return aws.Config{}, err
+	}
+	if cfg.Region == "" {
+		cfg.Region = DefaultRegion
+	}
+	if o.RoleARN != "" {
+		cfg = assumeRole(cfg, o.RoleARN, o.endpoint(ServiceSTS))
+	}
+	return cfg, nil
+}
+
+// Identity is who the resolved credentials act as.
+type Identity struct {
+	Account string `json:"account" yaml:"account"`
+	ARN     string `json:"arn" yaml:"arn"`
+	UserID  string `json:"user_id" yaml:"user_id"`
+	Region  string `json:"region" yaml:"region"`
+}
+
+// WhoAmI asks STS who the credentials for o belong to.
+func WhoAmI(ctx context.Context, o AWSOptions) (*Identity, error) {
+	cfg, err := LoadAWSConfig(ctx, o)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := newSTSClient(cfg, o).GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return nil, err
+	}
+	return &Identity{
+		Account: aws.ToString(resp.Account),
+		ARN:     aws.ToString(resp.Arn),
+		UserID:  aws.ToString(resp.UserId),
+		Region:  cfg.Region,
+	}, nil
+}
+
+func newSTSClient(cfg aws.Config, o AWSOptions) *sts.Client {
+	return sts.NewFromConfig(cfg, func(so *sts.Options) {
+		if ep := o.endpoint(ServiceSTS); ep != nil {
+			so.BaseEndpoint = ep
+		}
+	})
+}
@@ -0,0 +1,80 @@
+package dns
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// isolateAWSConfig points the SDK at an empty shared config so tests do not
+// depend on the machine they run on.
+func isolateAWSConfig(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	cfgFile := filepath.Join(dir, "config")
+	require.NoError(t, os.WriteFile(cfgFile, []byte("[profile eu]\nregion = eu-west-1\n"), 0o600))
+	t.Setenv("AWS_CONFIG_FILE", cfgFile)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "credentials"))
+	t.Setenv("AWS_REGION", "")
+	t.Setenv("AWS_DEFAULT_REGION", "")
+	t.Setenv("AWS_PROFILE", "")
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	t.Setenv("AWS_ENDPOINT_URL", "")
+}
+
+func TestLoadAWSConfig_Region(t *testing.T) {
+	isolateAWSConfig(t)
+
+	cfg, err := LoadAWSConfig(context.Background(), AWSOptions{})
+	require.NoError(t, err)
+	require.Equal(t, DefaultRegion, cfg.Region)
+	_, set := os.LookupEnv("AWS_REGION")
+	require.True(t, set)
+	require.Empty(t, os.Getenv("AWS_REGION"), "the environment must not be changed")
+
+	cfg, err = LoadAWSConfig(context.Background(), AWSOptions{Profile: "eu"})
+	require.NoError(t, err)
+	require.Equal(t, "eu-west-1", cfg.Region)
+
+	cfg, err = LoadAWSConfig(context.Background(), AWSOptions{Profile: "eu", Region: "ap-south-1", EndpointURL: "http://localhost:4566"})
+	require.NoError(t, err)
+	require.Equal(t, "ap-south-1", cfg.Region)
+	require.Equal(t, "http://localhost:4566", *cfg.BaseEndpoint)
+}
+
+func TestNewDomainManager_PinsUSEast1(t *testing.T) {
+	isolateAWSConfig(t)
+
+	dm, err := NewDomainManager(context.Background(), AWSOptions{Profile: "eu"})
+	require.NoError(t, err)
+	require.Equal(t, "us-east-1", dm.cli.Options().Region, "Route 53 Domains only exists in us-east-1")
+
+	rm, err := NewRouteManager(context.Background(), AWSOptions{Profile: "eu", Region: "ap-south-1"}, nil)
+	require.NoError(t, err)
+	require.Equal(t, "us-east-1", rm.domains.Options().Region)
+
+	dm, err = NewDomainManager(context.Background(), AWSOptions{Profile: "eu", EndpointURL: "http://localhost:4566"})
+	require.NoError(t, err)
+	require.Equal(t, "eu-west-1", dm.cli.Options().Region, "emulators keep the configured region")
+}
+
+func TestNewRouteManager_MissingProfileReturnsError(t *testing.T) {
+	isolateAWSConfig(t)
+
+	_, err := NewRouteManager(context.Background(), AWSOptions{Profile: "missing"}, nil)
+	require.Error(t, err)
+
+	_, err = NewDomainManager(context.Background(), AWSOptions{Profile: "missing"})
+	require.Error(t, err)
+}
+
+func TestLoadAWSConfig_RejectsUnknownEndpointService(t *testing.T) {
+	isolateAWSConfig(t)
+
+	_, err := LoadAWSConfig(context.Background(), AWSOptions{Endpoints: map[string]string{"route-53": "http://localhost"}})
+	require.ErrorContains(t, err, `unknown service "route-53"`)
+}
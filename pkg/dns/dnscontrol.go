@@ -0,0 +1,155 @@
+package dns
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/v4/models"
+	"github.com/StackExchange/dnscontrol/v4/pkg/prettyzone"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	rtypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
+)
+
+// writeDNSControl renders a dnsconfig.js D() stanza. REG_NONE and DSP_R53
+// are expected to be declared elsewhere in dnsconfig.js. dnscontrol has no
+// routing policies, so routed sets become IGNORE() entries that leave them
+// in place.
+func writeDNSControl(w io.Writer, zone rtypes.HostedZone, records []rtypes.ResourceRecordSet) error {
+	origin := DenormalizeDomain(aws.ToString(zone.Name))
+	records = managedRecordSets(origin, records)
+
+	all := models.Records{}
+	for _, rs := range records {
+		all = append(all, modelRecords(origin, rs)...)
+	}
+	defaultTTL := prettyzone.MostCommonTTL(slices.DeleteFunc(all, isR53Alias))
+	if defaultTTL == 0 {
+		defaultTTL = 300
+	}
+
+	lines := []string{fmt.Sprintf("DefaultTTL(%d)", defaultTTL)}
+	ignored := map[string]bool{}
+	ignore := func(rs rtypes.ResourceRecordSet, why string) {
+		rc := modelRecordLabel(origin, rs)
+		k := rc + " " + string(rs.Type)
+		lines = append(lines, "// "+why)
+		if !ignored[k] {
+			ignored[k] = true
+			lines = append(lines, fmt.Sprintf("IGNORE(%s, %s)", jsString(rc), jsString(string(rs.Type))))
+		}
+	}
+	for _, rs := range records {
+		if hasRouting(rs) {
+			ignore(rs, fmt.Sprintf("%s %s is routed (%s)", aws.ToString(rs.Name), rs.Type, routingDescription(rs)))
+			continue
+		}
+		recs := modelRecords(origin, rs)
+		rendered := []string{}
+		for _, rc := range recs {
+			line, ok := dnscontrolRecord(rc, defaultTTL)
+			if !ok {
+				break
+			}
+			rendered = append(rendered, line)
+		}
+		if len(rendered) != len(recs) {
+			ignore(rs, fmt.Sprintf("%s %s is not supported by this export", aws.ToString(rs.Name), rs.Type))
+			continue
+		}
+		if rs.HealthCheckId != nil {
+			lines = append(lines, fmt.Sprintf("// %s %s has health check %s", aws.ToString(rs.Name), rs.Type, aws.ToString(rs.HealthCheckId)))
+		}
+		lines = append(lines, rendered...)
+	}
+
+	fmt.Fprintf(w, "// Exported by r53tool from %s (%s).\n", strings.TrimPrefix(aws.ToString(zone.Id), "/hostedzone/"), origin)
+	if IsPrivateZone(zone) {
+		fmt.Fprintln(w, "// This is a private hosted zone.")
+	}
+	fmt.Fprintf(w, "D(%s, REG_NONE, DnsProvider(DSP_R53),\n", jsString(origin))
+	// Every argument but the last takes a comma; comments are not arguments.
+	last := 0
+	for i, l := range lines {
+		if !strings.HasPrefix(l, "//") {
+			last = i
+		}
+	}
+	for i, l := range lines {
+		if i < last && !strings.HasPrefix(l, "//") {
+			l += ","
+		}
+		fmt.Fprintf(w, "\t%s\n", l)
+	}
+	_, err := fmt.Fprintln(w, ");")
+	return err
+}
+
+// dnscontrolRecord renders rc as a dnscontrol record function call.
+func dnscontrolRecord(rc *models.RecordConfig, defaultTTL uint32) (string, bool) {
+	label := jsString(rc.GetLabel())
+	var args []string
+	switch rc.Type {
+	case "A", "AAAA", "CNAME", "NS", "PTR":
+		args = []string{label, jsString(rc.GetTargetField())}
+	case "MX":
+		args = []string{label, fmt.Sprint(rc.MxPreference), jsString(rc.GetTargetField())}
+	case "SRV":
+		args = []string{label, fmt.Sprint(rc.SrvPriority), fmt.Sprint(rc.SrvWeight), fmt.Sprint(rc.SrvPort), jsString(rc.GetTargetField())}
+	case "CAA":
+		args = []string{label, jsString(rc.CaaTag), jsString(rc.GetTargetField())}
+		if rc.CaaFlag&128 != 0 {
+			args = append(args, "CAA_CRITICAL")
+		}
+	case "TXT":
+		segments := rc.GetTargetTXTSegmented()
+		if len(segments) == 1 {
+			args = []string{label, jsString(segments[0])}
+		} else {
+			quoted := []string{}
+			for _, s := range segments {
+				quoted = append(quoted, jsString(s))
+			}
+			args = []string{label, "[" + strings.Join(quoted, ", ") + "]"}
+		}
+	case "R53_ALIAS":
+		args = []string{label, jsString(rc.R53Alias["type"]), jsString(rc.GetTargetField()),
+			fmt.Sprintf("R53_ZONE(%s)", jsString(rc.R53Alias["zone_id"]))}
+		if rc.R53Alias["evaluate_target_health"] == "true" {
+			args = append(args, "R53_EVALUATE_TARGET_HEALTH(true)")
+		}
+		return fmt.Sprintf("R53_ALIAS(%s)", strings.Join(args, ", ")), true
+	default:
+		return "", false
+	}
+	if rc.TTL != 0 && rc.TTL != defaultTTL {
+		args = append(args, fmt.Sprintf("TTL(%d)", rc.TTL))
+	}
+	return fmt.Sprintf("%s(%s)", rc.Type, strings.Join(args, ", ")), true
+}
+
+// modelRecords converts a single record set, with its name unescaped.
+func modelRecords(origin string, rs rtypes.ResourceRecordSet) models.Records {
+	rs.Name = aws.String(unescapeName(aws.ToString(rs.Name)))
+	recs, _ := route53ToModelRecords(origin, []rtypes.ResourceRecordSet{rs})
+	return recs
+}
+
+// modelRecordLabel is the dnscontrol label of rs, "@" for the apex.
+func modelRecordLabel(origin string, rs rtypes.ResourceRecordSet) string {
+	rc := &models.RecordConfig{}
+	rc.SetLabelFromFQDN(unescapeName(aws.ToString(rs.Name)), origin)
+	return rc.GetLabel()
+}
+
+// jsString quotes s as a JavaScript string literal.
+func jsString(s string) string {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	return strings.TrimSuffix(b.String(), "\n")
+}
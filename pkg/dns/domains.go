@@ -3,11 +3,10 @@ package dns
 import (
 	"context"
 	"fmt"
-	"os"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/route53domains"
 	"github.com/aws/aws-sdk-go-v2/service/route53domains/types"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
@@ -21,28 +20,49 @@ type DomainManager struct {
 	stscli *sts.Client
 }
 
+// DomainDetail is the registrar's view of a registered domain.
+type DomainDetail struct {
+	Name        string
+	Nameservers []string
+	AutoRenew   bool
+	Expiry      time.Time
+}
+
 type Transfer struct {
 	Password    string
 	OperationID string
 }
 
-func NewDomainManager(ctx context.Context, profile string) (*DomainManager, error) {
-	if r := os.Getenv("AWS_REGION"); r == "" {
-		_ = os.Setenv("AWS_REGION", "us-east-1")
-	}
-	cfg, err := config.LoadDefaultConfig(ctx,
-		config.WithSharedConfigProfile(profile))
-
+func NewDomainManager(ctx context.Context, o AWSOptions) (*DomainManager, error) {
+	cfg, err := LoadAWSConfig(ctx, o)
 	if err != nil {
 		return nil, err
 	}
 
 	return &DomainManager{
-		cli:    route53domains.NewFromConfig(cfg),
-		stscli: sts.NewFromConfig(cfg),
+		cli:    newDomainsClient(cfg, o),
+		stscli: newSTSClient(cfg, o),
 	}, nil
 }
 
+// domainsRegion is the only region Route 53 Domains is served from.
+const domainsRegion = "us-east-1"
+
+// newDomainsClient builds a Route 53 Domains client. It always talks to
+// us-east-1, whatever the profile's region, unless an endpoint override
+// points it elsewhere.
+func newDomainsClient(cfg aws.Config, o AWSOptions) *route53domains.Client {
+	return route53domains.NewFromConfig(cfg, func(do *route53domains.Options) {
+		ep := o.endpoint(ServiceRoute53Domains)
+		if ep != nil {
+			do.BaseEndpoint = ep
+		}
+		if ep == nil && o.EndpointURL == "" {
+			do.Region = domainsRegion
+		}
+	})
+}
+
 func (dm *DomainManager) GetAccountID(ctx context.Context) (string, error) {
 	i, err := dm.stscli.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
 	if err != nil {
@@ -69,6 +89,27 @@ func (dm *DomainManager) ListRegisteredDomains(ctx context.Context) ([]string, e
 	return domains, nil
 }
 
+// GetDomainDetail returns the nameservers the registrar publishes for domain,
+// lowercased and without the trailing dot, along with its renewal settings.
+func (dm *DomainManager) GetDomainDetail(ctx context.Context, domain string) (*DomainDetail, error) {
+	resp, err := dm.cli.GetDomainDetail(ctx, &route53domains.GetDomainDetailInput{
+		DomainName: aws.String(DenormalizeDomain(domain)),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	d := &DomainDetail{
+		Name:      aws.ToString(resp.DomainName),
+		AutoRenew: aws.ToBool(resp.AutoRenew),
+		Expiry:    aws.ToTime(resp.ExpirationDate),
+	}
+	for _, n := range resp.Nameservers {
+		d.Nameservers = append(d.Nameservers, strings.ToLower(DenormalizeDomain(aws.ToString(n.Name))))
+	}
+	return d, nil
+}
+
 func (dm *DomainManager) TransferDomain(ctx context.Context, domain, dstAccount string) (*Transfer, error) {
 	resp, err := dm.cli.TransferDomainToAnotherAwsAccount(ctx, &route53domains.TransferDomainToAnotherAwsAccountInput{
 		AccountId:  aws.String(dstAccount),
@@ -237,7 +278,7 @@ func (w *GetOperationDetailWaiter) WaitForOutput(ctx context.Context, params *ro
 			o.APIOptions = append(o.APIOptions, apiOptions...)
 		})
 
-		retryable, err := options.Retryable(ctx, w.options.ExpectedStatus, params, out, err)
+		retryable, err := options.Retryable(ctx, options.ExpectedStatus, params, out, err)
 		if err != nil {
 			return nil, err
 		}
@@ -267,16 +308,21 @@ func (w *GetOperationDetailWaiter) WaitForOutput(ctx context.Context, params *ro
 	return nil, fmt.Errorf("exceeded max wait time for GetOperationDetail waiter")
 }
 
+// getOperationDetailStateRetryable keeps polling while the operation is in
+// progress. Errors are returned rather than retried, since the client's
+// retryer already covers transient ones; emulators that do not track
+// operations would otherwise keep the waiter busy until it times out.
 func getOperationDetailStateRetryable(ctx context.Context, expected types.OperationStatus, input *route53domains.GetOperationDetailInput, output *route53domains.GetOperationDetailOutput, err error) (bool, error) {
+	if err != nil {
+		return false, err
+	}
 
-	if err == nil {
-		if output.Status == expected {
-			return false, nil
-		}
+	if output.Status == expected {
+		return false, nil
+	}
 
-		if output.Status == types.OperationStatusFailed || output.Status == types.OperationStatusError {
-			return false, fmt.Errorf("operation failed: %s", aws.ToString(output.Message))
-		}
+	if output.Status == types.OperationStatusFailed || output.Status == types.OperationStatusError {
+		return false, fmt.Errorf("operation failed: %s", aws.ToString(output.Message))
 	}
 
 	return true, nil
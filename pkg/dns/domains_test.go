@@ -1,10 +1,15 @@
 package dns
 
 import (
+	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	rtypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/aws/aws-sdk-go-v2/service/route53domains"
+	"github.com/aws/aws-sdk-go-v2/service/route53domains/types"
 	"github.com/stretchr/testify/require"
 )
 
@@ -16,3 +21,43 @@ func TestToAwsTags(t *testing.T) {
 		{Key: aws.String("team"), Value: aws.String("dns")},
 	}, out)
 }
+
+type operationClient struct {
+	statuses []types.OperationStatus
+	err      error
+	calls    int
+}
+
+func (c *operationClient) GetOperationDetail(ctx context.Context, params *route53domains.GetOperationDetailInput, optFns ...func(*route53domains.Options)) (*route53domains.GetOperationDetailOutput, error) {
+	c.calls++
+	if c.err != nil {
+		return nil, c.err
+	}
+	s := c.statuses[min(c.calls, len(c.statuses))-1]
+	return &route53domains.GetOperationDetailOutput{Status: s}, nil
+}
+
+func TestGetOperationDetailWaiter(t *testing.T) {
+	in := &route53domains.GetOperationDetailInput{OperationId: aws.String("op-1")}
+
+	c := &operationClient{statuses: []types.OperationStatus{types.OperationStatusSuccessful}}
+	require.NoError(t, NewGetOperationDetailWaiter(c).Wait(context.Background(), in, time.Minute))
+	require.Equal(t, 1, c.calls)
+
+	c = &operationClient{statuses: []types.OperationStatus{types.OperationStatusInProgress, types.OperationStatusSuccessful}}
+	w := NewGetOperationDetailWaiter(c, func(o *GetOperationDetailWaiterOptions) { o.MinDelay = time.Millisecond })
+	require.NoError(t, w.Wait(context.Background(), in, time.Minute))
+	require.Equal(t, 2, c.calls)
+
+	c = &operationClient{statuses: []types.OperationStatus{types.OperationStatusFailed}}
+	require.ErrorContains(t, NewGetOperationDetailWaiter(c).Wait(context.Background(), in, time.Minute), "operation failed")
+
+	c = &operationClient{err: errors.New("OperationNotFound")}
+	require.ErrorContains(t, NewGetOperationDetailWaiter(c).Wait(context.Background(), in, time.Minute), "OperationNotFound")
+	require.Equal(t, 1, c.calls)
+
+	// Per-call options, including the expected status, are honoured.
+	c = &operationClient{statuses: []types.OperationStatus{types.OperationStatusInProgress}}
+	require.NoError(t, NewGetOperationDetailWaiter(c).Wait(context.Background(), in, time.Minute,
+		func(o *GetOperationDetailWaiterOptions) { o.ExpectedStatus = types.OperationStatusInProgress }))
+}
@@ -0,0 +1,286 @@
+package dns
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	rtypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"gopkg.in/yaml.v3"
+)
+
+// ExportFormat is a file format a zone can be exported to.
+type ExportFormat string
+
+const (
+	FormatBIND           ExportFormat = "bind"
+	FormatDNSControl     ExportFormat = "dnscontrol"
+	FormatOctoDNS        ExportFormat = "octodns"
+	FormatTerraform      ExportFormat = "terraform"
+	FormatCloudFormation ExportFormat = "cloudformation"
+	FormatJSON           ExportFormat = "json"
+	FormatYAML           ExportFormat = "yaml"
+)
+
+// ExportFormats lists the accepted export formats.
+var ExportFormats = []ExportFormat{
+	FormatBIND, FormatDNSControl, FormatOctoDNS, FormatTerraform, FormatCloudFormation, FormatJSON, FormatYAML,
+}
+
+// ParseExportFormat returns the ExportFormat named s.
+func ParseExportFormat(s string) (ExportFormat, error) {
+	for _, f := range ExportFormats {
+		if string(f) == strings.ToLower(s) {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unknown export format %q, expected one of %v", s, ExportFormats)
+}
+
+// Extension is the file name extension used for the format.
+func (f ExportFormat) Extension() string {
+	switch f {
+	case FormatDNSControl:
+		return "js"
+	case FormatOctoDNS, FormatYAML:
+		return "yaml"
+	case FormatTerraform:
+		return "tf"
+	case FormatCloudFormation:
+		return "template.json"
+	case FormatJSON:
+		return "json"
+	}
+	return "zone"
+}
+
+// WriteZoneFile writes zone and its record sets at outputPath in format.
+func WriteZoneFile(outputPath string, format ExportFormat, zone rtypes.HostedZone, records []rtypes.ResourceRecordSet) error {
+	f, err := os.Create(outputPath)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	return WriteZone(f, format, zone, records)
+}
+
+// WriteZone renders zone and its record sets in format.
+func WriteZone(w io.Writer, format ExportFormat, zone rtypes.HostedZone, records []rtypes.ResourceRecordSet) error {
+	switch format {
+	case FormatBIND:
+		return writeBind(w, aws.ToString(zone.Name), records)
+	case FormatDNSControl:
+		return writeDNSControl(w, zone, records)
+	case FormatOctoDNS:
+		return writeOctoDNS(w, zone, records)
+	case FormatTerraform:
+		return writeTerraform(w, zone, records)
+	case FormatCloudFormation:
+		return writeCloudFormation(w, zone, records)
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(NewExportedZone(zone, records))
+	case FormatYAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(NewExportedZone(zone, records)); err != nil {
+			return err
+		}
+		return enc.Close()
+	}
+	return fmt.Errorf("unknown export format %q", format)
+}
+
+// ExportedZone is the JSON and YAML form of a hosted zone. Record sets keep
+// every Route53 field, so nothing is lost on the way out.
+type ExportedZone struct {
+	Zone       string              `json:"zone" yaml:"zone"`
+	ZoneID     string              `json:"zone_id,omitempty" yaml:"zone_id,omitempty"`
+	Private    bool                `json:"private,omitempty" yaml:"private,omitempty"`
+	RecordSets []ExportedRecordSet `json:"record_sets" yaml:"record_sets"`
+}
+
+// ExportedRecordSet is a record set with its routing policy, alias target
+// and health check in native fields. Names use "*" rather than Route53's
+// \052 escape.
+type ExportedRecordSet struct {
+	Name             string                `json:"name" yaml:"name"`
+	Type             string                `json:"type" yaml:"type"`
+	TTL              *int64                `json:"ttl,omitempty" yaml:"ttl,omitempty"`
+	Values           []string              `json:"values,omitempty" yaml:"values,omitempty"`
+	Alias            *ExportedAlias        `json:"alias,omitempty" yaml:"alias,omitempty"`
+	SetIdentifier    string                `json:"set_identifier,omitempty" yaml:"set_identifier,omitempty"`
+	Weight           *int64                `json:"weight,omitempty" yaml:"weight,omitempty"`
+	Region           string                `json:"region,omitempty" yaml:"region,omitempty"`
+	Failover         string                `json:"failover,omitempty" yaml:"failover,omitempty"`
+	GeoLocation      *ExportedGeoLocation  `json:"geo_location,omitempty" yaml:"geo_location,omitempty"`
+	GeoProximity     *ExportedGeoProximity `json:"geo_proximity,omitempty" yaml:"geo_proximity,omitempty"`
+	CidrRouting      *ExportedCidrRouting  `json:"cidr_routing,omitempty" yaml:"cidr_routing,omitempty"`
+	MultiValueAnswer bool                  `json:"multi_value_answer,omitempty" yaml:"multi_value_answer,omitempty"`
+	HealthCheckID    string                `json:"health_check_id,omitempty" yaml:"health_check_id,omitempty"`
+}
+
+// ExportedAlias is the target of an alias record set.
+type ExportedAlias struct {
+	DNSName              string `json:"dns_name" yaml:"dns_name"`
+	HostedZoneID         string `json:"hosted_zone_id" yaml:"hosted_zone_id"`
+	EvaluateTargetHealth bool   `json:"evaluate_target_health" yaml:"evaluate_target_health"`
+}
+
+// ExportedGeoLocation is the location a geolocation record set answers for.
+type ExportedGeoLocation struct {
+	ContinentCode   string `json:"continent_code,omitempty" yaml:"continent_code,omitempty"`
+	CountryCode     string `json:"country_code,omitempty" yaml:"country_code,omitempty"`
+	SubdivisionCode string `json:"subdivision_code,omitempty" yaml:"subdivision_code,omitempty"`
+}
+
+// ExportedGeoProximity is where a geoproximity record set answers from:
+// an AWS region, a Local Zone group or coordinates, with the bias that
+// grows or shrinks its area.
+type ExportedGeoProximity struct {
+	AWSRegion      string `json:"aws_region,omitempty" yaml:"aws_region,omitempty"`
+	LocalZoneGroup string `json:"local_zone_group,omitempty" yaml:"local_zone_group,omitempty"`
+	Latitude       string `json:"latitude,omitempty" yaml:"latitude,omitempty"`
+	Longitude      string `json:"longitude,omitempty" yaml:"longitude,omitempty"`
+	Bias           *int32 `json:"bias,omitempty" yaml:"bias,omitempty"`
+}
+
+// ExportedCidrRouting is the CIDR collection location an IP-based record
+// set answers for.
+type ExportedCidrRouting struct {
+	CollectionID string `json:"collection_id" yaml:"collection_id"`
+	LocationName string `json:"location_name" yaml:"location_name"`
+}
+
+// NewExportedZone converts zone and its record sets to their exported form.
+func NewExportedZone(zone rtypes.HostedZone, records []rtypes.ResourceRecordSet) ExportedZone {
+	z := ExportedZone{
+		Zone:       NormalizeDomain(aws.ToString(zone.Name)),
+		ZoneID:     strings.TrimPrefix(aws.ToString(zone.Id), "/hostedzone/"),
+		Private:    IsPrivateZone(zone),
+		RecordSets: []ExportedRecordSet{},
+	}
+	for _, rs := range records {
+		z.RecordSets = append(z.RecordSets, NewExportedRecordSet(rs))
+	}
+	return z
+}
+
+// NewExportedRecordSet converts a Route53 record set to its exported form.
+func NewExportedRecordSet(rs rtypes.ResourceRecordSet) ExportedRecordSet {
+	e := ExportedRecordSet{
+		Name:             unescapeName(aws.ToString(rs.Name)),
+		Type:             string(rs.Type),
+		TTL:              rs.TTL,
+		SetIdentifier:    aws.ToString(rs.SetIdentifier),
+		Weight:           rs.Weight,
+		Region:           string(rs.Region),
+		Failover:         string(rs.Failover),
+		MultiValueAnswer: aws.ToBool(rs.MultiValueAnswer),
+		HealthCheckID:    aws.ToString(rs.HealthCheckId),
+	}
+	for _, v := range rs.ResourceRecords {
+		e.Values = append(e.Values, aws.ToString(v.Value))
+	}
+	if a := rs.AliasTarget; a != nil {
+		e.Alias = &ExportedAlias{
+			DNSName:              aws.ToString(a.DNSName),
+			HostedZoneID:         aws.ToString(a.HostedZoneId),
+			EvaluateTargetHealth: a.EvaluateTargetHealth,
+		}
+	}
+	if g := rs.GeoLocation; g != nil {
+		e.GeoLocation = &ExportedGeoLocation{
+			ContinentCode:   aws.ToString(g.ContinentCode),
+			CountryCode:     aws.ToString(g.CountryCode),
+			SubdivisionCode: aws.ToString(g.SubdivisionCode),
+		}
+	}
+	if g := rs.GeoProximityLocation; g != nil {
+		e.GeoProximity = &ExportedGeoProximity{
+			AWSRegion:      aws.ToString(g.AWSRegion),
+			LocalZoneGroup: aws.ToString(g.LocalZoneGroup),
+			Bias:           g.Bias,
+		}
+		if c := g.Coordinates; c != nil {
+			e.GeoProximity.Latitude = aws.ToString(c.Latitude)
+			e.GeoProximity.Longitude = aws.ToString(c.Longitude)
+		}
+	}
+	if c := rs.CidrRoutingConfig; c != nil {
+		e.CidrRouting = &ExportedCidrRouting{
+			CollectionID: aws.ToString(c.CollectionId),
+			LocationName: aws.ToString(c.LocationName),
+		}
+	}
+	return e
+}
+
+// managedRecordSets drops the apex NS and SOA, which Route53 creates with
+// the zone and infrastructure-as-code tools leave alone.
+func managedRecordSets(zone string, records []rtypes.ResourceRecordSet) []rtypes.ResourceRecordSet {
+	zone = strings.ToLower(NormalizeDomain(zone))
+	out := []rtypes.ResourceRecordSet{}
+	for _, rs := range records {
+		if (rs.Type == rtypes.RRTypeNs || rs.Type == rtypes.RRTypeSoa) && strings.ToLower(aws.ToString(rs.Name)) == zone {
+			continue
+		}
+		out = append(out, rs)
+	}
+	return out
+}
+
+// hasRouting reports whether rs is one of several sets sharing a name and
+// type under a routing policy.
+func hasRouting(rs rtypes.ResourceRecordSet) bool {
+	return rs.SetIdentifier != nil
+}
+
+// routingDescription summarises the routing fields of rs for formats that
+// can only carry them as comments.
+func routingDescription(rs rtypes.ResourceRecordSet) string {
+	parts := []string{fmt.Sprintf("set_identifier=%s", aws.ToString(rs.SetIdentifier))}
+	if rs.Weight != nil {
+		parts = append(parts, fmt.Sprintf("weight=%d", aws.ToInt64(rs.Weight)))
+	}
+	if rs.Region != "" {
+		parts = append(parts, fmt.Sprintf("region=%s", rs.Region))
+	}
+	if rs.Failover != "" {
+		parts = append(parts, fmt.Sprintf("failover=%s", rs.Failover))
+	}
+	if g := rs.GeoLocation; g != nil {
+		parts = append(parts, fmt.Sprintf("geo=%s/%s/%s",
+			aws.ToString(g.ContinentCode), aws.ToString(g.CountryCode), aws.ToString(g.SubdivisionCode)))
+	}
+	if g := rs.GeoProximityLocation; g != nil {
+		parts = append(parts, "geoproximity="+geoProximityDescription(g))
+	}
+	if c := rs.CidrRoutingConfig; c != nil {
+		parts = append(parts, fmt.Sprintf("cidr=%s/%s", aws.ToString(c.CollectionId), aws.ToString(c.LocationName)))
+	}
+	if aws.ToBool(rs.MultiValueAnswer) {
+		parts = append(parts, "multivalue")
+	}
+	if rs.HealthCheckId != nil {
+		parts = append(parts, fmt.Sprintf("health_check_id=%s", aws.ToString(rs.HealthCheckId)))
+	}
+	return strings.Join(parts, " ")
+}
+
+// geoProximityDescription renders where a geoproximity set answers from
+// and its bias, e.g. "us-east-1 bias=10" or "49.22,-74.01".
+func geoProximityDescription(g *rtypes.GeoProximityLocation) string {
+	where := aws.ToString(g.AWSRegion) + aws.ToString(g.LocalZoneGroup)
+	if c := g.Coordinates; c != nil {
+		where = aws.ToString(c.Latitude) + "," + aws.ToString(c.Longitude)
+	}
+	if g.Bias != nil {
+		where += fmt.Sprintf(" bias=%d", aws.ToInt32(g.Bias))
+	}
+	return where
+}
@@ -0,0 +1,201 @@
+package dns
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	rtypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func exportFixture() (rtypes.HostedZone, []rtypes.ResourceRecordSet) {
+	zone := rtypes.HostedZone{Id: aws.String("/hostedzone/Z123"), Name: aws.String("example.com.")}
+	geo := plain("geo.example.com.", rtypes.RRTypeA, 300, "192.0.2.9")
+	geo.SetIdentifier = aws.String("eu")
+	geo.GeoLocation = &rtypes.GeoLocation{ContinentCode: aws.String("EU")}
+	geo.HealthCheckId = aws.String("hc-1")
+	return zone, []rtypes.ResourceRecordSet{
+		plain("example.com.", rtypes.RRTypeNs, 172800, "ns-1.awsdns-00.com."),
+		plain("example.com.", rtypes.RRTypeSoa, 900, "ns-1.awsdns-00.com. awsdns-hostmaster.amazon.com. 1 7200 900 1209600 86400"),
+		alias("example.com.", rtypes.RRTypeA, "d111.cloudfront.net."),
+		plain("example.com.", rtypes.RRTypeMx, 300, "10 mail.example.com."),
+		plain("example.com.", rtypes.RRTypeTxt, 300, `"v=spf1 -all"`),
+		plain("\\052.example.com.", rtypes.RRTypeCname, 60, "example.com"),
+		weighted("api.example.com.", "blue", 10, "192.0.2.1"),
+		weighted("api.example.com.", "green", 90, "192.0.2.2"),
+		alias("www.example.com.", rtypes.RRTypeA, "example.com."),
+		geo,
+	}
+}
+
+func render(t *testing.T, f ExportFormat) string {
+	t.Helper()
+	zone, records := exportFixture()
+	var b bytes.Buffer
+	require.NoError(t, WriteZone(&b, f, zone, records))
+	return b.String()
+}
+
+func TestParseExportFormat(t *testing.T) {
+	f, err := ParseExportFormat("Terraform")
+	require.NoError(t, err)
+	require.Equal(t, FormatTerraform, f)
+	require.Equal(t, "tf", f.Extension())
+	_, err = ParseExportFormat("xml")
+	require.ErrorContains(t, err, `unknown export format "xml"`)
+}
+
+func TestWriteZone_DNSControl(t *testing.T) {
+	out := render(t, FormatDNSControl)
+	require.Contains(t, out, `D("example.com", REG_NONE, DnsProvider(DSP_R53),`)
+	require.Contains(t, out, "\tDefaultTTL(300),\n")
+	require.Contains(t, out, `R53_ALIAS("@", "A", "d111.cloudfront.net.", R53_ZONE("Z2FDTNDATAQYW2")),`)
+	require.Contains(t, out, `MX("@", 10, "mail.example.com."),`)
+	require.Contains(t, out, `TXT("@", "v=spf1 -all"),`)
+	// Route53 names are absolute even without the trailing dot.
+	require.Contains(t, out, `CNAME("*", "example.com.", TTL(60)),`)
+	// dnscontrol cannot route, the sets are left alone.
+	require.Contains(t, out, "// api.example.com. A is routed (set_identifier=blue weight=10)\n\tIGNORE(\"api\", \"A\"),")
+	require.Contains(t, out, "health_check_id=hc-1)\n\tIGNORE(\"geo\", \"A\")\n);\n")
+	require.NotContains(t, out, "SOA")
+	require.NotContains(t, out, "awsdns")
+}
+
+func TestWriteZone_OctoDNS(t *testing.T) {
+	out := render(t, FormatOctoDNS)
+	var zone map[string]any
+	require.NoError(t, yaml.Unmarshal([]byte(out), &zone))
+	require.NotContains(t, zone, "geo.example.com")
+
+	api := zone["api"].(map[string]any)
+	require.Equal(t, []any{"192.0.2.1", "192.0.2.2"}, api["values"])
+	pool := api["dynamic"].(map[string]any)["pools"].(map[string]any)["weighted"].(map[string]any)
+	require.Equal(t, []any{
+		map[string]any{"value": "192.0.2.1", "weight": 10},
+		map[string]any{"value": "192.0.2.2", "weight": 90},
+	}, pool["values"])
+
+	require.Equal(t, map[string]any{"type": "CNAME", "ttl": 60, "value": "example.com."}, zone["*"])
+	require.Equal(t, map[string]any{"type": "Route53Provider/ALIAS", "value": map[string]any{
+		"type": "A", "evaluate-target-health": false,
+	}}, zone["www"])
+	require.Len(t, zone[""], 2, "MX and TXT, the CloudFront alias cannot be expressed")
+	require.Contains(t, out, "# example.com. A is an alias to d111.cloudfront.net. (zone Z2FDTNDATAQYW2), not supported by octoDNS")
+	require.Contains(t, out, "# geo.example.com. A has health check hc-1\n")
+}
+
+func TestWriteZone_Terraform(t *testing.T) {
+	out := render(t, FormatTerraform)
+	require.Contains(t, out, `resource "aws_route53_record" "api_a_blue" {
+  zone_id        = "Z123"
+  name           = "api.example.com"
+  type           = "A"
+  ttl            = 60
+  records        = ["192.0.2.1"]
+  set_identifier = "blue"
+
+  weighted_routing_policy {
+    weight = 10
+  }
+}
+
+import {
+  to = aws_route53_record.api_a_blue
+  id = "Z123_api.example.com_A_blue"
+}
+`)
+	require.Contains(t, out, `  alias {
+    name                   = "d111.cloudfront.net."
+    zone_id                = "Z2FDTNDATAQYW2"
+    evaluate_target_health = false
+  }`)
+	require.Contains(t, out, `records = ["v=spf1 -all"]`)
+	require.Contains(t, out, `id = "Z123_*.example.com_CNAME"`)
+	require.Contains(t, out, `health_check_id = "hc-1"`)
+	require.Contains(t, out, "geolocation_routing_policy {\n    continent = \"EU\"\n  }")
+	require.NotContains(t, out, `"SOA"`)
+}
+
+func TestWriteZone_CidrAndGeoProximity(t *testing.T) {
+	zone, _ := exportFixture()
+	byCidr := plain("ip.example.com.", rtypes.RRTypeA, 60, "192.0.2.20")
+	byCidr.SetIdentifier = aws.String("office")
+	byCidr.CidrRoutingConfig = &rtypes.CidrRoutingConfig{CollectionId: aws.String("c-1"), LocationName: aws.String("hq")}
+	byPoint := plain("near.example.com.", rtypes.RRTypeA, 60, "192.0.2.22")
+	byPoint.SetIdentifier = aws.String("point")
+	byPoint.GeoProximityLocation = &rtypes.GeoProximityLocation{
+		Bias:        aws.Int32(-5),
+		Coordinates: &rtypes.Coordinates{Latitude: aws.String("49.22"), Longitude: aws.String("-74.01")},
+	}
+	records := []rtypes.ResourceRecordSet{byCidr, byPoint}
+
+	var b bytes.Buffer
+	require.NoError(t, WriteZone(&b, FormatTerraform, zone, records))
+	require.Contains(t, b.String(), "cidr_routing_policy {\n    collection_id = \"c-1\"\n    location_name = \"hq\"\n  }")
+	require.Contains(t, b.String(), "geoproximity_routing_policy {\n    bias = -5\n\n    coordinates {\n      latitude  = \"49.22\"\n      longitude = \"-74.01\"\n    }\n  }")
+
+	var tmpl struct {
+		Resources map[string]struct{ Properties map[string]any }
+	}
+	b.Reset()
+	require.NoError(t, WriteZone(&b, FormatCloudFormation, zone, records))
+	require.NoError(t, json.Unmarshal(b.Bytes(), &tmpl))
+	require.Equal(t, map[string]any{"CollectionId": "c-1", "LocationName": "hq"}, tmpl.Resources["IpOfficeA"].Properties["CidrRoutingConfig"])
+	require.Equal(t, map[string]any{"Bias": float64(-5), "Coordinates": map[string]any{"Latitude": "49.22", "Longitude": "-74.01"}},
+		tmpl.Resources["NearPointA"].Properties["GeoProximityLocation"])
+
+	require.Equal(t, "set_identifier=office cidr=c-1/hq", routingDescription(byCidr))
+	require.Equal(t, "set_identifier=point geoproximity=49.22,-74.01 bias=-5", routingDescription(byPoint))
+}
+
+func TestTerraformHelpers(t *testing.T) {
+	require.Equal(t, `"say \"hi\" $${name} %%{if}"`, hclString(`say "hi" ${name} %{if}`))
+	require.Equal(t, `one""two`, terraformValue(rtypes.RRTypeTxt, `"one" "two"`))
+	require.Equal(t, "10 mx.example.com.", terraformValue(rtypes.RRTypeMx, "10 mx.example.com."))
+
+	names := map[string]int{}
+	require.Equal(t, "_1_a", uniqueName(names, terraformName("example.com", plain("1.example.com.", rtypes.RRTypeA, 60))))
+	require.Equal(t, "_1_a_2", uniqueName(names, terraformName("example.com", plain("1.example.com.", rtypes.RRTypeA, 60))))
+}
+
+func TestWriteZone_CloudFormation(t *testing.T) {
+	var tmpl struct {
+		Resources map[string]struct {
+			Type       string
+			Properties map[string]any
+		}
+	}
+	require.NoError(t, json.Unmarshal([]byte(render(t, FormatCloudFormation)), &tmpl))
+	require.Len(t, tmpl.Resources, 8)
+	require.Equal(t, "AWS::Route53::RecordSet", tmpl.Resources["ApiBlueA"].Type)
+	require.Equal(t, map[string]any{
+		"HostedZoneId": "Z123", "Name": "geo.example.com.", "Type": "A", "TTL": "300",
+		"ResourceRecords": []any{"192.0.2.9"}, "SetIdentifier": "eu",
+		"GeoLocation": map[string]any{"ContinentCode": "EU"}, "HealthCheckId": "hc-1",
+	}, tmpl.Resources["GeoEuA"].Properties)
+	require.Equal(t, map[string]any{
+		"DNSName": "d111.cloudfront.net.", "HostedZoneId": "Z2FDTNDATAQYW2", "EvaluateTargetHealth": false,
+	}, tmpl.Resources["ApexA"].Properties["AliasTarget"])
+	require.Equal(t, "*.example.com.", tmpl.Resources["WildcardCNAME"].Properties["Name"])
+}
+
+func TestWriteZone_JSONAndYAML(t *testing.T) {
+	var fromJSON, fromYAML ExportedZone
+	require.NoError(t, json.Unmarshal([]byte(render(t, FormatJSON)), &fromJSON))
+	require.NoError(t, yaml.Unmarshal([]byte(render(t, FormatYAML)), &fromYAML))
+	require.Equal(t, fromJSON, fromYAML)
+
+	require.Equal(t, "example.com.", fromJSON.Zone)
+	require.Equal(t, "Z123", fromJSON.ZoneID)
+	// Everything is kept, including the apex NS and SOA.
+	require.Len(t, fromJSON.RecordSets, 10)
+	require.Equal(t, ExportedRecordSet{
+		Name: "geo.example.com.", Type: "A", TTL: aws.Int64(300), Values: []string{"192.0.2.9"},
+		SetIdentifier: "eu", GeoLocation: &ExportedGeoLocation{ContinentCode: "EU"}, HealthCheckID: "hc-1",
+	}, fromJSON.RecordSets[9])
+	require.Equal(t, &ExportedAlias{DNSName: "d111.cloudfront.net.", HostedZoneID: "Z2FDTNDATAQYW2"}, fromJSON.RecordSets[2].Alias)
+	require.Equal(t, "*.example.com.", fromJSON.RecordSets[5].Name)
+}
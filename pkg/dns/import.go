@@ -0,0 +1,295 @@
+package dns
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	rtypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	mdns "github.com/miekg/dns"
+	"gopkg.in/yaml.v3"
+)
+
+// routedSetTag starts the BIND comment holding a record set that plain zone
+// file lines cannot describe, as the JSON of its ExportedRecordSet.
+const routedSetTag = "R53_SET"
+
+const bindHeader = "Exported by r53tool. Zone: "
+
+// ImportFormats lists the export formats ReadZone understands.
+var ImportFormats = []ExportFormat{FormatBIND, FormatJSON, FormatYAML}
+
+// ReadZoneFile reads a zone exported by WriteZoneFile. The format is taken
+// from the file extension: .json and .yaml/.yml are read as ExportedZone,
+// anything else as BIND. origin is only needed for BIND files that lack the
+// r53tool header.
+func ReadZoneFile(path, origin string) (string, []rtypes.ResourceRecordSet, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	format := FormatBIND
+	switch {
+	case strings.HasSuffix(path, "."+FormatCloudFormation.Extension()):
+		return "", nil, fmt.Errorf("%s: CloudFormation templates cannot be imported, use one of %v", path, ImportFormats)
+	case filepath.Ext(path) == ".json":
+		format = FormatJSON
+	case filepath.Ext(path) == ".yaml" || filepath.Ext(path) == ".yml":
+		format = FormatYAML
+	}
+	zone, records, err := ReadZone(f, format, origin)
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return zone, records, nil
+}
+
+// ReadZone reads a zone written by WriteZone in format and returns its name
+// and record sets. Routing policies, health checks and alias targets come
+// back as they were exported, so the sets can be applied to Route53 again.
+func ReadZone(r io.Reader, format ExportFormat, origin string) (string, []rtypes.ResourceRecordSet, error) {
+	switch format {
+	case FormatBIND:
+		return readBind(r, origin)
+	case FormatJSON, FormatYAML:
+		var z ExportedZone
+		var err error
+		if format == FormatJSON {
+			err = json.NewDecoder(r).Decode(&z)
+		} else {
+			err = yaml.NewDecoder(r).Decode(&z)
+		}
+		if err != nil {
+			return "", nil, err
+		}
+		if z.Zone == "" {
+			return "", nil, fmt.Errorf("not an r53tool %s export: no zone", format)
+		}
+		records := []rtypes.ResourceRecordSet{}
+		for _, e := range z.RecordSets {
+			records = append(records, e.RecordSet())
+		}
+		return NormalizeDomain(z.Zone), records, nil
+	}
+	return "", nil, fmt.Errorf("%s exports cannot be imported, use one of %v", format, ImportFormats)
+}
+
+// RecordSet converts an exported record set back to its Route53 form.
+func (e ExportedRecordSet) RecordSet() rtypes.ResourceRecordSet {
+	rs := rtypes.ResourceRecordSet{
+		Name:     aws.String(escapeName(e.Name)),
+		Type:     rtypes.RRType(e.Type),
+		TTL:      e.TTL,
+		Weight:   e.Weight,
+		Region:   rtypes.ResourceRecordSetRegion(e.Region),
+		Failover: rtypes.ResourceRecordSetFailover(e.Failover),
+	}
+	for _, v := range e.Values {
+		rs.ResourceRecords = append(rs.ResourceRecords, rtypes.ResourceRecord{Value: aws.String(v)})
+	}
+	if e.SetIdentifier != "" {
+		rs.SetIdentifier = aws.String(e.SetIdentifier)
+	}
+	if e.MultiValueAnswer {
+		rs.MultiValueAnswer = aws.Bool(true)
+	}
+	if e.HealthCheckID != "" {
+		rs.HealthCheckId = aws.String(e.HealthCheckID)
+	}
+	if a := e.Alias; a != nil {
+		rs.AliasTarget = &rtypes.AliasTarget{
+			DNSName:              aws.String(a.DNSName),
+			HostedZoneId:         aws.String(a.HostedZoneID),
+			EvaluateTargetHealth: a.EvaluateTargetHealth,
+		}
+	}
+	if g := e.GeoLocation; g != nil {
+		rs.GeoLocation = &rtypes.GeoLocation{
+			ContinentCode:   optionalString(g.ContinentCode),
+			CountryCode:     optionalString(g.CountryCode),
+			SubdivisionCode: optionalString(g.SubdivisionCode),
+		}
+	}
+	if g := e.GeoProximity; g != nil {
+		rs.GeoProximityLocation = &rtypes.GeoProximityLocation{
+			AWSRegion:      optionalString(g.AWSRegion),
+			LocalZoneGroup: optionalString(g.LocalZoneGroup),
+			Bias:           g.Bias,
+		}
+		if g.Latitude != "" || g.Longitude != "" {
+			rs.GeoProximityLocation.Coordinates = &rtypes.Coordinates{
+				Latitude:  aws.String(g.Latitude),
+				Longitude: aws.String(g.Longitude),
+			}
+		}
+	}
+	if c := e.CidrRouting; c != nil {
+		rs.CidrRoutingConfig = &rtypes.CidrRoutingConfig{
+			CollectionId: aws.String(c.CollectionID),
+			LocationName: aws.String(c.LocationName),
+		}
+	}
+	return rs
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return aws.String(s)
+}
+
+// escapeName turns a leading "*" label into Route53's octal escape.
+func escapeName(name string) string {
+	if name == "*" || strings.HasPrefix(name, "*.") {
+		return `\052` + name[1:]
+	}
+	return name
+}
+
+// routedSetComment describes rs as an R53_SET comment line for BIND.
+func routedSetComment(rs rtypes.ResourceRecordSet) string {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(NewExportedRecordSet(rs))
+	return fmt.Sprintf("; %s %s", routedSetTag, strings.TrimSpace(b.String()))
+}
+
+// readBind parses a BIND zone file. Plain records are grouped into record
+// sets by name and type; R53_ALIAS comments become alias record sets, and
+// R53_SET comments replace the plain records of their name and type.
+func readBind(r io.Reader, origin string) (string, []rtypes.ResourceRecordSet, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var aliasLines []string
+	routed := []rtypes.ResourceRecordSet{}
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if !strings.HasPrefix(line, ";") {
+			continue
+		}
+		comment := strings.TrimLeft(line, "; \t")
+		switch {
+		case strings.HasPrefix(comment, bindHeader) && origin == "":
+			origin = strings.TrimSpace(strings.TrimPrefix(comment, bindHeader))
+		case strings.HasPrefix(comment, routedSetTag+" "):
+			var e ExportedRecordSet
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(comment, routedSetTag+" ")), &e); err != nil {
+				return "", nil, fmt.Errorf("invalid %s comment %q: %w", routedSetTag, line, err)
+			}
+			routed = append(routed, e.RecordSet())
+		case strings.Contains(line, " IN R53_ALIAS "):
+			aliasLines = append(aliasLines, strings.TrimPrefix(line, ";"))
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return "", nil, err
+	}
+	if origin == "" {
+		return "", nil, fmt.Errorf("zone file has no r53tool header, the zone name must be given")
+	}
+	origin = NormalizeDomain(origin)
+
+	covered := map[string]bool{}
+	for _, rs := range routed {
+		covered[importKey(aws.ToString(rs.Name), string(rs.Type))] = true
+	}
+
+	records := []rtypes.ResourceRecordSet{}
+	index := map[string]int{}
+	zp := mdns.NewZoneParser(bytes.NewReader(data), origin, "")
+	for rr, ok := zp.Next(); ok; rr, ok = zp.Next() {
+		h := rr.Header()
+		rtype := mdns.TypeToString[h.Rrtype]
+		key := importKey(h.Name, rtype)
+		if covered[key] {
+			continue
+		}
+		value := strings.TrimPrefix(rr.String(), h.String())
+		if i, ok := index[key]; ok {
+			records[i].ResourceRecords = append(records[i].ResourceRecords, rtypes.ResourceRecord{Value: aws.String(value)})
+			continue
+		}
+		index[key] = len(records)
+		records = append(records, rtypes.ResourceRecordSet{
+			Name:            aws.String(h.Name),
+			Type:            rtypes.RRType(rtype),
+			TTL:             aws.Int64(int64(h.Ttl)),
+			ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String(value)}},
+		})
+	}
+	if err := zp.Err(); err != nil {
+		return "", nil, err
+	}
+
+	for _, line := range aliasLines {
+		rs, err := parseAliasLine(line, origin)
+		if err != nil {
+			return "", nil, err
+		}
+		if !covered[importKey(aws.ToString(rs.Name), string(rs.Type))] {
+			records = append(records, rs)
+		}
+	}
+	return origin, append(records, routed...), nil
+}
+
+func importKey(name, rtype string) string {
+	return strings.ToLower(NormalizeDomain(unescapeName(name))) + " " + rtype
+}
+
+// parseAliasLine reads an R53_ALIAS line as written by writeBind:
+//
+//	www  IN R53_ALIAS target.example.com. atype=A zone_id=Z2FDTNDATAQYW2 evaluate_target_health=false
+func parseAliasLine(line, origin string) (rtypes.ResourceRecordSet, error) {
+	fields := strings.Fields(line)
+	at := -1
+	for i, f := range fields {
+		if f == "R53_ALIAS" {
+			at = i
+			break
+		}
+	}
+	if at < 1 || at+1 >= len(fields) || fields[0] == "IN" {
+		return rtypes.ResourceRecordSet{}, fmt.Errorf("invalid R53_ALIAS line %q", line)
+	}
+
+	name := fields[0]
+	switch {
+	case name == "@":
+		name = origin
+	case !strings.HasSuffix(name, "."):
+		name = name + "." + origin
+	}
+	alias := &rtypes.AliasTarget{DNSName: aws.String(fields[at+1])}
+	rs := rtypes.ResourceRecordSet{Name: aws.String(name), AliasTarget: alias}
+	for _, kv := range fields[at+2:] {
+		k, v, _ := strings.Cut(kv, "=")
+		switch k {
+		case "atype":
+			rs.Type = rtypes.RRType(v)
+		case "zone_id":
+			alias.HostedZoneId = aws.String(v)
+		case "evaluate_target_health":
+			alias.EvaluateTargetHealth = v == "true"
+		}
+	}
+	if rs.Type == "" || alias.HostedZoneId == nil {
+		return rtypes.ResourceRecordSet{}, fmt.Errorf("invalid R53_ALIAS line %q: missing atype or zone_id", line)
+	}
+	return rs, nil
+}
@@ -0,0 +1,93 @@
+package dns
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	rtypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadZone_RoundTrip(t *testing.T) {
+	for _, f := range ImportFormats {
+		t.Run(string(f), func(t *testing.T) {
+			zone, records := exportFixture()
+			// BIND writes targets fully qualified.
+			records[5].ResourceRecords[0].Value = aws.String("example.com.")
+			failover := plain("db.example.com.", rtypes.RRTypeCname, 60, "db-1.example.net.")
+			failover.SetIdentifier = aws.String("primary")
+			failover.Failover = rtypes.ResourceRecordSetFailoverPrimary
+			failover.HealthCheckId = aws.String("hc-2")
+			routedAlias := alias("lb.example.com.", rtypes.RRTypeA, "lb-1.elb.amazonaws.com.")
+			routedAlias.SetIdentifier = aws.String("us")
+			routedAlias.Region = rtypes.ResourceRecordSetRegionUsEast1
+			byCidr := plain("ip.example.com.", rtypes.RRTypeA, 60, "192.0.2.20")
+			byCidr.SetIdentifier = aws.String("office")
+			byCidr.CidrRoutingConfig = &rtypes.CidrRoutingConfig{CollectionId: aws.String("c-1"), LocationName: aws.String("hq")}
+			byRegion := plain("near.example.com.", rtypes.RRTypeA, 60, "192.0.2.21")
+			byRegion.SetIdentifier = aws.String("us")
+			byRegion.GeoProximityLocation = &rtypes.GeoProximityLocation{AWSRegion: aws.String("us-east-1"), Bias: aws.Int32(10)}
+			byPoint := plain("near.example.com.", rtypes.RRTypeA, 60, "192.0.2.22")
+			byPoint.SetIdentifier = aws.String("point")
+			byPoint.GeoProximityLocation = &rtypes.GeoProximityLocation{
+				Coordinates: &rtypes.Coordinates{Latitude: aws.String("49.22"), Longitude: aws.String("-74.01")},
+			}
+			records = append(records, failover, routedAlias, byCidr, byRegion, byPoint)
+
+			var b bytes.Buffer
+			require.NoError(t, WriteZone(&b, f, zone, records))
+			name, got, err := ReadZone(&b, f, "")
+			require.NoError(t, err)
+			require.Equal(t, "example.com.", name)
+			require.Len(t, got, len(records))
+			d := CompareRecordSets(name, records, got)
+			require.True(t, d.Empty(), "%+v", d)
+		})
+	}
+}
+
+func TestReadZone_Bind(t *testing.T) {
+	zone := `$TTL 300
+www        IN A     192.0.2.1
+           IN A     192.0.2.2
+\052  60   IN TXT   "one" "two"
+;api      IN R53_ALIAS lb.example.net. atype=AAAA zone_id=Z1 evaluate_target_health=true
+`
+	_, _, err := ReadZone(strings.NewReader(zone), FormatBIND, "")
+	require.ErrorContains(t, err, "the zone name must be given")
+
+	name, got, err := ReadZone(strings.NewReader(zone), FormatBIND, "example.com")
+	require.NoError(t, err)
+	require.Equal(t, "example.com.", name)
+	require.Equal(t, []rtypes.ResourceRecordSet{
+		plain("www.example.com.", rtypes.RRTypeA, 300, "192.0.2.1", "192.0.2.2"),
+		plain(`\052.example.com.`, rtypes.RRTypeTxt, 60, `"one" "two"`),
+		{Name: aws.String("api.example.com."), Type: rtypes.RRTypeAaaa, AliasTarget: &rtypes.AliasTarget{
+			DNSName: aws.String("lb.example.net."), HostedZoneId: aws.String("Z1"), EvaluateTargetHealth: true,
+		}},
+	}, got)
+}
+
+func TestReadZoneFile_Formats(t *testing.T) {
+	dir := t.TempDir()
+	zone, records := exportFixture()
+	for _, f := range []ExportFormat{FormatJSON, FormatYAML, FormatCloudFormation} {
+		path := filepath.Join(dir, "example."+f.Extension())
+		require.NoError(t, WriteZoneFile(path, f, zone, records))
+		name, got, err := ReadZoneFile(path, "")
+		if f == FormatCloudFormation {
+			require.ErrorContains(t, err, "cannot be imported")
+			continue
+		}
+		require.NoError(t, err)
+		require.Equal(t, "example.com.", name)
+		require.Equal(t, records, got)
+	}
+
+	_, _, err := ReadZoneFile(filepath.Join(dir, "missing.zone"), "")
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,247 @@
+package dns
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	rtypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"gopkg.in/yaml.v3"
+)
+
+// octoDNS record types that take a single value rather than a list.
+var octoSingleValue = map[string]bool{"CNAME": true, "ALIAS": true}
+
+// octoDNS dynamic records only support these types.
+var octoDynamicTypes = map[rtypes.RRType]bool{rtypes.RRTypeA: true, rtypes.RRTypeAaaa: true, rtypes.RRTypeCname: true}
+
+type octoRecord struct {
+	Type    string       `yaml:"type"`
+	TTL     int64        `yaml:"ttl,omitempty"`
+	Value   any          `yaml:"value,omitempty"`
+	Values  []any        `yaml:"values,omitempty"`
+	Dynamic *octoDynamic `yaml:"dynamic,omitempty"`
+}
+
+type octoDynamic struct {
+	Pools map[string]octoPool `yaml:"pools"`
+	Rules []octoRule          `yaml:"rules"`
+}
+
+type octoPool struct {
+	Values []octoPoolValue `yaml:"values"`
+}
+
+type octoPoolValue struct {
+	Value  string `yaml:"value"`
+	Weight int64  `yaml:"weight"`
+}
+
+type octoRule struct {
+	Pool string `yaml:"pool"`
+}
+
+type octoAlias struct {
+	Name                 string `yaml:"name,omitempty"`
+	Type                 string `yaml:"type"`
+	EvaluateTargetHealth bool   `yaml:"evaluate-target-health"`
+}
+
+// writeOctoDNS renders an octoDNS YAML zone. Weighted A, AAAA and CNAME
+// sets become a dynamic record; other routing policies, aliases outside the
+// zone and health checks are noted in comments.
+func writeOctoDNS(w io.Writer, zone rtypes.HostedZone, records []rtypes.ResourceRecordSet) error {
+	origin := DenormalizeDomain(strings.ToLower(aws.ToString(zone.Name)))
+	records = managedRecordSets(origin, records)
+
+	// octoDNS has one record per name and type, routed sets are grouped.
+	type group struct {
+		label string
+		sets  []rtypes.ResourceRecordSet
+	}
+	groups := []*group{}
+	byKey := map[string]*group{}
+	for _, rs := range records {
+		label := octoLabel(origin, aws.ToString(rs.Name))
+		k := label + " " + string(rs.Type)
+		if g, ok := byKey[k]; ok {
+			g.sets = append(g.sets, rs)
+			continue
+		}
+		g := &group{label: label, sets: []rtypes.ResourceRecordSet{rs}}
+		byKey[k] = g
+		groups = append(groups, g)
+	}
+
+	recs := map[string][]octoRecord{}
+	notes := map[string][]string{}
+	for _, g := range groups {
+		rec, n, ok := octoRecordFor(origin, g.sets)
+		notes[g.label] = append(notes[g.label], n...)
+		if ok {
+			recs[g.label] = append(recs[g.label], rec)
+		}
+	}
+
+	labels := []string{}
+	for label := range notes {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	header := []string{fmt.Sprintf("Exported by r53tool from %s (%s).",
+		strings.TrimPrefix(aws.ToString(zone.Id), "/hostedzone/"), origin)}
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	for _, label := range labels {
+		if len(recs[label]) == 0 {
+			// Nothing octoDNS can hold, the notes go in the header.
+			header = append(header, notes[label]...)
+			continue
+		}
+		key := &yaml.Node{}
+		if err := key.Encode(label); err != nil {
+			return err
+		}
+		key.HeadComment = strings.Join(notes[label], "\n")
+		value := &yaml.Node{}
+		var err error
+		if len(recs[label]) == 1 {
+			err = value.Encode(recs[label][0])
+		} else {
+			err = value.Encode(recs[label])
+		}
+		if err != nil {
+			return err
+		}
+		root.Content = append(root.Content, key, value)
+	}
+
+	doc := &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{root}, HeadComment: strings.Join(header, "\n")}
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// octoRecordFor converts the sets sharing a name and type into one octoDNS
+// record, with notes on whatever could not be carried over.
+func octoRecordFor(origin string, sets []rtypes.ResourceRecordSet) (octoRecord, []string, bool) {
+	first := sets[0]
+	name := fmt.Sprintf("%s %s", unescapeName(aws.ToString(first.Name)), first.Type)
+	notes := []string{}
+	for _, rs := range sets {
+		if rs.HealthCheckId != nil {
+			notes = append(notes, fmt.Sprintf("%s has health check %s", name, aws.ToString(rs.HealthCheckId)))
+		}
+	}
+
+	if first.AliasTarget != nil {
+		target := DenormalizeDomain(strings.ToLower(aws.ToString(first.AliasTarget.DNSName)))
+		if len(sets) > 1 || (target != origin && !strings.HasSuffix(target, "."+origin)) {
+			notes = append(notes, fmt.Sprintf("%s is an alias to %s (zone %s), not supported by octoDNS",
+				name, aws.ToString(first.AliasTarget.DNSName), aws.ToString(first.AliasTarget.HostedZoneId)))
+			return octoRecord{}, notes, false
+		}
+		return octoRecord{Type: "Route53Provider/ALIAS", Value: octoAlias{
+			Name:                 octoLabel(origin, target),
+			Type:                 string(first.Type),
+			EvaluateTargetHealth: first.AliasTarget.EvaluateTargetHealth,
+		}}, notes, true
+	}
+
+	rec := octoRecord{Type: string(first.Type), TTL: aws.ToInt64(first.TTL)}
+
+	if hasRouting(first) {
+		if dyn, ok := octoWeighted(sets); ok {
+			rec.Dynamic = dyn
+		} else {
+			for _, rs := range sets {
+				notes = append(notes, fmt.Sprintf("%s is routed (%s)", name, routingDescription(rs)))
+			}
+			notes = append(notes, "octoDNS cannot express this routing policy, the values are merged")
+		}
+	}
+
+	values := []any{}
+	seen := map[string]bool{}
+	for _, rs := range sets {
+		// Secondaries only answer when the primary is unhealthy.
+		if rs.Failover == rtypes.ResourceRecordSetFailoverSecondary {
+			continue
+		}
+		vs, ok := octoValues(origin, rs)
+		if !ok {
+			notes = append(notes, fmt.Sprintf("%s is not supported by this export", name))
+			return octoRecord{}, notes, false
+		}
+		for _, v := range vs {
+			k := fmt.Sprint(v)
+			if !seen[k] {
+				seen[k] = true
+				values = append(values, v)
+			}
+		}
+	}
+	if len(values) == 1 && octoSingleValue[rec.Type] {
+		rec.Value = values[0]
+	} else {
+		rec.Values = values
+	}
+	return rec, notes, true
+}
+
+// octoWeighted turns weighted sets into a dynamic record with one pool.
+func octoWeighted(sets []rtypes.ResourceRecordSet) (*octoDynamic, bool) {
+	pool := octoPool{}
+	for _, rs := range sets {
+		// octoDNS weights run from 1 to 100.
+		weight := aws.ToInt64(rs.Weight)
+		if rs.Weight == nil || weight < 1 || weight > 100 || !octoDynamicTypes[rs.Type] {
+			return nil, false
+		}
+		for _, v := range rs.ResourceRecords {
+			pool.Values = append(pool.Values, octoPoolValue{Value: aws.ToString(v.Value), Weight: weight})
+		}
+	}
+	return &octoDynamic{
+		Pools: map[string]octoPool{"weighted": pool},
+		Rules: []octoRule{{Pool: "weighted"}},
+	}, true
+}
+
+func octoValues(origin string, rs rtypes.ResourceRecordSet) ([]any, bool) {
+	values := []any{}
+	for _, rc := range modelRecords(origin, rs) {
+		switch rc.Type {
+		case "A", "AAAA", "CNAME", "NS", "PTR":
+			values = append(values, rc.GetTargetField())
+		case "MX":
+			values = append(values, map[string]any{"exchange": rc.GetTargetField(), "preference": rc.MxPreference})
+		case "SRV":
+			values = append(values, map[string]any{
+				"priority": rc.SrvPriority, "weight": rc.SrvWeight, "port": rc.SrvPort, "target": rc.GetTargetField(),
+			})
+		case "CAA":
+			values = append(values, map[string]any{"flags": rc.CaaFlag, "tag": rc.CaaTag, "value": rc.GetTargetField()})
+		case "TXT", "SPF":
+			// octoDNS needs semicolons escaped.
+			values = append(values, strings.ReplaceAll(rc.GetTargetTXTJoined(), ";", `\;`))
+		default:
+			return nil, false
+		}
+	}
+	return values, true
+}
+
+// octoLabel is name relative to origin, "" for the apex.
+func octoLabel(origin, name string) string {
+	name = DenormalizeDomain(strings.ToLower(unescapeName(name)))
+	if name == origin {
+		return ""
+	}
+	return strings.TrimSuffix(name, "."+origin)
+}
@@ -0,0 +1,150 @@
+package dns
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	rtypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"gopkg.in/yaml.v3"
+)
+
+// ParkingTTL is the TTL of parking records that do not set one.
+const ParkingTTL = 3600
+
+// DefaultParkingTemplate is the built-in template park uses by default.
+const DefaultParkingTemplate = "parked"
+
+//go:embed templates/*.yaml
+var builtinParkingTemplates embed.FS
+
+// ParkingTemplate lists the records a parked domain gets on top of its apex
+// target and www CNAME. Names and values are text/template strings where
+// {{.Domain}} is the zone name without the trailing dot.
+type ParkingTemplate struct {
+	Name        string                  `yaml:"name"`
+	Description string                  `yaml:"description,omitempty"`
+	Records     []ParkingTemplateRecord `yaml:"records"`
+}
+
+// ParkingTemplateRecord is one record set of a ParkingTemplate.
+type ParkingTemplateRecord struct {
+	Name   string   `yaml:"name"`
+	Type   string   `yaml:"type"`
+	TTL    int64    `yaml:"ttl,omitempty"`
+	Values []string `yaml:"values"`
+}
+
+// parkingTemplateData is what the placeholders of a template can use.
+type parkingTemplateData struct {
+	Domain string
+}
+
+// BuiltinParkingTemplates returns the names of the built-in templates.
+func BuiltinParkingTemplates() []string {
+	entries, _ := builtinParkingTemplates.ReadDir("templates")
+	names := []string{}
+	for _, e := range entries {
+		names = append(names, strings.TrimSuffix(e.Name(), ".yaml"))
+	}
+	return names
+}
+
+// LoadParkingTemplate returns the built-in template called name, or reads
+// name as a YAML file.
+func LoadParkingTemplate(name string) (*ParkingTemplate, error) {
+	data, err := builtinParkingTemplates.ReadFile("templates/" + name + ".yaml")
+	if err != nil {
+		if data, err = os.ReadFile(name); err != nil {
+			return nil, fmt.Errorf("parking template %q is neither built in (%v) nor a readable file: %w", name, BuiltinParkingTemplates(), err)
+		}
+	}
+	t, err := ParseParkingTemplate(data)
+	if err != nil {
+		return nil, fmt.Errorf("parking template %s: %w", name, err)
+	}
+	return t, nil
+}
+
+// ParseParkingTemplate parses a YAML parking template and checks it renders.
+func ParseParkingTemplate(data []byte) (*ParkingTemplate, error) {
+	t := &ParkingTemplate{}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(t); err != nil {
+		return nil, err
+	}
+	if _, err := t.RecordSets("example.com"); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// RecordSets renders the template for domain.
+func (t *ParkingTemplate) RecordSets(domain string) ([]rtypes.ResourceRecordSet, error) {
+	data := parkingTemplateData{Domain: DenormalizeDomain(domain)}
+	apex := strings.ToLower(NormalizeDomain(data.Domain))
+	seen := map[string]bool{}
+
+	records := []rtypes.ResourceRecordSet{}
+	for i, r := range t.Records {
+		rtype := rtypes.RRType(strings.ToUpper(r.Type))
+		if !slices.Contains(rtype.Values(), rtype) {
+			return nil, fmt.Errorf("record %d: unknown type %q", i+1, r.Type)
+		}
+		if len(r.Values) == 0 {
+			return nil, fmt.Errorf("record %d: no values", i+1)
+		}
+		name, err := renderParkingField(r.Name, data)
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %w", i+1, err)
+		}
+		name = NormalizeDomain(name)
+		lower := strings.ToLower(name)
+		if lower != apex && !strings.HasSuffix(lower, "."+apex) {
+			return nil, fmt.Errorf("record %d: %s is outside %s", i+1, name, apex)
+		}
+		// park writes these itself from its arguments.
+		if (lower == apex && (rtype == rtypes.RRTypeA || rtype == rtypes.RRTypeAaaa)) ||
+			(lower == "www."+apex && rtype == rtypes.RRTypeCname) {
+			return nil, fmt.Errorf("record %d: %s %s is created by park itself", i+1, name, rtype)
+		}
+		key := lower + " " + string(rtype)
+		if seen[key] {
+			return nil, fmt.Errorf("record %d: %s %s is listed twice, put all values in one record", i+1, name, rtype)
+		}
+		seen[key] = true
+
+		ttl := r.TTL
+		if ttl == 0 {
+			ttl = ParkingTTL
+		}
+		rs := rtypes.ResourceRecordSet{Name: aws.String(name), Type: rtype, TTL: aws.Int64(ttl)}
+		for _, v := range r.Values {
+			value, err := renderParkingField(v, data)
+			if err != nil {
+				return nil, fmt.Errorf("record %d: %w", i+1, err)
+			}
+			rs.ResourceRecords = append(rs.ResourceRecords, rtypes.ResourceRecord{Value: aws.String(value)})
+		}
+		records = append(records, rs)
+	}
+	return records, nil
+}
+
+func renderParkingField(field string, data parkingTemplateData) (string, error) {
+	tmpl, err := template.New("").Option("missingkey=error").Parse(field)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,67 @@
+package dns
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	rtypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadParkingTemplate_Parked(t *testing.T) {
+	require.Equal(t, []string{"minimal", "parked"}, BuiltinParkingTemplates())
+
+	tmpl, err := LoadParkingTemplate(DefaultParkingTemplate)
+	require.NoError(t, err)
+	records, err := tmpl.RecordSets("example.com.")
+	require.NoError(t, err)
+	require.Equal(t, []rtypes.ResourceRecordSet{
+		plain("example.com.", rtypes.RRTypeMx, ParkingTTL, "0 ."),
+		plain("example.com.", rtypes.RRTypeTxt, ParkingTTL, `"v=spf1 -all"`),
+		plain("_dmarc.example.com.", rtypes.RRTypeTxt, ParkingTTL, `"v=DMARC1; p=reject; sp=reject; adkim=s; aspf=s"`),
+		plain("example.com.", rtypes.RRTypeCaa, ParkingTTL, `0 issue ";"`, `0 issuewild ";"`),
+		plain("*.example.com.", rtypes.RRTypeCname, ParkingTTL, "example.com."),
+	}, records)
+
+	tmpl, err = LoadParkingTemplate("minimal")
+	require.NoError(t, err)
+	require.Empty(t, tmpl.Records)
+}
+
+func TestLoadParkingTemplate_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "custom.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+name: custom
+records:
+  - name: "{{.Domain}}"
+    type: txt
+    ttl: 60
+    values: ['"parked by {{.Domain}}"']
+`), 0o644))
+
+	tmpl, err := LoadParkingTemplate(path)
+	require.NoError(t, err)
+	records, err := tmpl.RecordSets("example.org")
+	require.NoError(t, err)
+	require.Equal(t, []rtypes.ResourceRecordSet{plain("example.org.", rtypes.RRTypeTxt, 60, `"parked by example.org"`)}, records)
+
+	_, err = LoadParkingTemplate("no-such-template")
+	require.ErrorContains(t, err, `parking template "no-such-template" is neither built in ([minimal parked])`)
+}
+
+func TestParseParkingTemplate_Invalid(t *testing.T) {
+	for _, tc := range []struct{ yaml, err string }{
+		{`records: [{name: "{{.Domain}}", type: BOGUS, values: [x]}]`, `unknown type "BOGUS"`},
+		{`records: [{name: "{{.Domain}}", type: TXT}]`, "no values"},
+		{`records: [{name: "{{.Zone}}", type: TXT, values: [x]}]`, "can't evaluate field Zone"},
+		{`records: [{name: "other.net", type: TXT, values: [x]}]`, "other.net. is outside example.com."},
+		{`records: [{name: "www.{{.Domain}}", type: CNAME, values: [x]}]`, "is created by park itself"},
+		{`records: [{name: "{{.Domain}}", type: TXT, values: [a]}, {name: "{{.Domain}}", type: TXT, values: [b]}]`, "listed twice"},
+		{`records: []
+extra: true`, "field extra not found"},
+	} {
+		_, err := ParseParkingTemplate([]byte(tc.yaml))
+		require.ErrorContains(t, err, tc.err, tc.yaml)
+	}
+}
@@ -0,0 +1,222 @@
+package dns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/route53"
+	rtypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
+)
+
+// VPC identifies a VPC a private hosted zone is associated with.
+type VPC struct {
+	ID     string `json:"id" yaml:"id"`
+	Region string `json:"region" yaml:"region"`
+}
+
+func (v VPC) String() string {
+	if v.Region == "" {
+		return v.ID
+	}
+	return fmt.Sprintf("%s (%s)", v.ID, v.Region)
+}
+
+// ZoneFilter narrows a lookup by name when a public and a private zone, or
+// several private zones, share the same name.
+type ZoneFilter struct {
+	PrivateOnly bool
+	PublicOnly  bool
+	// VPC selects private zones associated with this VPC. An empty Region
+	// matches the VPC in any region.
+	VPC VPC
+}
+
+// NeedsVPCs reports whether Match needs the zone's VPC associations.
+func (f ZoneFilter) NeedsVPCs() bool {
+	return f.VPC.ID != ""
+}
+
+// Match reports whether zone passes the filter. vpcs are the zone's
+// associations and are only consulted when NeedsVPCs is true.
+func (f ZoneFilter) Match(zone rtypes.HostedZone, vpcs []VPC) bool {
+	private := IsPrivateZone(zone)
+	if (f.PrivateOnly || f.NeedsVPCs()) && !private {
+		return false
+	}
+	if f.PublicOnly && private {
+		return false
+	}
+	if !f.NeedsVPCs() {
+		return true
+	}
+	for _, v := range vpcs {
+		if v.ID == f.VPC.ID && (f.VPC.Region == "" || v.Region == f.VPC.Region) {
+			return true
+		}
+	}
+	return false
+}
+
+func IsPrivateZone(zone rtypes.HostedZone) bool {
+	return zone.Config != nil && zone.Config.PrivateZone
+}
+
+// AmbiguousHostedZone is returned when more than one hosted zone matches a
+// lookup by name.
+type AmbiguousHostedZone struct {
+	Zone  string
+	Zones []rtypes.HostedZone
+}
+
+func (e *AmbiguousHostedZone) Error() string {
+	ids := []string{}
+	for _, z := range e.Zones {
+		kind := "public"
+		if IsPrivateZone(z) {
+			kind = "private"
+		}
+		ids = append(ids, fmt.Sprintf("%s (%s)", aws.ToString(z.Id), kind))
+	}
+	return fmt.Sprintf("%d hosted zones named %s: %s; address the zone by its ID instead",
+		len(e.Zones), e.Zone, strings.Join(ids, ", "))
+}
+
+// CreateZoneOptions describes the zone GetOrCreateZone creates when it is
+// missing. Private zones need at least one VPC.
+type CreateZoneOptions struct {
+	Private bool
+	VPCs    []VPC
+}
+
+// Filter finds an existing zone matching the options.
+func (o CreateZoneOptions) Filter() ZoneFilter {
+	if !o.Private {
+		return ZoneFilter{PublicOnly: true}
+	}
+	f := ZoneFilter{PrivateOnly: true}
+	if len(o.VPCs) > 0 {
+		f.VPC = o.VPCs[0]
+	}
+	return f
+}
+
+// zonesNamed returns every hosted zone whose name is exactly domain.
+func (r *RouteManager) zonesNamed(ctx context.Context, domain string) ([]rtypes.HostedZone, error) {
+	name := NormalizeDomain(domain)
+	params := &route53.ListHostedZonesByNameInput{
+		DNSName:  aws.String(name),
+		MaxItems: aws.Int32(100),
+	}
+
+	zones := []rtypes.HostedZone{}
+	for {
+		resp, err := r.cli.ListHostedZonesByName(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		for _, z := range resp.HostedZones {
+			// Results are sorted by name, so the first other name ends the
+			// run of zones sharing this one.
+			if !strings.EqualFold(aws.ToString(z.Name), name) {
+				return zones, nil
+			}
+			zones = append(zones, z)
+		}
+		if !resp.IsTruncated {
+			return zones, nil
+		}
+		params.DNSName = resp.NextDNSName
+		params.HostedZoneId = resp.NextHostedZoneId
+	}
+}
+
+// FindHostedZone looks a zone up by name, keeping only the zones that pass
+// f. It fails with AmbiguousHostedZone when more than one remains. domain may
+// also be a zone ID, which is fetched directly.
+func (r *RouteManager) FindHostedZone(ctx context.Context, domain string, f ZoneFilter) (rtypes.HostedZone, error) {
+	if IsZoneID(domain) {
+		return r.hostedZoneByID(ctx, domain, f)
+	}
+
+	candidates, err := r.zonesNamed(ctx, domain)
+	if err != nil {
+		return rtypes.HostedZone{}, err
+	}
+
+	matched := []rtypes.HostedZone{}
+	for _, z := range candidates {
+		var vpcs []VPC
+		if f.NeedsVPCs() && IsPrivateZone(z) {
+			vpcs, err = r.GetZoneVPCs(ctx, aws.ToString(z.Id))
+			if err != nil {
+				return rtypes.HostedZone{}, err
+			}
+		}
+		if f.Match(z, vpcs) {
+			matched = append(matched, z)
+		}
+	}
+
+	switch len(matched) {
+	case 0:
+		return rtypes.HostedZone{}, &HostedZoneNotFound{Zone: domain}
+	case 1:
+		return matched[0], nil
+	default:
+		return rtypes.HostedZone{}, &AmbiguousHostedZone{Zone: NormalizeDomain(domain), Zones: matched}
+	}
+}
+
+func (r *RouteManager) hostedZoneByID(ctx context.Context, id string, f ZoneFilter) (rtypes.HostedZone, error) {
+	resp, err := r.cli.GetHostedZone(ctx, &route53.GetHostedZoneInput{Id: aws.String(id)})
+	if err != nil {
+		var nf *rtypes.NoSuchHostedZone
+		if errors.As(err, &nf) {
+			return rtypes.HostedZone{}, &HostedZoneNotFound{Zone: id}
+		}
+		return rtypes.HostedZone{}, err
+	}
+
+	vpcs := []VPC{}
+	for _, v := range resp.VPCs {
+		vpcs = append(vpcs, VPC{ID: aws.ToString(v.VPCId), Region: string(v.VPCRegion)})
+	}
+	if !f.Match(*resp.HostedZone, vpcs) {
+		return rtypes.HostedZone{}, &HostedZoneNotFound{Zone: id}
+	}
+	return *resp.HostedZone, nil
+}
+
+// GetZoneVPCs returns the VPCs a private zone is associated with.
+func (r *RouteManager) GetZoneVPCs(ctx context.Context, zoneID string) ([]VPC, error) {
+	resp, err := r.cli.GetHostedZone(ctx, &route53.GetHostedZoneInput{
+		Id: aws.String(zoneID),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	vpcs := []VPC{}
+	for _, v := range resp.VPCs {
+		vpcs = append(vpcs, VPC{ID: aws.ToString(v.VPCId), Region: string(v.VPCRegion)})
+	}
+	return vpcs, nil
+}
+
+// AssociateVPC associates a VPC with a private zone. The VPC must be in the
+// caller's account or have been authorized by the zone owner.
+func (r *RouteManager) AssociateVPC(ctx context.Context, zoneID string, vpc VPC) error {
+	resp, err := r.cli.AssociateVPCWithHostedZone(ctx, &route53.AssociateVPCWithHostedZoneInput{
+		HostedZoneId: aws.String(zoneID),
+		VPC:          &rtypes.VPC{VPCId: aws.String(vpc.ID), VPCRegion: rtypes.VPCRegion(vpc.Region)},
+		Comment:      aws.String("Associated by route53copy"),
+	})
+	if err != nil {
+		return err
+	}
+	return r.WaitForChange(ctx, r.track(resp.ChangeInfo), 1*time.Minute)
+}
@@ -0,0 +1,40 @@
+package dns
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	rtypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestZoneFilterMatch(t *testing.T) {
+	public := rtypes.HostedZone{Id: aws.String("/hostedzone/Z1"), Config: &rtypes.HostedZoneConfig{}}
+	private := rtypes.HostedZone{Id: aws.String("/hostedzone/Z2"), Config: &rtypes.HostedZoneConfig{PrivateZone: true}}
+	vpcs := []VPC{{ID: "vpc-1", Region: "us-east-1"}}
+
+	require.True(t, ZoneFilter{}.Match(public, nil))
+	require.True(t, ZoneFilter{}.Match(private, nil))
+	require.True(t, ZoneFilter{PublicOnly: true}.Match(public, nil))
+	require.False(t, ZoneFilter{PublicOnly: true}.Match(private, nil))
+	require.False(t, ZoneFilter{PrivateOnly: true}.Match(public, nil))
+
+	require.True(t, ZoneFilter{VPC: VPC{ID: "vpc-1"}}.Match(private, vpcs))
+	require.True(t, ZoneFilter{VPC: VPC{ID: "vpc-1", Region: "us-east-1"}}.Match(private, vpcs))
+	require.False(t, ZoneFilter{VPC: VPC{ID: "vpc-1", Region: "eu-west-1"}}.Match(private, vpcs))
+	require.False(t, ZoneFilter{VPC: VPC{ID: "vpc-1"}}.Match(public, vpcs))
+}
+
+func TestCreateZoneOptionsFilter(t *testing.T) {
+	require.Equal(t, ZoneFilter{PublicOnly: true}, CreateZoneOptions{}.Filter())
+	require.Equal(t, ZoneFilter{PrivateOnly: true, VPC: VPC{ID: "vpc-1", Region: "us-east-1"}},
+		CreateZoneOptions{Private: true, VPCs: []VPC{{ID: "vpc-1", Region: "us-east-1"}, {ID: "vpc-2"}}}.Filter())
+}
+
+func TestAmbiguousHostedZoneError(t *testing.T) {
+	err := &AmbiguousHostedZone{Zone: "example.com.", Zones: []rtypes.HostedZone{
+		{Id: aws.String("/hostedzone/Z1")},
+		{Id: aws.String("/hostedzone/Z2"), Config: &rtypes.HostedZoneConfig{PrivateZone: true}},
+	}}
+	require.Equal(t, "2 hosted zones named example.com.: /hostedzone/Z1 (public), /hostedzone/Z2 (private); address the zone by its ID instead", err.Error())
+}
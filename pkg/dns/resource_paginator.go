@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/route53"
 	"github.com/aws/aws-sdk-go-v2/service/route53/types"
 )
@@ -32,7 +33,10 @@ type ListResourceRecordSetsPaginator struct {
 	params         *route53.ListResourceRecordSetsInput
 	nextRecordName *string
 	nextRecordType types.RRType
-	firstPage      bool
+	// nextRecordIdentifier continues inside a set of weighted, latency or
+	// other routed records split across pages.
+	nextRecordIdentifier *string
+	firstPage            bool
 }
 
 // NewListResourceRecordSetsPaginator returns a new ListResourceRecordSetsPaginator
@@ -51,12 +55,13 @@ func NewListResourceRecordSetsPaginator(client ListResourceRecordSetsAPIClient,
 	}
 
 	return &ListResourceRecordSetsPaginator{
-		options:        options,
-		client:         client,
-		params:         params,
-		firstPage:      true,
-		nextRecordName: params.StartRecordName,
-		nextRecordType: params.StartRecordType,
+		options:              options,
+		client:               client,
+		params:               params,
+		firstPage:            true,
+		nextRecordName:       params.StartRecordName,
+		nextRecordType:       params.StartRecordType,
+		nextRecordIdentifier: params.StartRecordIdentifier,
 	}
 }
 
@@ -74,6 +79,7 @@ func (p *ListResourceRecordSetsPaginator) NextPage(ctx context.Context, optFns .
 	params := *p.params
 	params.StartRecordName = p.nextRecordName
 	params.StartRecordType = p.nextRecordType
+	params.StartRecordIdentifier = p.nextRecordIdentifier
 
 	var limit *int32
 	if p.options.Limit > 0 {
@@ -87,14 +93,16 @@ func (p *ListResourceRecordSetsPaginator) NextPage(ctx context.Context, optFns .
 	}
 	p.firstPage = false
 
-	prevRecordName := p.nextRecordName
+	prevRecordName, prevRecordIdentifier := p.nextRecordName, p.nextRecordIdentifier
 	p.nextRecordName = result.NextRecordName
 	p.nextRecordType = result.NextRecordType
+	p.nextRecordIdentifier = result.NextRecordIdentifier
 
 	if p.options.StopOnDuplicateToken &&
 		prevRecordName != nil &&
 		p.nextRecordName != nil &&
-		*prevRecordName == *p.nextRecordName {
+		*prevRecordName == *p.nextRecordName &&
+		aws.ToString(prevRecordIdentifier) == aws.ToString(p.nextRecordIdentifier) {
 		p.nextRecordName = nil
 	}
 
@@ -5,13 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"log"
-	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/aws/retry"
-	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/route53"
 	rtypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
 	"github.com/aws/aws-sdk-go-v2/service/route53domains"
@@ -23,6 +21,11 @@ type RouteManager struct {
 	domains *route53domains.Client
 
 	o *RouteManagerOptions
+
+	// inSync holds the changes that were already INSYNC when submitted.
+	// Emulators answer that way and may not keep the change for GetChange.
+	mu     sync.Mutex
+	inSync map[string]bool
 }
 
 type RouteManagerOptions struct {
@@ -37,26 +40,10 @@ func (e *HostedZoneNotFound) Error() string {
 	return fmt.Sprintf("hosted zone not found: %s", e.Zone)
 }
 
-func NewRouteManager(ctx context.Context, profile string, rmo *RouteManagerOptions) *RouteManager {
-	if r := os.Getenv("AWS_REGION"); r == "" {
-		_ = os.Setenv("AWS_REGION", "us-east-1")
-	}
-	cfg, err := config.LoadDefaultConfig(ctx,
-		config.WithSharedConfigProfile(profile),
-		config.WithRetryer(func() aws.Retryer {
-			return retry.NewAdaptiveMode(func(amo *retry.AdaptiveModeOptions) {
-				amo.StandardOptions = []func(*retry.StandardOptions){
-					func(so *retry.StandardOptions) {
-						so.MaxAttempts = 5
-						so.MaxBackoff = 60 * time.Second
-						so.Backoff = retry.NewExponentialJitterBackoff(so.MaxBackoff)
-					},
-				}
-			})
-		}),
-	)
+func NewRouteManager(ctx context.Context, ao AWSOptions, rmo *RouteManagerOptions) (*RouteManager, error) {
+	cfg, err := LoadAWSConfig(ctx, ao)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
 	o := &RouteManagerOptions{NoWait: false}
@@ -65,32 +52,22 @@ func NewRouteManager(ctx context.Context, profile string, rmo *RouteManagerOptio
 	}
 
 	return &RouteManager{
-		cli:     route53.NewFromConfig(cfg),
-		domains: route53domains.NewFromConfig(cfg),
+		cli: route53.NewFromConfig(cfg, func(ro *route53.Options) {
+			if ep := ao.endpoint(ServiceRoute53); ep != nil {
+				ro.BaseEndpoint = ep
+			}
+		}),
+		domains: newDomainsClient(cfg, ao),
 
 		o: o,
-	}
+	}, nil
 }
 
+// GetHostedZone looks a zone up by name. When a public and a private zone
+// share the name it fails with AmbiguousHostedZone; use FindHostedZone to
+// pick one.
 func (r *RouteManager) GetHostedZone(ctx context.Context, domain string) (rtypes.HostedZone, error) {
-	params := &route53.ListHostedZonesByNameInput{
-		DNSName:  aws.String(domain),
-		MaxItems: aws.Int32(1),
-	}
-	resp, err := r.cli.ListHostedZonesByName(ctx, params)
-	if err != nil {
-		return rtypes.HostedZone{}, err
-	}
-
-	if len(resp.HostedZones) == 0 {
-		return rtypes.HostedZone{}, &HostedZoneNotFound{Zone: domain}
-	}
-
-	zone := resp.HostedZones[0]
-	if *zone.Name != NormalizeDomain(domain) {
-		return rtypes.HostedZone{}, &HostedZoneNotFound{Zone: domain}
-	}
-	return zone, nil
+	return r.FindHostedZone(ctx, domain, ZoneFilter{})
 }
 
 func (r *RouteManager) ListHostedZones(ctx context.Context) ([]rtypes.HostedZone, error) {
@@ -108,20 +85,36 @@ func (r *RouteManager) ListHostedZones(ctx context.Context) ([]rtypes.HostedZone
 	return zones, nil
 }
 
-func (r *RouteManager) CreateZone(ctx context.Context, domain string) (rtypes.HostedZone, error) {
+func (r *RouteManager) CreateZone(ctx context.Context, domain string, o CreateZoneOptions) (rtypes.HostedZone, error) {
+	if o.Private && len(o.VPCs) == 0 {
+		return rtypes.HostedZone{}, fmt.Errorf("private zone %s needs at least one VPC", domain)
+	}
 	params := &route53.CreateHostedZoneInput{
 		Name:            aws.String(NormalizeDomain(domain)),
 		CallerReference: aws.String(fmt.Sprintf("%s-%d", domain, time.Now().Unix())),
 		HostedZoneConfig: &rtypes.HostedZoneConfig{
 			Comment:     aws.String("Created by route53copy"),
-			PrivateZone: false,
+			PrivateZone: o.Private,
 		},
 	}
+	if o.Private {
+		// CreateHostedZone takes a single VPC, the rest are associated
+		// afterwards.
+		params.VPC = &rtypes.VPC{VPCId: aws.String(o.VPCs[0].ID), VPCRegion: rtypes.VPCRegion(o.VPCs[0].Region)}
+	}
 	resp, err := r.cli.CreateHostedZone(ctx, params)
 	if err != nil {
 		return rtypes.HostedZone{}, err
 	}
 
+	if o.Private {
+		for _, vpc := range o.VPCs[1:] {
+			if err := r.AssociateVPC(ctx, aws.ToString(resp.HostedZone.Id), vpc); err != nil {
+				return *resp.HostedZone, fmt.Errorf("error associating %s with %s: %w", vpc, domain, err)
+			}
+		}
+	}
+
 	if resp.ChangeInfo.Status != rtypes.ChangeStatusInsync {
 		start := time.Now()
 		err := r.WaitForChange(ctx, aws.ToString(resp.ChangeInfo.Id), 1*time.Minute)
@@ -142,10 +135,36 @@ func (r *RouteManager) CreateZone(ctx context.Context, domain string) (rtypes.Ho
 	return *resp.HostedZone, nil
 }
 
+// track remembers a change that is already in sync and returns its ID.
+func (r *RouteManager) track(ci *rtypes.ChangeInfo) string {
+	if ci == nil {
+		return ""
+	}
+	id := aws.ToString(ci.Id)
+	if ci.Status == rtypes.ChangeStatusInsync {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if r.inSync == nil {
+			r.inSync = map[string]bool{}
+		}
+		r.inSync[id] = true
+	}
+	return id
+}
+
+// WaitForChange waits until the change is INSYNC. Changes that were in sync
+// when submitted, or have no ID, return at once.
 func (r *RouteManager) WaitForChange(ctx context.Context, changeId string, maxWait time.Duration) error {
-	if r.o.NoWait {
+	if r.o.NoWait || changeId == "" {
 		return nil
 	}
+	r.mu.Lock()
+	done := r.inSync[changeId]
+	r.mu.Unlock()
+	if done {
+		return nil
+	}
+
 	waiter := route53.NewResourceRecordSetsChangedWaiter(r.cli, func(rrscwo *route53.ResourceRecordSetsChangedWaiterOptions) {
 		rrscwo.MinDelay = 15 * time.Second
 	})
@@ -154,15 +173,15 @@ func (r *RouteManager) WaitForChange(ctx context.Context, changeId string, maxWa
 	}, maxWait)
 }
 
-func (r *RouteManager) GetOrCreateZone(ctx context.Context, domain string) (rtypes.HostedZone, error) {
+func (r *RouteManager) GetOrCreateZone(ctx context.Context, domain string, o CreateZoneOptions) (rtypes.HostedZone, error) {
 	var zone rtypes.HostedZone
 	var err error
-	zone, err = r.GetHostedZone(ctx, domain)
+	zone, err = r.FindHostedZone(ctx, domain, o.Filter())
 	if err != nil {
 		var e *HostedZoneNotFound
 		if errors.As(err, &e) {
 			log.Printf("Destination profile does not contain %s, creating it\n", domain)
-			zone, err = r.CreateZone(ctx, domain)
+			zone, err = r.CreateZone(ctx, domain, o)
 			if err != nil {
 				return zone, err
 			}
@@ -178,16 +197,15 @@ type Tag struct {
 	Value string
 }
 
-func (r *RouteManager) GetZoneTags(ctx context.Context, zoneID string) ([]Tag, error) {
-
-	resourceId := zoneID
-	if strings.Contains(zoneID, "/") {
-		s := strings.Split(zoneID, "/")
-		resourceId = s[len(s)-1]
-	}
+// tagResourceID strips the /hostedzone/ prefix the tagging API rejects.
+func tagResourceID(zoneID string) string {
+	s := strings.Split(zoneID, "/")
+	return s[len(s)-1]
+}
 
+func (r *RouteManager) GetZoneTags(ctx context.Context, zoneID string) ([]Tag, error) {
 	t, err := r.cli.ListTagsForResource(ctx, &route53.ListTagsForResourceInput{
-		ResourceId:   aws.String(resourceId),
+		ResourceId:   aws.String(tagResourceID(zoneID)),
 		ResourceType: rtypes.TagResourceTypeHostedzone,
 	})
 	if err != nil {
@@ -205,20 +223,25 @@ func (r *RouteManager) GetZoneTags(ctx context.Context, zoneID string) ([]Tag, e
 }
 
 func (r *RouteManager) UpsertTags(ctx context.Context, zoneID string, tags []Tag) error {
-	resourceId := zoneID
-	if strings.Contains(zoneID, "/") {
-		s := strings.Split(zoneID, "/")
-		resourceId = s[len(s)-1]
-	}
-
 	_, err := r.cli.ChangeTagsForResource(ctx, &route53.ChangeTagsForResourceInput{
-		ResourceId:   aws.String(resourceId),
+		ResourceId:   aws.String(tagResourceID(zoneID)),
 		ResourceType: rtypes.TagResourceTypeHostedzone,
 		AddTags:      toAwsTags(tags),
 	})
 	return err
 }
 
+// DeleteTags removes the tags with the given keys from a zone. Keys the zone
+// does not have are ignored.
+func (r *RouteManager) DeleteTags(ctx context.Context, zoneID string, keys []string) error {
+	_, err := r.cli.ChangeTagsForResource(ctx, &route53.ChangeTagsForResourceInput{
+		ResourceId:    aws.String(tagResourceID(zoneID)),
+		ResourceType:  rtypes.TagResourceTypeHostedzone,
+		RemoveTagKeys: keys,
+	})
+	return err
+}
+
 func toAwsTags(tags []Tag) []rtypes.Tag {
 	awsTags := []rtypes.Tag{}
 	for _, tag := range tags {
@@ -283,7 +306,7 @@ func (r *RouteManager) DeleteRecords(ctx context.Context, zoneId string, records
 	if err != nil {
 		return "", err
 	}
-	return aws.ToString(ch.ChangeInfo.Id), nil
+	return r.track(ch.ChangeInfo), nil
 }
 
 func (r *RouteManager) DeleteHostedZone(ctx context.Context, zoneId string) (string, error) {
@@ -293,7 +316,7 @@ func (r *RouteManager) DeleteHostedZone(ctx context.Context, zoneId string) (str
 	if err != nil {
 		return "", err
 	}
-	return aws.ToString(dhz.ChangeInfo.Id), nil
+	return r.track(dhz.ChangeInfo), nil
 }
 
 func (r *RouteManager) GetNSRecords(ctx context.Context, zoneId string) (rtypes.ResourceRecordSet, error) {
@@ -355,6 +378,7 @@ func (r *RouteManager) UpdateRecords(ctx context.Context, comment, zoneId string
 	if err != nil {
 		return nil, err
 	}
+	r.track(resp.ChangeInfo)
 	return resp.ChangeInfo, nil
 }
 
@@ -1,7 +1,6 @@
 package dns
 
 import (
-	"context"
 	"testing"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -38,7 +37,7 @@ func TestCreateChanges_ExcludesApexNSAndSOA_IncludesOthers(t *testing.T) {
 		},
 	}
 
-	changes := NewRouteManager(context.TODO(), "", &RouteManagerOptions{}).CreateChanges(domain, input)
+	changes := (&RouteManager{}).CreateChanges(domain, input)
 
 	// Expect 2 changes: sub NS and TXT
 	require.Len(t, changes, 2)
@@ -1,11 +1,14 @@
 package dns
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	rtypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
 	rdtypes "github.com/aws/aws-sdk-go-v2/service/route53domains/types"
+	"github.com/pedrokiefer/route53copy/pkg/dns/route53test"
 	"github.com/stretchr/testify/require"
 )
 
@@ -44,3 +47,136 @@ func TestFindInList(t *testing.T) {
 	require.True(t, findInList(list, "b"))
 	require.False(t, findInList(list, "d"))
 }
+
+func newFakeRouteManager(t *testing.T, opts ...route53test.Option) (*RouteManager, *route53test.Server) {
+	t.Helper()
+	route53test.SetEnv(t)
+	srv := route53test.NewServer(opts...)
+	t.Cleanup(srv.Close)
+	rm, err := NewRouteManager(context.Background(), AWSOptions{EndpointURL: srv.URL}, nil)
+	require.NoError(t, err)
+	return rm, srv
+}
+
+func weighted(name, id string, weight int64, value string) rtypes.ResourceRecordSet {
+	return rtypes.ResourceRecordSet{
+		Name:            aws.String(name),
+		Type:            rtypes.RRTypeA,
+		SetIdentifier:   aws.String(id),
+		Weight:          aws.Int64(weight),
+		TTL:             aws.Int64(60),
+		ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String(value)}},
+	}
+}
+
+func TestGetResourceRecords_PaginatesThroughRoutedSets(t *testing.T) {
+	rm, srv := newFakeRouteManager(t, route53test.WithPageSize(2))
+	zoneID := srv.AddZone("example.com")
+	require.NoError(t, srv.AddRecordSets(zoneID,
+		weighted("api.example.com", "blue", 10, "192.0.2.1"),
+		weighted("api.example.com", "green", 20, "192.0.2.2"),
+		weighted("api.example.com", "red", 0, "192.0.2.3"),
+		rtypes.ResourceRecordSet{Name: aws.String("www.example.com"), Type: rtypes.RRTypeCname, TTL: aws.Int64(60),
+			ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String("api.example.com")}}},
+	))
+
+	// Pages split the weighted set: [NS SOA] [blue green] [red www].
+	records, err := rm.GetResourceRecords(context.Background(), zoneID)
+	require.NoError(t, err)
+	require.Len(t, records, 6)
+	ids := []string{}
+	for _, rs := range records {
+		ids = append(ids, aws.ToString(rs.SetIdentifier))
+	}
+	require.Equal(t, []string{"", "", "blue", "green", "red", ""}, ids)
+	require.Equal(t, 3, srv.Calls("ListResourceRecordSets"))
+}
+
+func TestRouteManager_EndToEnd(t *testing.T) {
+	rm, srv := newFakeRouteManager(t)
+	ctx := context.Background()
+
+	zone, err := rm.GetOrCreateZone(ctx, "example.com", CreateZoneOptions{})
+	require.NoError(t, err)
+	zoneID := aws.ToString(zone.Id)
+	again, err := rm.GetOrCreateZone(ctx, "example.com.", CreateZoneOptions{})
+	require.NoError(t, err)
+	require.Equal(t, zoneID, aws.ToString(again.Id))
+
+	changes := rm.CreateChanges("example.com", []rtypes.ResourceRecordSet{
+		{Name: aws.String("www.example.com."), Type: rtypes.RRTypeA, TTL: aws.Int64(300),
+			ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String("192.0.2.1")}}},
+	})
+	ci, err := rm.UpdateRecords(ctx, "test", zoneID, changes)
+	require.NoError(t, err)
+	require.NoError(t, rm.WaitForChange(ctx, aws.ToString(ci.Id), time.Minute))
+
+	ns, err := rm.GetNSRecords(ctx, zoneID)
+	require.NoError(t, err)
+	require.Len(t, ns.ResourceRecords, 4)
+
+	require.NoError(t, rm.UpsertTags(ctx, zoneID, []Tag{{Name: "parked", Value: "true"}}))
+	tags, err := rm.GetZoneTags(ctx, zoneID)
+	require.NoError(t, err)
+	require.Equal(t, []Tag{{Name: "parked", Value: "true"}}, tags)
+	require.NoError(t, rm.DeleteTags(ctx, zoneID, []string{"parked", "missing"}))
+	tags, err = rm.GetZoneTags(ctx, zoneID)
+	require.NoError(t, err)
+	require.Empty(t, tags)
+
+	// A second zone with the same name makes lookups by name ambiguous.
+	srv.AddZone("example.com")
+	_, err = rm.GetHostedZone(ctx, "example.com")
+	var ambiguous *AmbiguousHostedZone
+	require.ErrorAs(t, err, &ambiguous)
+	byID, err := rm.FindHostedZone(ctx, zoneID, ZoneFilter{})
+	require.NoError(t, err)
+	require.Equal(t, zoneID, aws.ToString(byID.Id))
+
+	records, err := rm.GetResourceRecords(ctx, zoneID)
+	require.NoError(t, err)
+	id, err := rm.DeleteRecords(ctx, zoneID, records)
+	require.NoError(t, err)
+	require.NoError(t, rm.WaitForChange(ctx, id, time.Minute))
+	id, err = rm.DeleteHostedZone(ctx, zoneID)
+	require.NoError(t, err)
+	require.NoError(t, rm.WaitForChange(ctx, id, time.Minute))
+
+	_, err = rm.FindHostedZone(ctx, zoneID, ZoneFilter{})
+	var notFound *HostedZoneNotFound
+	require.ErrorAs(t, err, &notFound)
+}
+
+func TestWaitForChange_SkipsChangesAlreadyInSync(t *testing.T) {
+	rm, srv := newFakeRouteManager(t, route53test.WithChangesInSync())
+	zoneID := srv.AddZone("example.com")
+
+	ci, err := rm.UpdateRecords(context.Background(), "test", zoneID, rm.CreateChanges("example.com", []rtypes.ResourceRecordSet{
+		{Name: aws.String("example.com."), Type: rtypes.RRTypeTxt, TTL: aws.Int64(60),
+			ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String(`"hello"`)}}},
+	}))
+	require.NoError(t, err)
+	require.NoError(t, rm.WaitForChange(context.Background(), aws.ToString(ci.Id), time.Second))
+	require.Zero(t, srv.Calls("GetChange"))
+	require.NoError(t, rm.WaitForChange(context.Background(), "", time.Second))
+}
+
+func TestWaitForChange_PollsPendingChanges(t *testing.T) {
+	route53test.SetEnv(t)
+	srv := route53test.NewServer()
+	defer srv.Close()
+	zoneID := srv.AddZone("example.com")
+	require.NoError(t, srv.AddRecordSets(zoneID, weighted("api.example.com", "blue", 10, "192.0.2.1")))
+
+	// The per-service override wins over the shared endpoint.
+	rm, err := NewRouteManager(context.Background(), AWSOptions{
+		EndpointURL: "http://127.0.0.1:1",
+		Endpoints:   map[string]string{ServiceRoute53: srv.URL},
+	}, nil)
+	require.NoError(t, err)
+
+	id, err := rm.DeleteRecords(context.Background(), zoneID, []rtypes.ResourceRecordSet{weighted("api.example.com.", "blue", 10, "192.0.2.1")})
+	require.NoError(t, err)
+	require.NoError(t, rm.WaitForChange(context.Background(), id, 30*time.Second))
+	require.Equal(t, 1, srv.Calls("GetChange"))
+}
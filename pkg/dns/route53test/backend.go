@@ -0,0 +1,489 @@
+package route53test
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// apiError is a Route53 error response.
+type apiError struct {
+	status  int
+	code    string
+	message string
+	// messages, when set, make this an InvalidChangeBatch response.
+	messages []string
+}
+
+func (e *apiError) Error() string {
+	if len(e.messages) > 0 {
+		return strings.Join(e.messages, "; ")
+	}
+	return fmt.Sprintf("%s: %s", e.code, e.message)
+}
+
+func noSuchHostedZone(id string) *apiError {
+	return &apiError{status: http.StatusNotFound, code: "NoSuchHostedZone", message: "No hosted zone found with ID: " + id}
+}
+
+func invalidInput(format string, args ...interface{}) *apiError {
+	return &apiError{status: http.StatusBadRequest, code: "InvalidInput", message: fmt.Sprintf(format, args...)}
+}
+
+type zone struct {
+	id              string
+	name            string
+	callerReference string
+	comment         string
+	private         bool
+	vpcs            []xmlVPC
+	nameservers     []string
+	records         []xmlRecordSet
+	tags            map[string]string
+}
+
+func (z *zone) hostedZone() xmlHostedZone {
+	hz := xmlHostedZone{
+		Id:                     "/hostedzone/" + z.id,
+		Name:                   z.name,
+		CallerReference:        z.callerReference,
+		ResourceRecordSetCount: int64(len(z.records)),
+		Config:                 &xmlZoneConfig{Comment: z.comment, PrivateZone: z.private},
+	}
+	return hz
+}
+
+type change struct {
+	id          string
+	status      string
+	submittedAt time.Time
+	comment     string
+}
+
+func (c *change) info() xmlChangeInfo {
+	return xmlChangeInfo{
+		Id:          "/change/" + c.id,
+		Status:      c.status,
+		SubmittedAt: c.submittedAt.UTC().Format(time.RFC3339),
+		Comment:     c.comment,
+	}
+}
+
+// backend holds the state of the fake account.
+type backend struct {
+	mu      sync.Mutex
+	zones   []*zone
+	changes map[string]*change
+	serial  int
+
+	changesInSync bool
+}
+
+func newBackend() *backend {
+	return &backend{changes: map[string]*change{}}
+}
+
+func (b *backend) nextID(prefix string) string {
+	b.serial++
+	return fmt.Sprintf("%sFAKE%08d", prefix, b.serial)
+}
+
+func (b *backend) newChange(comment string) *change {
+	c := &change{id: b.nextID("C"), status: "PENDING", submittedAt: time.Now(), comment: comment}
+	if b.changesInSync {
+		c.status = "INSYNC"
+	}
+	b.changes[c.id] = c
+	return c
+}
+
+func (b *backend) zone(id string) (*zone, error) {
+	id = strings.TrimPrefix(id, "/hostedzone/")
+	for _, z := range b.zones {
+		if z.id == id {
+			return z, nil
+		}
+	}
+	return nil, noSuchHostedZone(id)
+}
+
+func (b *backend) createZone(req createHostedZoneRequest) (*zone, *change, error) {
+	if req.Name == "" || req.CallerReference == "" {
+		return nil, nil, invalidInput("Name and CallerReference are required")
+	}
+	for _, z := range b.zones {
+		if z.callerReference == req.CallerReference {
+			return nil, nil, &apiError{status: http.StatusConflict, code: "HostedZoneAlreadyExists",
+				message: fmt.Sprintf("A hosted zone has already been created with the specified caller reference %s", req.CallerReference)}
+		}
+	}
+
+	z := &zone{
+		id:              b.nextID("Z"),
+		name:            normalizeName(req.Name),
+		callerReference: req.CallerReference,
+		tags:            map[string]string{},
+	}
+	if c := req.HostedZoneConfig; c != nil {
+		z.comment = c.Comment
+		z.private = c.PrivateZone
+	}
+	if z.private || req.VPC != nil {
+		if req.VPC == nil || req.VPC.VPCId == "" {
+			return nil, nil, invalidInput("A private hosted zone needs a VPC")
+		}
+		z.private = true
+		z.vpcs = []xmlVPC{*req.VPC}
+	}
+
+	n := b.serial
+	z.nameservers = []string{
+		fmt.Sprintf("ns-%d.awsdns-%02d.com.", n, n%64),
+		fmt.Sprintf("ns-%d.awsdns-%02d.net.", n+512, n%64),
+		fmt.Sprintf("ns-%d.awsdns-%02d.org.", n+1024, n%64),
+		fmt.Sprintf("ns-%d.awsdns-%02d.co.uk.", n+1536, n%64),
+	}
+	if z.private {
+		z.nameservers = []string{"ns-0.awsdns-00.com.", "ns-512.awsdns-00.net.", "ns-1024.awsdns-00.org.", "ns-1536.awsdns-00.co.uk."}
+	}
+
+	soaTTL, nsTTL := int64(900), int64(172800)
+	ns := []xmlResourceRecord{}
+	for _, s := range z.nameservers {
+		ns = append(ns, xmlResourceRecord{Value: s})
+	}
+	z.records = []xmlRecordSet{
+		{Name: z.name, Type: "NS", TTL: &nsTTL, ResourceRecords: ns},
+		{Name: z.name, Type: "SOA", TTL: &soaTTL, ResourceRecords: []xmlResourceRecord{
+			{Value: z.nameservers[0] + " awsdns-hostmaster.amazon.com. 1 7200 900 1209600 86400"},
+		}},
+	}
+	sortRecords(z.records)
+
+	b.zones = append(b.zones, z)
+	return z, b.newChange(""), nil
+}
+
+func (b *backend) deleteZone(id string) (*change, error) {
+	z, err := b.zone(id)
+	if err != nil {
+		return nil, err
+	}
+	for _, rs := range z.records {
+		if rs.Name == z.name && (rs.Type == "NS" || rs.Type == "SOA") {
+			continue
+		}
+		return nil, &apiError{status: http.StatusBadRequest, code: "HostedZoneNotEmpty",
+			message: "The specified hosted zone contains non-required resource record sets and so cannot be deleted."}
+	}
+	for i, other := range b.zones {
+		if other == z {
+			b.zones = append(b.zones[:i], b.zones[i+1:]...)
+			break
+		}
+	}
+	return b.newChange(""), nil
+}
+
+// recordKey identifies a record set within a zone.
+type recordKey struct {
+	name, typ, setID string
+}
+
+func keyOf(rs xmlRecordSet) recordKey {
+	return recordKey{rs.Name, rs.Type, rs.SetIdentifier}
+}
+
+func describe(k recordKey) string {
+	if k.setID != "" {
+		return fmt.Sprintf("[name='%s', type='%s', set-identifier='%s']", k.name, k.typ, k.setID)
+	}
+	return fmt.Sprintf("[name='%s', type='%s']", k.name, k.typ)
+}
+
+// applyChanges validates a change batch against the zone and applies it as
+// a whole, or not at all, like Route53 does.
+func (b *backend) applyChanges(id string, req changeResourceRecordSetsRequest) (*change, error) {
+	z, err := b.zone(id)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Changes) == 0 {
+		return nil, invalidInput("ChangeBatch must contain at least one change")
+	}
+
+	current := map[recordKey]xmlRecordSet{}
+	for _, rs := range z.records {
+		current[keyOf(rs)] = rs
+	}
+
+	messages := []string{}
+	deleted := map[recordKey]bool{}
+	seen := map[recordKey]bool{}
+	for _, c := range req.Changes {
+		if c.ResourceRecordSet == nil {
+			return nil, invalidInput("Change with Action=%s has no ResourceRecordSet", c.Action)
+		}
+		rs := *c.ResourceRecordSet
+		rs.Name = normalizeName(rs.Name)
+		if err := validateRecordSet(c.Action, rs); err != nil {
+			return nil, err
+		}
+		k := keyOf(rs)
+
+		// The same record set may only appear twice when it is deleted and
+		// then created again.
+		if seen[k] && !(deleted[k] && c.Action != "DELETE") {
+			messages = append(messages, fmt.Sprintf("The request contains an invalid set of changes for a resource record set '%s %s'", rs.Type, rs.Name))
+			continue
+		}
+		seen[k] = true
+
+		if rs.Name != z.name && !strings.HasSuffix(rs.Name, "."+z.name) {
+			messages = append(messages, fmt.Sprintf("RRSet with DNS name %s is not permitted in zone %s", rs.Name, z.name))
+			continue
+		}
+
+		existing, exists := current[k]
+		switch c.Action {
+		case "CREATE":
+			if exists {
+				messages = append(messages, fmt.Sprintf("Tried to create resource record set %s but it already exists", describe(k)))
+				continue
+			}
+			current[k] = rs
+		case "UPSERT":
+			current[k] = rs
+		case "DELETE":
+			if !exists {
+				messages = append(messages, fmt.Sprintf("Tried to delete resource record set %s but it was not found", describe(k)))
+				continue
+			}
+			if !sameValues(existing, rs) {
+				messages = append(messages, fmt.Sprintf("Tried to delete resource record set %s but the values provided do not match the current values", describe(k)))
+				continue
+			}
+			delete(current, k)
+			deleted[k] = true
+		default:
+			return nil, invalidInput("Invalid Action %q", c.Action)
+		}
+	}
+
+	records := make([]xmlRecordSet, 0, len(current))
+	for _, rs := range current {
+		records = append(records, rs)
+	}
+	messages = append(messages, checkZoneRules(z.name, records)...)
+	if len(messages) > 0 {
+		return nil, &apiError{status: http.StatusBadRequest, code: "InvalidChangeBatch", messages: messages}
+	}
+
+	sortRecords(records)
+	z.records = records
+	return b.newChange(req.Comment), nil
+}
+
+func validateRecordSet(action string, rs xmlRecordSet) error {
+	hasValues := rs.TTL != nil && len(rs.ResourceRecords) > 0
+	hasAlias := rs.AliasTarget != nil
+	hasPolicy := rs.TrafficPolicyInstanceId != ""
+	if n := countTrue(hasValues, hasAlias, hasPolicy); n != 1 {
+		return invalidInput("Invalid request: Expected exactly one of [AliasTarget, all of [TTL, and ResourceRecords], or TrafficPolicyInstanceId], but found %d in Change with [Action=%s, Name=%s, Type=%s, SetIdentifier=%s]",
+			n, action, rs.Name, rs.Type, rs.SetIdentifier)
+	}
+	routed := rs.Weight != nil || rs.Region != "" || rs.Failover != "" || rs.GeoLocation != nil || rs.MultiValueAnswer != nil
+	if routed != (rs.SetIdentifier != "") {
+		return invalidInput("Invalid request: SetIdentifier must be given with exactly one routing policy, in Change with [Action=%s, Name=%s, Type=%s]",
+			action, rs.Name, rs.Type)
+	}
+	if !knownTypes[rs.Type] {
+		return invalidInput("Invalid request: unknown record type %s", rs.Type)
+	}
+	return nil
+}
+
+var knownTypes = map[string]bool{
+	"A": true, "AAAA": true, "CAA": true, "CNAME": true, "DS": true, "HTTPS": true, "MX": true, "NAPTR": true,
+	"NS": true, "PTR": true, "SOA": true, "SPF": true, "SRV": true, "SSHFP": true, "SVCB": true, "TLSA": true, "TXT": true,
+}
+
+func countTrue(bs ...bool) int {
+	n := 0
+	for _, b := range bs {
+		if b {
+			n++
+		}
+	}
+	return n
+}
+
+// checkZoneRules enforces the rules that span record sets.
+func checkZoneRules(apex string, records []xmlRecordSet) []string {
+	messages := []string{}
+	byName := map[string][]xmlRecordSet{}
+	for _, rs := range records {
+		byName[rs.Name] = append(byName[rs.Name], rs)
+	}
+
+	names := make([]string, 0, len(byName))
+	for name := range byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		sets := byName[name]
+		routed := map[string]int{}
+		plain := map[string]int{}
+		for _, rs := range sets {
+			if rs.SetIdentifier != "" {
+				routed[rs.Type]++
+			} else {
+				plain[rs.Type]++
+			}
+			if rs.Type == "CNAME" && len(rs.ResourceRecords) > 1 {
+				messages = append(messages, fmt.Sprintf("RRSet of type CNAME with DNS name %s contains more than one value", name))
+			}
+		}
+		for typ := range routed {
+			if plain[typ] > 0 {
+				messages = append(messages, fmt.Sprintf("RRSet with DNS name %s, type %s cannot be created as a non-routed set exists with the same name and type", name, typ))
+			}
+		}
+		if plain["CNAME"]+routed["CNAME"] > 0 {
+			if name == apex {
+				messages = append(messages, fmt.Sprintf("RRSet of type CNAME with DNS name %s is not permitted at apex in zone %s", name, apex))
+			} else if len(plain)+len(routed) > 1 {
+				messages = append(messages, fmt.Sprintf("RRSet of type CNAME with DNS name %s is not permitted as it conflicts with other records with the same DNS name", name))
+			}
+		}
+	}
+
+	apexSets := byName[apex]
+	soa, ns := 0, 0
+	for _, rs := range apexSets {
+		switch rs.Type {
+		case "SOA":
+			soa++
+		case "NS":
+			ns++
+		}
+	}
+	if soa != 1 {
+		messages = append(messages, "A HostedZone must contain exactly one SOA record.")
+	}
+	if ns == 0 {
+		messages = append(messages, "A HostedZone must contain at least one NS record for the zone itself.")
+	}
+	return messages
+}
+
+func sameValues(a, b xmlRecordSet) bool {
+	return reflect.DeepEqual(a, b)
+}
+
+// normalizeName lowercases name, makes it fully qualified and escapes "*"
+// the way Route53 returns it.
+func normalizeName(name string) string {
+	name = strings.ToLower(name)
+	if !strings.HasSuffix(name, ".") {
+		name += "."
+	}
+	return strings.ReplaceAll(name, "*", `\052`)
+}
+
+// sortKey orders names the way Route53 lists them: by label from the right.
+func sortKey(name string) string {
+	labels := strings.Split(strings.TrimSuffix(name, "."), ".")
+	for i, j := 0, len(labels)-1; i < j; i, j = i+1, j-1 {
+		labels[i], labels[j] = labels[j], labels[i]
+	}
+	return strings.Join(labels, ".")
+}
+
+func lessRecord(a, b recordKey) bool {
+	if ka, kb := sortKey(a.name), sortKey(b.name); ka != kb {
+		return ka < kb
+	}
+	if a.typ != b.typ {
+		return a.typ < b.typ
+	}
+	return a.setID < b.setID
+}
+
+func sortRecords(records []xmlRecordSet) {
+	sort.Slice(records, func(i, j int) bool { return lessRecord(keyOf(records[i]), keyOf(records[j])) })
+}
+
+func (b *backend) listRecords(id, name, typ, setID string, maxItems int) (listResourceRecordSetsResponse, error) {
+	z, err := b.zone(id)
+	if err != nil {
+		return listResourceRecordSetsResponse{}, err
+	}
+	resp := listResourceRecordSetsResponse{MaxItems: maxItems, ResourceRecordSets: []xmlRecordSet{}}
+
+	start := 0
+	if name != "" {
+		from := recordKey{normalizeName(name), typ, setID}
+		start = sort.Search(len(z.records), func(i int) bool { return !lessRecord(keyOf(z.records[i]), from) })
+	}
+	end := min(start+maxItems, len(z.records))
+	resp.ResourceRecordSets = append(resp.ResourceRecordSets, z.records[start:end]...)
+	if end < len(z.records) {
+		next := z.records[end]
+		resp.IsTruncated = true
+		resp.NextRecordName = next.Name
+		resp.NextRecordType = next.Type
+		resp.NextRecordIdentifier = next.SetIdentifier
+	}
+	return resp, nil
+}
+
+// zonesByName returns the zones ordered as ListHostedZonesByName lists them.
+func (b *backend) zonesByName() []*zone {
+	zones := append([]*zone{}, b.zones...)
+	sort.SliceStable(zones, func(i, j int) bool {
+		if ki, kj := sortKey(zones[i].name), sortKey(zones[j].name); ki != kj {
+			return ki < kj
+		}
+		return zones[i].id < zones[j].id
+	})
+	return zones
+}
+
+func (b *backend) changeTags(id string, req changeTagsRequest) error {
+	z, err := b.zone(id)
+	if err != nil {
+		return err
+	}
+	for _, t := range req.AddTags {
+		z.tags[t.Key] = t.Value
+	}
+	for _, k := range req.RemoveTagKeys {
+		delete(z.tags, k)
+	}
+	return nil
+}
+
+func (b *backend) associateVPC(id string, req associateVPCRequest) (*change, error) {
+	z, err := b.zone(id)
+	if err != nil {
+		return nil, err
+	}
+	if !z.private {
+		return nil, invalidInput("VPCs can only be associated with private hosted zones")
+	}
+	for _, v := range z.vpcs {
+		if v == req.VPC {
+			return nil, &apiError{status: http.StatusConflict, code: "ConflictingDomainExists",
+				message: fmt.Sprintf("The VPC %s is already associated with the hosted zone", v.VPCId)}
+		}
+	}
+	z.vpcs = append(z.vpcs, req.VPC)
+	return b.newChange(req.Comment), nil
+}
@@ -0,0 +1,515 @@
+// Package route53test provides an in-memory Route53 served over HTTP, so
+// code can be tested end to end through the real AWS SDK client.
+//
+// The server speaks the Route53 REST/XML API for hosted zones, record sets,
+// changes and tags. Change batches are validated and applied atomically the
+// way Route53 does, and listings paginate with Route53's markers.
+package route53test
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/route53"
+	rtypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
+)
+
+const apiPrefix = "/2013-04-01/"
+
+// Server is a fake Route53 endpoint. Point an SDK client's BaseEndpoint at
+// URL to use it.
+type Server struct {
+	*httptest.Server
+
+	b        *backend
+	pageSize int
+
+	callsMu sync.Mutex
+	calls   map[string]int
+}
+
+// Option configures a Server.
+type Option func(*Server)
+
+// WithPageSize caps every listing at n items, regardless of the MaxItems a
+// client asks for, so pagination can be exercised with a few records.
+func WithPageSize(n int) Option {
+	return func(s *Server) { s.pageSize = n }
+}
+
+// WithChangesInSync makes changes INSYNC as soon as they are submitted, like
+// most emulators. By default they are PENDING until the first GetChange.
+func WithChangesInSync() Option {
+	return func(s *Server) { s.b.changesInSync = true }
+}
+
+// NewServer starts a fake Route53 with no hosted zones. Close it when done.
+func NewServer(opts ...Option) *Server {
+	s := &Server{b: newBackend(), calls: map[string]int{}}
+	for _, o := range opts {
+		o(s)
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Client returns an SDK client talking to the server with static
+// credentials.
+func (s *Server) Client() *route53.Client {
+	return route53.New(route53.Options{
+		BaseEndpoint: aws.String(s.URL),
+		Region:       "us-east-1",
+		Credentials:  credentials.NewStaticCredentialsProvider("test", "test", ""),
+	})
+}
+
+// SetEnv isolates the test from the machine's AWS configuration and gives
+// the SDK static credentials, so clients built from the default
+// configuration can talk to a Server.
+func SetEnv(t testing.TB) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("AWS_CONFIG_FILE", dir+"/config")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", dir+"/credentials")
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	t.Setenv("AWS_SESSION_TOKEN", "")
+	t.Setenv("AWS_PROFILE", "")
+	t.Setenv("AWS_REGION", "us-east-1")
+	t.Setenv("AWS_ENDPOINT_URL", "")
+	t.Setenv("AWS_ENDPOINT_URL_ROUTE_53", "")
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+}
+
+// Calls returns how many times an operation, such as
+// "ListResourceRecordSets", was called.
+func (s *Server) Calls(op string) int {
+	s.callsMu.Lock()
+	defer s.callsMu.Unlock()
+	return s.calls[op]
+}
+
+// AddZone creates a public hosted zone and returns its ID.
+func (s *Server) AddZone(name string) string {
+	return s.addZone(createHostedZoneRequest{Name: name})
+}
+
+// AddPrivateZone creates a private hosted zone associated with a VPC and
+// returns its ID.
+func (s *Server) AddPrivateZone(name, vpcID, vpcRegion string) string {
+	return s.addZone(createHostedZoneRequest{
+		Name:             name,
+		HostedZoneConfig: &xmlZoneConfig{PrivateZone: true},
+		VPC:              &xmlVPC{VPCId: vpcID, VPCRegion: vpcRegion},
+	})
+}
+
+func (s *Server) addZone(req createHostedZoneRequest) string {
+	s.b.mu.Lock()
+	defer s.b.mu.Unlock()
+	req.CallerReference = s.b.nextID("ref-")
+	z, _, err := s.b.createZone(req)
+	if err != nil {
+		panic(err)
+	}
+	return "/hostedzone/" + z.id
+}
+
+// AddRecordSets upserts record sets into a zone, with the same validation
+// as ChangeResourceRecordSets.
+func (s *Server) AddRecordSets(zoneID string, records ...rtypes.ResourceRecordSet) error {
+	req := changeResourceRecordSetsRequest{}
+	for _, rs := range records {
+		x := fromRecordSet(rs)
+		req.Changes = append(req.Changes, xmlChange{Action: "UPSERT", ResourceRecordSet: &x})
+	}
+	s.b.mu.Lock()
+	defer s.b.mu.Unlock()
+	_, err := s.b.applyChanges(zoneID, req)
+	return err
+}
+
+// RecordSets returns a zone's record sets in listing order, or nil when the
+// zone does not exist.
+func (s *Server) RecordSets(zoneID string) []rtypes.ResourceRecordSet {
+	s.b.mu.Lock()
+	defer s.b.mu.Unlock()
+	z, err := s.b.zone(zoneID)
+	if err != nil {
+		return nil
+	}
+	records := []rtypes.ResourceRecordSet{}
+	for _, rs := range z.records {
+		records = append(records, rs.recordSet())
+	}
+	return records
+}
+
+// Zones returns the hosted zones in creation order.
+func (s *Server) Zones() []rtypes.HostedZone {
+	s.b.mu.Lock()
+	defer s.b.mu.Unlock()
+	zones := []rtypes.HostedZone{}
+	for _, z := range s.b.zones {
+		hz := z.hostedZone()
+		zones = append(zones, rtypes.HostedZone{
+			Id:                     aws.String(hz.Id),
+			Name:                   aws.String(hz.Name),
+			CallerReference:        aws.String(hz.CallerReference),
+			ResourceRecordSetCount: aws.Int64(hz.ResourceRecordSetCount),
+			Config:                 &rtypes.HostedZoneConfig{Comment: optional(hz.Config.Comment), PrivateZone: hz.Config.PrivateZone},
+		})
+	}
+	return zones
+}
+
+// SetTags adds tags to a zone.
+func (s *Server) SetTags(zoneID string, tags map[string]string) error {
+	req := changeTagsRequest{}
+	for k, v := range tags {
+		req.AddTags = append(req.AddTags, xmlTag{Key: k, Value: v})
+	}
+	s.b.mu.Lock()
+	defer s.b.mu.Unlock()
+	return s.b.changeTags(zoneID, req)
+}
+
+// Tags returns a zone's tags, or nil when the zone does not exist.
+func (s *Server) Tags(zoneID string) map[string]string {
+	s.b.mu.Lock()
+	defer s.b.mu.Unlock()
+	z, err := s.b.zone(zoneID)
+	if err != nil {
+		return nil
+	}
+	tags := map[string]string{}
+	for k, v := range z.tags {
+		tags[k] = v
+	}
+	return tags
+}
+
+func (s *Server) count(op string) {
+	s.callsMu.Lock()
+	defer s.callsMu.Unlock()
+	s.calls[op]++
+}
+
+// pathSegments splits the request path after the API version, unescaping
+// each segment so IDs such as "/change/C1" survive.
+func pathSegments(r *http.Request) []string {
+	p := strings.TrimPrefix(r.URL.EscapedPath(), apiPrefix)
+	p = strings.TrimSuffix(p, "/")
+	segments := []string{}
+	for _, seg := range strings.Split(p, "/") {
+		if u, err := url.PathUnescape(seg); err == nil {
+			seg = u
+		}
+		segments = append(segments, seg)
+	}
+	return segments
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.URL.Path, apiPrefix) {
+		writeError(w, &apiError{status: http.StatusNotFound, code: "UnknownOperation", message: r.URL.Path})
+		return
+	}
+	seg := pathSegments(r)
+	route := func(method string, n int, names ...string) bool {
+		if r.Method != method || len(seg) != n {
+			return false
+		}
+		for i, name := range names {
+			if name != "" && seg[i] != name {
+				return false
+			}
+		}
+		return true
+	}
+
+	s.b.mu.Lock()
+	defer s.b.mu.Unlock()
+
+	switch {
+	case route(http.MethodPost, 1, "hostedzone"):
+		s.createHostedZone(w, r)
+	case route(http.MethodGet, 1, "hostedzone"):
+		s.listHostedZones(w, r)
+	case route(http.MethodGet, 1, "hostedzonesbyname"):
+		s.listHostedZonesByName(w, r)
+	case route(http.MethodGet, 2, "hostedzone"):
+		s.getHostedZone(w, seg[1])
+	case route(http.MethodDelete, 2, "hostedzone"):
+		s.deleteHostedZone(w, seg[1])
+	case route(http.MethodPost, 3, "hostedzone", "", "rrset"):
+		s.changeResourceRecordSets(w, r, seg[1])
+	case route(http.MethodGet, 3, "hostedzone", "", "rrset"):
+		s.listResourceRecordSets(w, r, seg[1])
+	case route(http.MethodPost, 3, "hostedzone", "", "associatevpc"):
+		s.associateVPC(w, r, seg[1])
+	case route(http.MethodGet, 2, "change"):
+		s.getChange(w, seg[1])
+	case route(http.MethodGet, 3, "tags", "hostedzone"):
+		s.listTags(w, seg[2])
+	case route(http.MethodPost, 3, "tags", "hostedzone"):
+		s.changeTags(w, r, seg[2])
+	default:
+		writeError(w, &apiError{status: http.StatusBadRequest, code: "UnknownOperation",
+			message: r.Method + " " + r.URL.Path + " is not implemented by route53test"})
+	}
+}
+
+func (s *Server) createHostedZone(w http.ResponseWriter, r *http.Request) {
+	s.count("CreateHostedZone")
+	req := createHostedZoneRequest{}
+	if !decode(w, r, &req) {
+		return
+	}
+	z, c, err := s.b.createZone(req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	resp := createHostedZoneResponse{
+		HostedZone:    z.hostedZone(),
+		ChangeInfo:    c.info(),
+		DelegationSet: &xmlDelegationSet{NameServers: z.nameservers},
+	}
+	if z.private {
+		resp.DelegationSet = nil
+		resp.VPC = &z.vpcs[0]
+	}
+	w.Header().Set("Location", s.URL+apiPrefix+"hostedzone/"+z.id)
+	writeXML(w, http.StatusCreated, resp)
+}
+
+func (s *Server) listHostedZones(w http.ResponseWriter, r *http.Request) {
+	s.count("ListHostedZones")
+	q := r.URL.Query()
+	maxItems := s.maxItems(q.Get("maxitems"), 100)
+
+	start := 0
+	if marker := q.Get("marker"); marker != "" {
+		start = len(s.b.zones)
+		for i, z := range s.b.zones {
+			if z.id == marker {
+				start = i
+				break
+			}
+		}
+	}
+	end := min(start+maxItems, len(s.b.zones))
+
+	resp := listHostedZonesResponse{Marker: q.Get("marker"), MaxItems: maxItems, HostedZones: []xmlHostedZone{}}
+	for _, z := range s.b.zones[start:end] {
+		resp.HostedZones = append(resp.HostedZones, z.hostedZone())
+	}
+	if end < len(s.b.zones) {
+		resp.IsTruncated = true
+		resp.NextMarker = s.b.zones[end].id
+	}
+	writeXML(w, http.StatusOK, resp)
+}
+
+func (s *Server) listHostedZonesByName(w http.ResponseWriter, r *http.Request) {
+	s.count("ListHostedZonesByName")
+	q := r.URL.Query()
+	maxItems := s.maxItems(q.Get("maxitems"), 100)
+	dnsName, zoneID := q.Get("dnsname"), strings.TrimPrefix(q.Get("hostedzoneid"), "/hostedzone/")
+
+	zones := s.b.zonesByName()
+	start := 0
+	if dnsName != "" {
+		from := sortKey(normalizeName(dnsName))
+		start = sort.Search(len(zones), func(i int) bool {
+			k := sortKey(zones[i].name)
+			return k > from || (k == from && zones[i].id >= zoneID)
+		})
+	}
+	end := min(start+maxItems, len(zones))
+
+	resp := listHostedZonesByNameResponse{DNSName: dnsName, HostedZoneId: q.Get("hostedzoneid"), MaxItems: maxItems, HostedZones: []xmlHostedZone{}}
+	for _, z := range zones[start:end] {
+		resp.HostedZones = append(resp.HostedZones, z.hostedZone())
+	}
+	if end < len(zones) {
+		resp.IsTruncated = true
+		resp.NextDNSName = zones[end].name
+		resp.NextHostedZoneId = zones[end].id
+	}
+	writeXML(w, http.StatusOK, resp)
+}
+
+func (s *Server) getHostedZone(w http.ResponseWriter, id string) {
+	s.count("GetHostedZone")
+	z, err := s.b.zone(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	resp := getHostedZoneResponse{HostedZone: z.hostedZone()}
+	if z.private {
+		resp.VPCs = z.vpcs
+	} else {
+		resp.DelegationSet = &xmlDelegationSet{NameServers: z.nameservers}
+	}
+	writeXML(w, http.StatusOK, resp)
+}
+
+func (s *Server) deleteHostedZone(w http.ResponseWriter, id string) {
+	s.count("DeleteHostedZone")
+	c, err := s.b.deleteZone(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeXML(w, http.StatusOK, deleteHostedZoneResponse{ChangeInfo: c.info()})
+}
+
+func (s *Server) changeResourceRecordSets(w http.ResponseWriter, r *http.Request, id string) {
+	s.count("ChangeResourceRecordSets")
+	req := changeResourceRecordSetsRequest{}
+	if !decode(w, r, &req) {
+		return
+	}
+	c, err := s.b.applyChanges(id, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeXML(w, http.StatusOK, changeResourceRecordSetsResponse{ChangeInfo: c.info()})
+}
+
+func (s *Server) listResourceRecordSets(w http.ResponseWriter, r *http.Request, id string) {
+	s.count("ListResourceRecordSets")
+	q := r.URL.Query()
+	resp, err := s.b.listRecords(id, q.Get("name"), q.Get("type"), q.Get("identifier"), s.maxItems(q.Get("maxitems"), 300))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeXML(w, http.StatusOK, resp)
+}
+
+func (s *Server) associateVPC(w http.ResponseWriter, r *http.Request, id string) {
+	s.count("AssociateVPCWithHostedZone")
+	req := associateVPCRequest{}
+	if !decode(w, r, &req) {
+		return
+	}
+	c, err := s.b.associateVPC(id, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeXML(w, http.StatusOK, associateVPCResponse{ChangeInfo: c.info()})
+}
+
+func (s *Server) getChange(w http.ResponseWriter, id string) {
+	s.count("GetChange")
+	c, ok := s.b.changes[strings.TrimPrefix(id, "/change/")]
+	if !ok {
+		writeError(w, &apiError{status: http.StatusNotFound, code: "NoSuchChange", message: "A change with the specified change ID does not exist: " + id})
+		return
+	}
+	// Changes propagate once somebody asks about them, so waiters finish
+	// after a single poll.
+	c.status = "INSYNC"
+	writeXML(w, http.StatusOK, getChangeResponse{ChangeInfo: c.info()})
+}
+
+func (s *Server) listTags(w http.ResponseWriter, id string) {
+	s.count("ListTagsForResource")
+	z, err := s.b.zone(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	resp := listTagsForResourceResponse{ResourceType: "hostedzone", ResourceId: z.id, Tags: []xmlTag{}}
+	keys := make([]string, 0, len(z.tags))
+	for k := range z.tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		resp.Tags = append(resp.Tags, xmlTag{Key: k, Value: z.tags[k]})
+	}
+	writeXML(w, http.StatusOK, resp)
+}
+
+func (s *Server) changeTags(w http.ResponseWriter, r *http.Request, id string) {
+	s.count("ChangeTagsForResource")
+	req := changeTagsRequest{}
+	if !decode(w, r, &req) {
+		return
+	}
+	if err := s.b.changeTags(id, req); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeXML(w, http.StatusOK, changeTagsResponse{})
+}
+
+// maxItems applies the server's page size to the client's MaxItems.
+func (s *Server) maxItems(param string, def int) int {
+	n := def
+	if v, err := strconv.Atoi(param); err == nil && v > 0 {
+		n = min(v, def)
+	}
+	if s.pageSize > 0 {
+		n = min(n, s.pageSize)
+	}
+	return n
+}
+
+func decode(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	body, err := io.ReadAll(r.Body)
+	if err == nil {
+		err = xml.Unmarshal(body, v)
+	}
+	if err != nil {
+		writeError(w, invalidInput("Could not parse request: %s", err))
+		return false
+	}
+	return true
+}
+
+func writeXML(w http.ResponseWriter, status int, v interface{}) {
+	body, err := xml.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/xml")
+	w.Header().Set("X-Amzn-RequestId", "route53test")
+	w.WriteHeader(status)
+	_, _ = io.WriteString(w, xml.Header)
+	_, _ = w.Write(body)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	e, ok := err.(*apiError)
+	if !ok {
+		e = &apiError{status: http.StatusInternalServerError, code: "InternalError", message: err.Error()}
+	}
+	if len(e.messages) > 0 {
+		writeXML(w, e.status, invalidChangeBatchResponse{Messages: e.messages, RequestId: "route53test"})
+		return
+	}
+	typ := "Sender"
+	if e.status >= http.StatusInternalServerError {
+		typ = "Receiver"
+	}
+	writeXML(w, e.status, errorResponse{Type: typ, Code: e.code, Message: e.message, RequestId: "route53test"})
+}
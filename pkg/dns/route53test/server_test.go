@@ -0,0 +1,217 @@
+package route53test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/route53"
+	rtypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/stretchr/testify/require"
+)
+
+func aRecord(name, value string) rtypes.ResourceRecordSet {
+	return rtypes.ResourceRecordSet{
+		Name:            aws.String(name),
+		Type:            rtypes.RRTypeA,
+		TTL:             aws.Int64(300),
+		ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String(value)}},
+	}
+}
+
+func changeOf(action rtypes.ChangeAction, rs rtypes.ResourceRecordSet) rtypes.Change {
+	return rtypes.Change{Action: action, ResourceRecordSet: &rs}
+}
+
+func TestServer_ZoneLifecycle(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	cli := s.Client()
+	ctx := context.Background()
+
+	created, err := cli.CreateHostedZone(ctx, &route53.CreateHostedZoneInput{
+		Name:            aws.String("Example.com"),
+		CallerReference: aws.String("ref-1"),
+	})
+	require.NoError(t, err)
+	require.Equal(t, "example.com.", aws.ToString(created.HostedZone.Name))
+	require.Len(t, created.DelegationSet.NameServers, 4)
+	require.Equal(t, rtypes.ChangeStatusPending, created.ChangeInfo.Status)
+
+	_, err = cli.CreateHostedZone(ctx, &route53.CreateHostedZoneInput{Name: aws.String("example.com"), CallerReference: aws.String("ref-1")})
+	var exists *rtypes.HostedZoneAlreadyExists
+	require.ErrorAs(t, err, &exists)
+
+	// The SDK strips the /hostedzone/ prefix, the fake accepts both forms.
+	got, err := cli.GetHostedZone(ctx, &route53.GetHostedZoneInput{Id: created.HostedZone.Id})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), aws.ToInt64(got.HostedZone.ResourceRecordSetCount))
+
+	require.Equal(t, rtypes.ChangeStatusPending, created.ChangeInfo.Status)
+	ch, err := cli.GetChange(ctx, &route53.GetChangeInput{Id: created.ChangeInfo.Id})
+	require.NoError(t, err)
+	require.Equal(t, rtypes.ChangeStatusInsync, ch.ChangeInfo.Status)
+
+	require.NoError(t, s.AddRecordSets(*created.HostedZone.Id, aRecord("www.example.com", "192.0.2.1")))
+	_, err = cli.DeleteHostedZone(ctx, &route53.DeleteHostedZoneInput{Id: created.HostedZone.Id})
+	var notEmpty *rtypes.HostedZoneNotEmpty
+	require.ErrorAs(t, err, &notEmpty)
+
+	_, err = cli.ChangeResourceRecordSets(ctx, &route53.ChangeResourceRecordSetsInput{
+		HostedZoneId: created.HostedZone.Id,
+		ChangeBatch:  &rtypes.ChangeBatch{Changes: []rtypes.Change{changeOf(rtypes.ChangeActionDelete, aRecord("www.example.com.", "192.0.2.1"))}},
+	})
+	require.NoError(t, err)
+	_, err = cli.DeleteHostedZone(ctx, &route53.DeleteHostedZoneInput{Id: created.HostedZone.Id})
+	require.NoError(t, err)
+
+	_, err = cli.GetHostedZone(ctx, &route53.GetHostedZoneInput{Id: created.HostedZone.Id})
+	var missing *rtypes.NoSuchHostedZone
+	require.ErrorAs(t, err, &missing)
+}
+
+func TestServer_ChangeBatchValidation(t *testing.T) {
+	s := NewServer(WithChangesInSync())
+	defer s.Close()
+	cli := s.Client()
+	ctx := context.Background()
+	zoneID := s.AddZone("example.com")
+	require.NoError(t, s.AddRecordSets(zoneID, aRecord("www.example.com", "192.0.2.1")))
+
+	apply := func(changes ...rtypes.Change) error {
+		_, err := cli.ChangeResourceRecordSets(ctx, &route53.ChangeResourceRecordSetsInput{
+			HostedZoneId: aws.String(zoneID),
+			ChangeBatch:  &rtypes.ChangeBatch{Changes: changes},
+		})
+		return err
+	}
+	messages := func(err error) []string {
+		var icb *rtypes.InvalidChangeBatch
+		require.True(t, errors.As(err, &icb), "expected InvalidChangeBatch, got %v", err)
+		return icb.Messages
+	}
+
+	// A failing batch changes nothing.
+	err := apply(
+		changeOf(rtypes.ChangeActionCreate, aRecord("new.example.com", "192.0.2.9")),
+		changeOf(rtypes.ChangeActionCreate, aRecord("www.example.com", "192.0.2.2")),
+	)
+	require.Equal(t, []string{"Tried to create resource record set [name='www.example.com.', type='A'] but it already exists"}, messages(err))
+	require.Len(t, s.RecordSets(zoneID), 3)
+
+	err = apply(changeOf(rtypes.ChangeActionDelete, aRecord("www.example.com", "192.0.2.2")))
+	require.Contains(t, messages(err)[0], "do not match the current values")
+
+	err = apply(changeOf(rtypes.ChangeActionDelete, aRecord("gone.example.com", "192.0.2.2")))
+	require.Contains(t, messages(err)[0], "but it was not found")
+
+	err = apply(changeOf(rtypes.ChangeActionUpsert, aRecord("www.example.org", "192.0.2.2")))
+	require.Contains(t, messages(err)[0], "is not permitted in zone example.com.")
+
+	cname := rtypes.ResourceRecordSet{Name: aws.String("www.example.com"), Type: rtypes.RRTypeCname, TTL: aws.Int64(60),
+		ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String("lb.example.net.")}}}
+	err = apply(changeOf(rtypes.ChangeActionCreate, cname))
+	require.Contains(t, messages(err)[0], "conflicts with other records")
+
+	// Deleting and recreating a record set in one batch replaces it.
+	require.NoError(t, apply(
+		changeOf(rtypes.ChangeActionDelete, aRecord("www.example.com", "192.0.2.1")),
+		changeOf(rtypes.ChangeActionCreate, cname),
+	))
+
+	// Names come back escaped the way Route53 returns them.
+	require.NoError(t, apply(changeOf(rtypes.ChangeActionUpsert, aRecord("*.example.com", "192.0.2.3"))))
+	names := []string{}
+	for _, rs := range s.RecordSets(zoneID) {
+		names = append(names, aws.ToString(rs.Name)+" "+string(rs.Type))
+	}
+	require.Equal(t, []string{"example.com. NS", "example.com. SOA", `\052.example.com. A`, "www.example.com. CNAME"}, names)
+
+	_, err = cli.ChangeResourceRecordSets(ctx, &route53.ChangeResourceRecordSetsInput{
+		HostedZoneId: aws.String(zoneID),
+		ChangeBatch: &rtypes.ChangeBatch{Changes: []rtypes.Change{{Action: rtypes.ChangeActionCreate,
+			ResourceRecordSet: &rtypes.ResourceRecordSet{Name: aws.String("x.example.com"), Type: rtypes.RRTypeA}}}},
+	})
+	var invalid *rtypes.InvalidInput
+	require.ErrorAs(t, err, &invalid)
+}
+
+func TestServer_Pagination(t *testing.T) {
+	s := NewServer(WithPageSize(2))
+	defer s.Close()
+	cli := s.Client()
+	ctx := context.Background()
+
+	for _, name := range []string{"b.com", "a.com", "a.com", "c.org"} {
+		s.AddZone(name)
+	}
+
+	zones := []string{}
+	p := route53.NewListHostedZonesPaginator(cli, &route53.ListHostedZonesInput{})
+	for p.HasMorePages() {
+		page, err := p.NextPage(ctx)
+		require.NoError(t, err)
+		for _, z := range page.HostedZones {
+			zones = append(zones, aws.ToString(z.Name))
+		}
+	}
+	require.Equal(t, []string{"b.com.", "a.com.", "a.com.", "c.org."}, zones)
+	require.Equal(t, 2, s.Calls("ListHostedZones"))
+
+	byName, err := cli.ListHostedZonesByName(ctx, &route53.ListHostedZonesByNameInput{DNSName: aws.String("a.com")})
+	require.NoError(t, err)
+	require.True(t, byName.IsTruncated)
+	require.Equal(t, "a.com.", aws.ToString(byName.HostedZones[0].Name))
+	require.Equal(t, "a.com.", aws.ToString(byName.HostedZones[1].Name))
+	require.Equal(t, "b.com.", aws.ToString(byName.NextDNSName))
+}
+
+func TestServer_Tags(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	cli := s.Client()
+	ctx := context.Background()
+	zoneID := s.AddZone("example.com")
+
+	_, err := cli.ChangeTagsForResource(ctx, &route53.ChangeTagsForResourceInput{
+		ResourceId:   aws.String(zoneID[len("/hostedzone/"):]),
+		ResourceType: rtypes.TagResourceTypeHostedzone,
+		AddTags:      []rtypes.Tag{{Key: aws.String("env"), Value: aws.String("prod")}, {Key: aws.String("parked"), Value: aws.String("true")}},
+	})
+	require.NoError(t, err)
+	_, err = cli.ChangeTagsForResource(ctx, &route53.ChangeTagsForResourceInput{
+		ResourceId:    aws.String(zoneID[len("/hostedzone/"):]),
+		ResourceType:  rtypes.TagResourceTypeHostedzone,
+		RemoveTagKeys: []string{"parked"},
+	})
+	require.NoError(t, err)
+
+	out, err := cli.ListTagsForResource(ctx, &route53.ListTagsForResourceInput{
+		ResourceId:   aws.String(zoneID[len("/hostedzone/"):]),
+		ResourceType: rtypes.TagResourceTypeHostedzone,
+	})
+	require.NoError(t, err)
+	require.Len(t, out.ResourceTagSet.Tags, 1)
+	require.Equal(t, map[string]string{"env": "prod"}, s.Tags(zoneID))
+}
+
+func TestServer_PrivateZones(t *testing.T) {
+	s := NewServer(WithChangesInSync())
+	defer s.Close()
+	cli := s.Client()
+	ctx := context.Background()
+	zoneID := s.AddPrivateZone("corp.internal", "vpc-1", "us-east-1")
+
+	_, err := cli.AssociateVPCWithHostedZone(ctx, &route53.AssociateVPCWithHostedZoneInput{
+		HostedZoneId: aws.String(zoneID),
+		VPC:          &rtypes.VPC{VPCId: aws.String("vpc-2"), VPCRegion: rtypes.VPCRegionEuWest1},
+	})
+	require.NoError(t, err)
+
+	got, err := cli.GetHostedZone(ctx, &route53.GetHostedZoneInput{Id: aws.String(zoneID)})
+	require.NoError(t, err)
+	require.True(t, got.HostedZone.Config.PrivateZone)
+	require.Len(t, got.VPCs, 2)
+	require.Nil(t, got.DelegationSet)
+}
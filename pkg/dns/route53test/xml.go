@@ -0,0 +1,282 @@
+package route53test
+
+import (
+	"encoding/xml"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	rtypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
+)
+
+const xmlns = "https://route53.amazonaws.com/doc/2013-04-01/"
+
+type xmlHostedZone struct {
+	Id                     string
+	Name                   string
+	CallerReference        string
+	Config                 *xmlZoneConfig `xml:",omitempty"`
+	ResourceRecordSetCount int64
+}
+
+type xmlZoneConfig struct {
+	Comment     string `xml:",omitempty"`
+	PrivateZone bool
+}
+
+type xmlChangeInfo struct {
+	Id          string
+	Status      string
+	SubmittedAt string
+	Comment     string `xml:",omitempty"`
+}
+
+type xmlVPC struct {
+	VPCRegion string `xml:",omitempty"`
+	VPCId     string `xml:",omitempty"`
+}
+
+type xmlDelegationSet struct {
+	NameServers []string `xml:"NameServers>NameServer"`
+}
+
+type xmlGeoLocation struct {
+	ContinentCode   string `xml:",omitempty"`
+	CountryCode     string `xml:",omitempty"`
+	SubdivisionCode string `xml:",omitempty"`
+}
+
+type xmlAliasTarget struct {
+	HostedZoneId         string
+	DNSName              string
+	EvaluateTargetHealth bool
+}
+
+type xmlResourceRecord struct {
+	Value string
+}
+
+type xmlRecordSet struct {
+	Name                    string
+	Type                    string
+	SetIdentifier           string              `xml:",omitempty"`
+	Weight                  *int64              `xml:",omitempty"`
+	Region                  string              `xml:",omitempty"`
+	GeoLocation             *xmlGeoLocation     `xml:",omitempty"`
+	Failover                string              `xml:",omitempty"`
+	MultiValueAnswer        *bool               `xml:",omitempty"`
+	TTL                     *int64              `xml:",omitempty"`
+	ResourceRecords         []xmlResourceRecord `xml:"ResourceRecords>ResourceRecord,omitempty"`
+	AliasTarget             *xmlAliasTarget     `xml:",omitempty"`
+	HealthCheckId           string              `xml:",omitempty"`
+	TrafficPolicyInstanceId string              `xml:",omitempty"`
+}
+
+type xmlTag struct {
+	Key   string
+	Value string
+}
+
+// Requests.
+
+type createHostedZoneRequest struct {
+	XMLName          xml.Name `xml:"CreateHostedZoneRequest"`
+	Name             string
+	CallerReference  string
+	HostedZoneConfig *xmlZoneConfig
+	VPC              *xmlVPC
+}
+
+type changeResourceRecordSetsRequest struct {
+	XMLName xml.Name    `xml:"ChangeResourceRecordSetsRequest"`
+	Comment string      `xml:"ChangeBatch>Comment"`
+	Changes []xmlChange `xml:"ChangeBatch>Changes>Change"`
+}
+
+type xmlChange struct {
+	Action            string
+	ResourceRecordSet *xmlRecordSet
+}
+
+type changeTagsRequest struct {
+	XMLName       xml.Name `xml:"ChangeTagsForResourceRequest"`
+	AddTags       []xmlTag `xml:"AddTags>Tag"`
+	RemoveTagKeys []string `xml:"RemoveTagKeys>Key"`
+}
+
+type associateVPCRequest struct {
+	XMLName xml.Name `xml:"AssociateVPCWithHostedZoneRequest"`
+	VPC     xmlVPC
+	Comment string
+}
+
+// Responses.
+
+type createHostedZoneResponse struct {
+	XMLName       xml.Name `xml:"https://route53.amazonaws.com/doc/2013-04-01/ CreateHostedZoneResponse"`
+	HostedZone    xmlHostedZone
+	ChangeInfo    xmlChangeInfo
+	DelegationSet *xmlDelegationSet `xml:",omitempty"`
+	VPC           *xmlVPC           `xml:",omitempty"`
+}
+
+type getHostedZoneResponse struct {
+	XMLName       xml.Name `xml:"https://route53.amazonaws.com/doc/2013-04-01/ GetHostedZoneResponse"`
+	HostedZone    xmlHostedZone
+	DelegationSet *xmlDelegationSet `xml:",omitempty"`
+	VPCs          []xmlVPC          `xml:"VPCs>VPC,omitempty"`
+}
+
+type listHostedZonesResponse struct {
+	XMLName     xml.Name        `xml:"https://route53.amazonaws.com/doc/2013-04-01/ ListHostedZonesResponse"`
+	HostedZones []xmlHostedZone `xml:"HostedZones>HostedZone"`
+	Marker      string          `xml:",omitempty"`
+	IsTruncated bool
+	NextMarker  string `xml:",omitempty"`
+	MaxItems    int
+}
+
+type listHostedZonesByNameResponse struct {
+	XMLName          xml.Name        `xml:"https://route53.amazonaws.com/doc/2013-04-01/ ListHostedZonesByNameResponse"`
+	HostedZones      []xmlHostedZone `xml:"HostedZones>HostedZone"`
+	DNSName          string          `xml:",omitempty"`
+	HostedZoneId     string          `xml:",omitempty"`
+	IsTruncated      bool
+	NextDNSName      string `xml:",omitempty"`
+	NextHostedZoneId string `xml:",omitempty"`
+	MaxItems         int
+}
+
+type deleteHostedZoneResponse struct {
+	XMLName    xml.Name `xml:"https://route53.amazonaws.com/doc/2013-04-01/ DeleteHostedZoneResponse"`
+	ChangeInfo xmlChangeInfo
+}
+
+type associateVPCResponse struct {
+	XMLName    xml.Name `xml:"https://route53.amazonaws.com/doc/2013-04-01/ AssociateVPCWithHostedZoneResponse"`
+	ChangeInfo xmlChangeInfo
+}
+
+type changeResourceRecordSetsResponse struct {
+	XMLName    xml.Name `xml:"https://route53.amazonaws.com/doc/2013-04-01/ ChangeResourceRecordSetsResponse"`
+	ChangeInfo xmlChangeInfo
+}
+
+type listResourceRecordSetsResponse struct {
+	XMLName              xml.Name       `xml:"https://route53.amazonaws.com/doc/2013-04-01/ ListResourceRecordSetsResponse"`
+	ResourceRecordSets   []xmlRecordSet `xml:"ResourceRecordSets>ResourceRecordSet"`
+	IsTruncated          bool
+	NextRecordName       string `xml:",omitempty"`
+	NextRecordType       string `xml:",omitempty"`
+	NextRecordIdentifier string `xml:",omitempty"`
+	MaxItems             int
+}
+
+type getChangeResponse struct {
+	XMLName    xml.Name `xml:"https://route53.amazonaws.com/doc/2013-04-01/ GetChangeResponse"`
+	ChangeInfo xmlChangeInfo
+}
+
+type listTagsForResourceResponse struct {
+	XMLName      xml.Name `xml:"https://route53.amazonaws.com/doc/2013-04-01/ ListTagsForResourceResponse"`
+	ResourceType string   `xml:"ResourceTagSet>ResourceType"`
+	ResourceId   string   `xml:"ResourceTagSet>ResourceId"`
+	Tags         []xmlTag `xml:"ResourceTagSet>Tags>Tag"`
+}
+
+type changeTagsResponse struct {
+	XMLName xml.Name `xml:"https://route53.amazonaws.com/doc/2013-04-01/ ChangeTagsForResourceResponse"`
+}
+
+type errorResponse struct {
+	XMLName   xml.Name `xml:"https://route53.amazonaws.com/doc/2013-04-01/ ErrorResponse"`
+	Type      string   `xml:"Error>Type"`
+	Code      string   `xml:"Error>Code"`
+	Message   string   `xml:"Error>Message"`
+	RequestId string
+}
+
+type invalidChangeBatchResponse struct {
+	XMLName   xml.Name `xml:"https://route53.amazonaws.com/doc/2013-04-01/ InvalidChangeBatch"`
+	Messages  []string `xml:"Messages>Message"`
+	RequestId string
+}
+
+// Conversions between the SDK types and the wire shapes.
+
+func fromRecordSet(rs rtypes.ResourceRecordSet) xmlRecordSet {
+	x := xmlRecordSet{
+		Name:                    aws.ToString(rs.Name),
+		Type:                    string(rs.Type),
+		SetIdentifier:           aws.ToString(rs.SetIdentifier),
+		Weight:                  rs.Weight,
+		Region:                  string(rs.Region),
+		Failover:                string(rs.Failover),
+		MultiValueAnswer:        rs.MultiValueAnswer,
+		TTL:                     rs.TTL,
+		HealthCheckId:           aws.ToString(rs.HealthCheckId),
+		TrafficPolicyInstanceId: aws.ToString(rs.TrafficPolicyInstanceId),
+	}
+	if g := rs.GeoLocation; g != nil {
+		x.GeoLocation = &xmlGeoLocation{
+			ContinentCode:   aws.ToString(g.ContinentCode),
+			CountryCode:     aws.ToString(g.CountryCode),
+			SubdivisionCode: aws.ToString(g.SubdivisionCode),
+		}
+	}
+	for _, rr := range rs.ResourceRecords {
+		x.ResourceRecords = append(x.ResourceRecords, xmlResourceRecord{Value: aws.ToString(rr.Value)})
+	}
+	if a := rs.AliasTarget; a != nil {
+		x.AliasTarget = &xmlAliasTarget{
+			HostedZoneId:         aws.ToString(a.HostedZoneId),
+			DNSName:              aws.ToString(a.DNSName),
+			EvaluateTargetHealth: a.EvaluateTargetHealth,
+		}
+	}
+	return x
+}
+
+func (x xmlRecordSet) recordSet() rtypes.ResourceRecordSet {
+	rs := rtypes.ResourceRecordSet{
+		Name:             aws.String(x.Name),
+		Type:             rtypes.RRType(x.Type),
+		Weight:           x.Weight,
+		Region:           rtypes.ResourceRecordSetRegion(x.Region),
+		Failover:         rtypes.ResourceRecordSetFailover(x.Failover),
+		MultiValueAnswer: x.MultiValueAnswer,
+		TTL:              x.TTL,
+	}
+	if x.SetIdentifier != "" {
+		rs.SetIdentifier = aws.String(x.SetIdentifier)
+	}
+	if x.HealthCheckId != "" {
+		rs.HealthCheckId = aws.String(x.HealthCheckId)
+	}
+	if x.TrafficPolicyInstanceId != "" {
+		rs.TrafficPolicyInstanceId = aws.String(x.TrafficPolicyInstanceId)
+	}
+	if g := x.GeoLocation; g != nil {
+		rs.GeoLocation = &rtypes.GeoLocation{
+			ContinentCode:   optional(g.ContinentCode),
+			CountryCode:     optional(g.CountryCode),
+			SubdivisionCode: optional(g.SubdivisionCode),
+		}
+	}
+	for _, rr := range x.ResourceRecords {
+		rs.ResourceRecords = append(rs.ResourceRecords, rtypes.ResourceRecord{Value: aws.String(rr.Value)})
+	}
+	if a := x.AliasTarget; a != nil {
+		rs.AliasTarget = &rtypes.AliasTarget{
+			HostedZoneId:         aws.String(a.HostedZoneId),
+			DNSName:              aws.String(a.DNSName),
+			EvaluateTargetHealth: a.EvaluateTargetHealth,
+		}
+	}
+	return rs
+}
+
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return aws.String(s)
+}
@@ -0,0 +1,203 @@
+package dns
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/v4/models"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	rtypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
+)
+
+var hclNameUnsafe = regexp.MustCompile(`[^a-z0-9_-]+`)
+
+// hclItem is an attribute or a nested block of an HCL block.
+type hclItem struct {
+	key   string
+	value string
+	block []hclItem
+}
+
+// writeTerraform renders one aws_route53_record resource per record set,
+// each with an import block so existing records can be adopted.
+func writeTerraform(w io.Writer, zone rtypes.HostedZone, records []rtypes.ResourceRecordSet) error {
+	origin := DenormalizeDomain(strings.ToLower(aws.ToString(zone.Name)))
+	zoneID := strings.TrimPrefix(aws.ToString(zone.Id), "/hostedzone/")
+
+	fmt.Fprintf(w, "# Exported by r53tool from %s (%s).\n", zoneID, origin)
+	names := map[string]int{}
+	for _, rs := range managedRecordSets(origin, records) {
+		name := uniqueName(names, terraformName(origin, rs))
+		fqdn := DenormalizeDomain(unescapeName(aws.ToString(rs.Name)))
+
+		fmt.Fprintf(w, "\nresource \"aws_route53_record\" %s {\n", hclString(name))
+		writeHCL(w, terraformRecord(zoneID, fqdn, rs), "  ")
+		fmt.Fprintln(w, "}")
+
+		importID := fmt.Sprintf("%s_%s_%s", zoneID, fqdn, rs.Type)
+		if rs.SetIdentifier != nil {
+			importID += "_" + aws.ToString(rs.SetIdentifier)
+		}
+		fmt.Fprintln(w, "\nimport {")
+		writeHCL(w, []hclItem{
+			{key: "to", value: "aws_route53_record." + name},
+			{key: "id", value: hclString(importID)},
+		}, "  ")
+		fmt.Fprintln(w, "}")
+	}
+	return nil
+}
+
+func terraformRecord(zoneID, fqdn string, rs rtypes.ResourceRecordSet) []hclItem {
+	items := []hclItem{
+		{key: "zone_id", value: hclString(zoneID)},
+		{key: "name", value: hclString(fqdn)},
+		{key: "type", value: hclString(string(rs.Type))},
+	}
+	if rs.TTL != nil {
+		items = append(items, hclItem{key: "ttl", value: strconv.FormatInt(aws.ToInt64(rs.TTL), 10)})
+	}
+	if len(rs.ResourceRecords) > 0 {
+		values := []string{}
+		for _, v := range rs.ResourceRecords {
+			values = append(values, hclString(terraformValue(rs.Type, aws.ToString(v.Value))))
+		}
+		items = append(items, hclItem{key: "records", value: "[" + strings.Join(values, ", ") + "]"})
+	}
+	if rs.SetIdentifier != nil {
+		items = append(items, hclItem{key: "set_identifier", value: hclString(aws.ToString(rs.SetIdentifier))})
+	}
+	if rs.HealthCheckId != nil {
+		items = append(items, hclItem{key: "health_check_id", value: hclString(aws.ToString(rs.HealthCheckId))})
+	}
+	if rs.MultiValueAnswer != nil {
+		items = append(items, hclItem{key: "multivalue_answer_routing_policy", value: strconv.FormatBool(aws.ToBool(rs.MultiValueAnswer))})
+	}
+	if a := rs.AliasTarget; a != nil {
+		items = append(items, hclItem{key: "alias", block: []hclItem{
+			{key: "name", value: hclString(aws.ToString(a.DNSName))},
+			{key: "zone_id", value: hclString(aws.ToString(a.HostedZoneId))},
+			{key: "evaluate_target_health", value: strconv.FormatBool(a.EvaluateTargetHealth)},
+		}})
+	}
+	if rs.Weight != nil {
+		items = append(items, hclItem{key: "weighted_routing_policy", block: []hclItem{
+			{key: "weight", value: strconv.FormatInt(aws.ToInt64(rs.Weight), 10)},
+		}})
+	}
+	if rs.Region != "" {
+		items = append(items, hclItem{key: "latency_routing_policy", block: []hclItem{
+			{key: "region", value: hclString(string(rs.Region))},
+		}})
+	}
+	if rs.Failover != "" {
+		items = append(items, hclItem{key: "failover_routing_policy", block: []hclItem{
+			{key: "type", value: hclString(string(rs.Failover))},
+		}})
+	}
+	if g := rs.GeoLocation; g != nil {
+		geo := []hclItem{}
+		for _, f := range []struct {
+			key   string
+			value *string
+		}{{"continent", g.ContinentCode}, {"country", g.CountryCode}, {"subdivision", g.SubdivisionCode}} {
+			if f.value != nil {
+				geo = append(geo, hclItem{key: f.key, value: hclString(aws.ToString(f.value))})
+			}
+		}
+		items = append(items, hclItem{key: "geolocation_routing_policy", block: geo})
+	}
+	if g := rs.GeoProximityLocation; g != nil {
+		geo := []hclItem{}
+		if g.AWSRegion != nil {
+			geo = append(geo, hclItem{key: "aws_region", value: hclString(aws.ToString(g.AWSRegion))})
+		}
+		if g.LocalZoneGroup != nil {
+			geo = append(geo, hclItem{key: "local_zone_group", value: hclString(aws.ToString(g.LocalZoneGroup))})
+		}
+		if g.Bias != nil {
+			geo = append(geo, hclItem{key: "bias", value: strconv.FormatInt(int64(aws.ToInt32(g.Bias)), 10)})
+		}
+		if c := g.Coordinates; c != nil {
+			geo = append(geo, hclItem{key: "coordinates", block: []hclItem{
+				{key: "latitude", value: hclString(aws.ToString(c.Latitude))},
+				{key: "longitude", value: hclString(aws.ToString(c.Longitude))},
+			}})
+		}
+		items = append(items, hclItem{key: "geoproximity_routing_policy", block: geo})
+	}
+	if c := rs.CidrRoutingConfig; c != nil {
+		items = append(items, hclItem{key: "cidr_routing_policy", block: []hclItem{
+			{key: "collection_id", value: hclString(aws.ToString(c.CollectionId))},
+			{key: "location_name", value: hclString(aws.ToString(c.LocationName))},
+		}})
+	}
+	return items
+}
+
+// terraformValue converts a Route53 value to the form the AWS provider
+// expects: TXT strings unquoted, with `""` between segments.
+func terraformValue(t rtypes.RRType, value string) string {
+	if t != rtypes.RRTypeTxt && t != rtypes.RRTypeSpf {
+		return value
+	}
+	return strings.Join(models.ParseQuotedTxt(value), `""`)
+}
+
+// terraformName derives a resource name from the record's label, type and
+// set identifier, e.g. www_a or apex_mx.
+func terraformName(origin string, rs rtypes.ResourceRecordSet) string {
+	label := octoLabel(origin, aws.ToString(rs.Name))
+	if label == "" {
+		label = "apex"
+	}
+	label = strings.ReplaceAll(label, "*", "wildcard")
+	parts := []string{label, string(rs.Type)}
+	if rs.SetIdentifier != nil {
+		parts = append(parts, aws.ToString(rs.SetIdentifier))
+	}
+	name := strings.Trim(hclNameUnsafe.ReplaceAllString(strings.ToLower(strings.Join(parts, "_")), "_"), "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') || name[0] == '-' {
+		name = "_" + name
+	}
+	return name
+}
+
+// uniqueName appends a counter to names already handed out.
+func uniqueName(seen map[string]int, name string) string {
+	seen[name]++
+	if n := seen[name]; n > 1 {
+		return fmt.Sprintf("%s_%d", name, n)
+	}
+	return name
+}
+
+// writeHCL writes items with the = of consecutive attributes aligned, like
+// terraform fmt.
+func writeHCL(w io.Writer, items []hclItem, indent string) {
+	for i := 0; i < len(items); {
+		if items[i].block != nil {
+			fmt.Fprintf(w, "\n%s%s {\n", indent, items[i].key)
+			writeHCL(w, items[i].block, indent+"  ")
+			fmt.Fprintf(w, "%s}\n", indent)
+			i++
+			continue
+		}
+		j, width := i, 0
+		for ; j < len(items) && items[j].block == nil; j++ {
+			width = max(width, len(items[j].key))
+		}
+		for ; i < j; i++ {
+			fmt.Fprintf(w, "%s%-*s = %s\n", indent, width, items[i].key, items[i].value)
+		}
+	}
+}
+
+// hclString quotes s as an HCL string, escaping template sequences.
+func hclString(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`, "${", "$${", "%{", "%%{")
+	return `"` + r.Replace(s) + `"`
+}
@@ -1,6 +1,11 @@
 package dns
 
-import "strings"
+import (
+	"regexp"
+	"strings"
+)
+
+var zoneIDPattern = regexp.MustCompile(`^(/hostedzone/)?Z[A-Z0-9]{4,31}$`)
 
 func NormalizeDomain(domain string) string {
 	if strings.HasSuffix(domain, ".") {
@@ -17,3 +22,15 @@ func DenormalizeDomain(domain string) string {
 		return domain
 	}
 }
+
+// IsRoute53Nameserver reports whether ns is one of the awsdns servers Route53
+// assigns to public hosted zones.
+func IsRoute53Nameserver(ns string) bool {
+	return strings.Contains(strings.ToLower(ns), ".awsdns-")
+}
+
+// IsZoneID reports whether s is a hosted zone ID, with or without the
+// /hostedzone/ prefix, rather than a domain name.
+func IsZoneID(s string) bool {
+	return zoneIDPattern.MatchString(s)
+}
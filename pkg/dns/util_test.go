@@ -36,3 +36,17 @@ func TestDenormalizeDomain(t *testing.T) {
 		require.Equal(t, c.out, got, "DenormalizeDomain(%q)", c.in)
 	}
 }
+
+func TestIsRoute53Nameserver(t *testing.T) {
+	require.True(t, IsRoute53Nameserver("ns-1234.awsdns-12.org."))
+	require.True(t, IsRoute53Nameserver("NS-99.AWSDNS-01.COM"))
+	require.False(t, IsRoute53Nameserver("ns1.example.net"))
+}
+
+func TestIsZoneID(t *testing.T) {
+	require.True(t, IsZoneID("Z1D633PJN98FT9"))
+	require.True(t, IsZoneID("/hostedzone/Z0123456789ABCDEFGHIJ"))
+	require.False(t, IsZoneID("example.com"))
+	require.False(t, IsZoneID("zexample"))
+	require.False(t, IsZoneID("Z1.example.com"))
+}
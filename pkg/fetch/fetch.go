@@ -10,6 +10,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/pedrokiefer/route53copy/pkg/dig"
 	"github.com/rs/dnscache"
 )
 
@@ -18,7 +19,7 @@ type hostResolver interface {
 	LookupHost(ctx context.Context, host string) ([]string, error)
 }
 
-var r hostResolver = &dnscache.Resolver{}
+var r hostResolver = &dnscache.Resolver{Resolver: dig.NetResolver()}
 
 func lookupWithRetry(ctx context.Context, host string, retries int) (addrs []string, err error) {
 	for i := 0; i < retries; i++ {
@@ -2,7 +2,6 @@ package vuln
 
 import (
 	"context"
-	"errors"
 	"log"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -10,6 +9,17 @@ import (
 	"github.com/pedrokiefer/route53copy/pkg/dig"
 )
 
+// resolveMissing resolves name and returns the response when the end of its
+// CNAME chain does not exist. NODATA answers mean the name exists and are not
+// reported.
+func resolveMissing(ctx context.Context, name string) (*dig.Response, bool) {
+	resp, _ := dig.Resolve(ctx, name, "A")
+	if resp == nil || !resp.NXDomain() {
+		return nil, false
+	}
+	return resp, true
+}
+
 func checkCNameExists(ctx context.Context, f *Findings, rs rtypes.ResourceRecordSet) {
 	if rs.Type != rtypes.RRTypeCname {
 		return
@@ -19,19 +29,13 @@ func checkCNameExists(ctx context.Context, f *Findings, rs rtypes.ResourceRecord
 	}
 
 	name := aws.ToString(rs.Name)
-	dst := aws.ToString(rs.ResourceRecords[0].Value)
-	err := dig.Resolve(ctx, name, "A")
-	if err == nil {
+	resp, missing := resolveMissing(ctx, name)
+	if !missing {
 		return
 	}
 
-	var derr *dig.ResolveError
-	if errors.As(err, &derr) {
-		if derr.Type == "NXDOMAIN" {
-			f.MisconfigRecords = append(f.MisconfigRecords, MisConfigRRFromAWS(rs, "CNAME points to missing name"))
-			log.Printf("%s CNAME %s points to missing name %s\n", MISCONFIG, name, dst)
-		}
-	}
+	f.MisconfigRecords = append(f.MisconfigRecords, MisConfigRRWithEvidence(rs, "CNAME points to missing name", resp))
+	log.Printf("%s CNAME %s points to missing name %s\n", MISCONFIG, name, resp.Target())
 }
 
 func checkAliasExists(ctx context.Context, f *Findings, rs rtypes.ResourceRecordSet) {
@@ -43,16 +47,11 @@ func checkAliasExists(ctx context.Context, f *Findings, rs rtypes.ResourceRecord
 	}
 	name := aws.ToString(rs.Name)
 	dst := aws.ToString(rs.AliasTarget.DNSName)
-	err := dig.Resolve(ctx, dst, "A")
-	if err == nil {
+	resp, missing := resolveMissing(ctx, dst)
+	if !missing {
 		return
 	}
 
-	var derr *dig.ResolveError
-	if errors.As(err, &derr) {
-		if derr.Type == "NXDOMAIN" {
-			f.MisconfigRecords = append(f.MisconfigRecords, MisConfigRRFromAWS(rs, "A with Alias points to missing name"))
-			log.Printf("%s A with Alias %s points to missing name %s\n", MISCONFIG, name, dst)
-		}
-	}
+	f.MisconfigRecords = append(f.MisconfigRecords, MisConfigRRWithEvidence(rs, "A with Alias points to missing name", resp))
+	log.Printf("%s A with Alias %s points to missing name %s\n", MISCONFIG, name, resp.Target())
 }
@@ -6,14 +6,21 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	rtypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/miekg/dns"
 	"github.com/pedrokiefer/route53copy/pkg/dig"
 	"github.com/stretchr/testify/require"
 )
 
 type fakeNXResolver struct{}
 
-func (fakeNXResolver) Resolve(ctx context.Context, domain string, t string) error {
-	return &dig.ResolveError{Domain: domain, Type: "NXDOMAIN"}
+func (fakeNXResolver) Resolve(ctx context.Context, domain string, t string) (*dig.Response, error) {
+	return &dig.Response{Name: domain, Type: t, Rcode: dns.RcodeNameError}, &dig.ResolveError{Domain: domain, Type: "NXDOMAIN"}
+}
+
+type fakeNoDataResolver struct{}
+
+func (fakeNoDataResolver) Resolve(ctx context.Context, domain string, t string) (*dig.Response, error) {
+	return &dig.Response{Name: domain, Type: t, Rcode: dns.RcodeSuccess}, nil
 }
 
 func TestCheckCNameExists_MisconfigOnNXDOMAIN(t *testing.T) {
@@ -43,3 +50,47 @@ func TestCheckAliasExists_MisconfigOnNXDOMAIN(t *testing.T) {
 	checkAliasExists(context.Background(), f, rs)
 	require.Len(t, f.MisconfigRecords, 1)
 }
+
+func TestCheckCNameExists_NoDataIsNotMissing(t *testing.T) {
+	t.Cleanup(func() { dig.CurrentResolver = dig.RealResolverForTest() })
+	dig.CurrentResolver = fakeNoDataResolver{}
+
+	f := NewFindings(ZoneMeta{Name: "zone"})
+	rs := rtypes.ResourceRecordSet{
+		Name:            aws.String("name.example.com"),
+		Type:            rtypes.RRTypeCname,
+		ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String("_x.acm-validations.aws")}},
+	}
+	checkCNameExists(context.Background(), f, rs)
+	require.Empty(t, f.MisconfigRecords)
+}
+
+func TestCheckCNameExists_ReportsEvidence(t *testing.T) {
+	t.Cleanup(func() { dig.CurrentResolver = dig.RealResolverForTest() })
+	dig.CurrentResolver = chainResolver{}
+
+	f := NewFindings(ZoneMeta{Name: "zone"})
+	rs := rtypes.ResourceRecordSet{
+		Name:            aws.String("name.example.com."),
+		Type:            rtypes.RRTypeCname,
+		ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String("dst.example.net.")}},
+	}
+	checkCNameExists(context.Background(), f, rs)
+	require.Len(t, f.MisconfigRecords, 1)
+	require.Equal(t, []string{
+		"name.example.com. CNAME dst.example.net.",
+		"dst.example.net. A NXDOMAIN",
+	}, f.MisconfigRecords[0].Evidence)
+}
+
+type chainResolver struct{}
+
+func (chainResolver) Resolve(ctx context.Context, domain string, t string) (*dig.Response, error) {
+	return &dig.Response{
+		Name:       domain,
+		Type:       t,
+		Rcode:      dns.RcodeNameError,
+		Answer:     []dig.Record{{Name: domain, Type: "CNAME", TTL: 300, Value: "dst.example.net."}},
+		CNAMEChain: []string{"dst.example.net."},
+	}, &dig.ResolveError{Domain: domain, Type: "NXDOMAIN"}
+}
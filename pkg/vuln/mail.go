@@ -32,6 +32,16 @@ func findByTypeAndName(rs []rtypes.ResourceRecordSet, t rtypes.RRType, name stri
 	return result
 }
 
+func findByName(rs []rtypes.ResourceRecordSet, name string) []rtypes.ResourceRecordSet {
+	var result []rtypes.ResourceRecordSet
+	for _, r := range rs {
+		if aws.ToString(r.Name) == name {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
 func findByType(rs []rtypes.ResourceRecordSet, t rtypes.RRType) []rtypes.ResourceRecordSet {
 	var result []rtypes.ResourceRecordSet
 	for _, r := range rs {
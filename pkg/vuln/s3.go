@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"net"
 	"net/http"
 	"strings"
 	"time"
@@ -21,10 +22,29 @@ var MISCONFIG = color.YellowString("[MISCONFIG]")
 var VULN = color.RedString("[VULN]")
 
 var cli = &http.Client{
-	Timeout: 3 * time.Second,
+	Timeout:   3 * time.Second,
+	Transport: probeTransport(),
 }
 
+// probeTransport is http.DefaultTransport, proxy settings included, with
+// host names resolved through the configured DNS resolvers.
+func probeTransport() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.DialContext = (&net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Resolver: dig.NetResolver()}).DialContext
+	return t
+}
+
+type HTTPErrorKind int
+
+const (
+	HTTPErrorTLS HTTPErrorKind = iota + 1
+	HTTPErrorNoSuchHost
+	HTTPErrorForbidden
+)
+
 type HTTPError struct {
+	Kind   HTTPErrorKind
+	TLS    TLSIssue
 	Reason string
 }
 
@@ -37,17 +57,14 @@ type domainCheck func(context.Context, *Findings, rtypes.ResourceRecordSet)
 func checkError(err error, t, k string, name string, f *Findings, record rtypes.ResourceRecordSet) {
 	var herr *HTTPError
 	if errors.As(err, &herr) {
-		switch herr.Reason {
-		case "SSL not configured":
-			f.MisconfigRecords = append(f.MisconfigRecords, MisConfigRRFromAWS(record, herr.Reason))
-			log.Printf("%s Zone %s has %s %s to %s but SSL is not configured\n", MISCONFIG, f.Name, t, k, name)
-		case "Invalid SSL certificate":
+		switch herr.Kind {
+		case HTTPErrorTLS:
 			f.MisconfigRecords = append(f.MisconfigRecords, MisConfigRRFromAWS(record, herr.Reason))
-			log.Printf("%s Zone %s has %s %s to %s but the SSL certificate is invalid\n", MISCONFIG, f.Name, t, k, name)
-		case "No such host":
+			log.Printf("%s Zone %s has %s %s to %s but TLS is misconfigured: %s\n", MISCONFIG, f.Name, t, k, name, herr.TLS)
+		case HTTPErrorNoSuchHost:
 			f.MisconfigRecords = append(f.MisconfigRecords, MisConfigRRFromAWS(record, herr.Reason))
 			log.Printf("%s Zone %s has %s %s to %s but the distribution does not exist\n", MISCONFIG, f.Name, t, k, name)
-		case "Forbidden":
+		case HTTPErrorForbidden:
 			f.MisconfigRecords = append(f.MisconfigRecords, MisConfigRRFromAWS(record, herr.Reason))
 			log.Printf("%s Zone %s has %s %s to %s S3 but the bucket is private\n", MISCONFIG, f.Name, t, k, name)
 		default:
@@ -56,6 +73,14 @@ func checkError(err error, t, k string, name string, f *Findings, record rtypes.
 	}
 }
 
+var tlsReasons = map[TLSIssue]string{
+	TLSNotConfigured:    "SSL not configured",
+	TLSExpired:          "Expired SSL certificate",
+	TLSHostnameMismatch: "SSL certificate hostname mismatch",
+	TLSSelfSigned:       "Self-signed SSL certificate",
+	TLSUntrusted:        "Invalid SSL certificate",
+}
+
 func valueInBody(b io.ReadCloser, v string) bool {
 	defer func() { _ = b.Close() }()
 	body, err := io.ReadAll(b)
@@ -72,23 +97,20 @@ func checkNoSuchBucket(ctx context.Context, name string) (bool, error) {
 	}
 	resp, err := cli.Do(req)
 	if err != nil {
-		if strings.Contains(err.Error(), "tls: handshake failure") {
-			log.Printf("SSL not configured for %s\n", name)
-			return false, &HTTPError{Reason: "SSL not configured"}
+		if issue, ok := classifyTLSError(err); ok {
+			log.Printf("TLS issue %s for %s\n", issue, name)
+			return false, &HTTPError{Kind: HTTPErrorTLS, TLS: issue, Reason: tlsReasons[issue]}
 		}
-		if strings.Contains(err.Error(), "tls: failed to verify certificate") {
-			log.Printf("Invalid SSL certificate for bucket %s\n", name)
-			return false, &HTTPError{Reason: "Invalid SSL certificate"}
-		}
-		if strings.Contains(err.Error(), "no such host") {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
 			log.Printf("Bucket %s does not exist\n", name)
-			return false, &HTTPError{Reason: "No such host"}
+			return false, &HTTPError{Kind: HTTPErrorNoSuchHost, Reason: "No such host"}
 		}
 		return false, nil
 	}
 	if resp.StatusCode == http.StatusForbidden {
 		log.Printf("%s exists but is private\n", name)
-		return false, &HTTPError{Reason: "Forbidden"}
+		return false, &HTTPError{Kind: HTTPErrorForbidden, Reason: "Forbidden"}
 	}
 	return resp.StatusCode == http.StatusNotFound && valueInBody(resp.Body, "Code: NoSuchBucket"), nil
 }
@@ -126,16 +148,9 @@ func checkAliasElasticBeanStalk(ctx context.Context, f *Findings, record rtypes.
 	if record.AliasTarget != nil && strings.HasSuffix(aws.ToString(record.AliasTarget.DNSName), ".elasticbeanstalk.com") {
 		name := aws.ToString(record.Name)
 		dst := aws.ToString(record.AliasTarget.DNSName)
-		err := dig.Resolve(ctx, name, "A")
-		if err == nil {
-			return
-		}
-		var derr *dig.ResolveError
-		if errors.As(err, &derr) {
-			if derr.Type == "NXDOMAIN" {
-				f.VulnerableRecords = append(f.VulnerableRecords, RRFromAWS(record))
-				log.Printf("%s Zone %s has an alias %s to Elastic Beanstalk %s but the domain does not exist\n", VULN, f.Name, name, dst)
-			}
+		if _, missing := resolveMissing(ctx, name); missing {
+			f.VulnerableRecords = append(f.VulnerableRecords, RRFromAWS(record))
+			log.Printf("%s Zone %s has an alias %s to Elastic Beanstalk %s but the domain does not exist\n", VULN, f.Name, name, dst)
 		}
 	}
 }
@@ -144,20 +159,11 @@ func checkCnameElasticBeanStalk(ctx context.Context, f *Findings, record rtypes.
 	if record.Type == rtypes.RRTypeCname && len(record.ResourceRecords) >= 1 && strings.HasSuffix(aws.ToString(record.ResourceRecords[0].Value), ".elasticbeanstalk.com") {
 		name := aws.ToString(record.Name)
 		dst := aws.ToString(record.ResourceRecords[0].Value)
-		err := dig.Resolve(ctx, name, "A")
-		if err == nil {
-			return
-		}
-		var derr *dig.ResolveError
-		if errors.As(err, &derr) {
-			if derr.Type == "NXDOMAIN" {
-				cerr := dig.Resolve(ctx, name, "CNAME")
-				if cerr == nil {
-					f.VulnerableRecords = append(f.VulnerableRecords, RRFromAWS(record))
-					log.Printf("%s Zone %s has a CNAME %s to Elastic Beanstalk %s but the domain does not exist\n", VULN, f.Name, name, dst)
-					return
-				}
-			}
+		// The CNAME must still be published for the dangling target to be
+		// claimable, i.e. the chain starts at name and ends in NXDOMAIN.
+		if resp, missing := resolveMissing(ctx, name); missing && len(resp.CNAMEChain) > 0 {
+			f.VulnerableRecords = append(f.VulnerableRecords, RRFromAWS(record))
+			log.Printf("%s Zone %s has a CNAME %s to Elastic Beanstalk %s but the domain does not exist\n", VULN, f.Name, name, dst)
 		}
 	}
 }
@@ -212,6 +218,10 @@ var subDomainTakeoverChecks = []domainCheck{
 }
 
 func SubDomainTakeoverCheck(ctx context.Context, f *Findings, record rtypes.ResourceRecordSet) {
+	// Wildcards have no concrete name to probe; WildcardCheck covers them.
+	if isWildcard(aws.ToString(record.Name)) {
+		return
+	}
 	for _, check := range subDomainTakeoverChecks {
 		check(ctx, f, record)
 	}
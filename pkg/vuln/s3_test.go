@@ -51,3 +51,12 @@ func TestS3CNameTakeover(t *testing.T) {
 		"GET http://test.example.com.s3-website-sa-east-1.amazonaws.com": 1,
 	}, httpmock.GetCallCountInfo())
 }
+
+func TestProbeTransport_KeepsDefaults(t *testing.T) {
+	tr := probeTransport()
+	assert.NotNil(t, tr.Proxy, "HTTPS_PROXY and friends are honoured")
+	assert.True(t, tr.ForceAttemptHTTP2)
+	assert.NotZero(t, tr.TLSHandshakeTimeout)
+	assert.NotZero(t, tr.MaxIdleConns)
+	assert.NotNil(t, tr.DialContext)
+}
@@ -0,0 +1,249 @@
+package vuln
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	rtypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/pedrokiefer/route53copy/pkg/dig"
+)
+
+type TLSIssue string
+
+const (
+	TLSNotConfigured    TLSIssue = "not_configured"
+	TLSExpired          TLSIssue = "expired"
+	TLSExpiringSoon     TLSIssue = "expiring_soon"
+	TLSHostnameMismatch TLSIssue = "hostname_mismatch"
+	TLSSelfSigned       TLSIssue = "self_signed"
+	TLSUntrusted        TLSIssue = "untrusted"
+	TLSWeakProtocol     TLSIssue = "weak_protocol"
+)
+
+type TLSFinding struct {
+	ResourceRecord
+	Issue    TLSIssue  `json:"issue"`
+	Detail   string    `json:"detail,omitempty"`
+	NotAfter time.Time `json:"not_after,omitzero"`
+}
+
+// TLSProbe is the outcome of a handshake against host:443.
+type TLSProbe struct {
+	Host     string
+	Version  uint16
+	NotAfter time.Time
+	Issues   []TLSIssue
+	Detail   map[TLSIssue]string
+}
+
+// dialTLS opens a TLS connection to host. It is a seam for tests.
+var dialTLS = func(ctx context.Context, host string, cfg *tls.Config) (*tls.Conn, error) {
+	d := &tls.Dialer{
+		NetDialer: &net.Dialer{Timeout: 5 * time.Second, Resolver: dig.NetResolver()},
+		Config:    cfg,
+	}
+	conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort(host, "443"))
+	if err != nil {
+		return nil, err
+	}
+	return conn.(*tls.Conn), nil
+}
+
+// verifyRoots is the pool used to verify certificates, nil means the system pool.
+var verifyRoots *x509.CertPool
+
+// errNotServingHTTPS is returned when nothing accepts connections on port 443.
+var errNotServingHTTPS = errors.New("not serving https")
+
+// ProbeTLS handshakes with host and classifies its certificate and protocol.
+// Certificates are verified after the handshake so a bad chain is reported
+// instead of aborting the probe.
+func ProbeTLS(ctx context.Context, host string, expiryWindow time.Duration) (*TLSProbe, error) {
+	host = strings.TrimSuffix(host, ".")
+	p := &TLSProbe{Host: host, Detail: map[TLSIssue]string{}}
+
+	conn, err := dialTLS(ctx, host, &tls.Config{
+		ServerName:         host,
+		InsecureSkipVerify: true, //nolint:gosec // verified below
+		MinVersion:         tls.VersionTLS10,
+	})
+	if err != nil {
+		var oe *net.OpError
+		if errors.As(err, &oe) && oe.Op == "dial" {
+			return nil, errNotServingHTTPS
+		}
+		if issue, ok := classifyTLSError(err); ok {
+			p.add(issue, err.Error())
+			return p, nil
+		}
+		return nil, err
+	}
+	state := conn.ConnectionState()
+	_ = conn.Close()
+
+	p.Version = state.Version
+	if state.Version < tls.VersionTLS12 {
+		p.add(TLSWeakProtocol, fmt.Sprintf("negotiated %s", tls.VersionName(state.Version)))
+	} else if weakProtocolAccepted(ctx, host) {
+		p.add(TLSWeakProtocol, "accepts TLS 1.1 or older")
+	}
+
+	if len(state.PeerCertificates) == 0 {
+		p.add(TLSNotConfigured, "no certificate presented")
+		return p, nil
+	}
+	leaf := state.PeerCertificates[0]
+	p.NotAfter = leaf.NotAfter
+
+	intermediates := x509.NewCertPool()
+	for _, c := range state.PeerCertificates[1:] {
+		intermediates.AddCert(c)
+	}
+	_, err = leaf.Verify(x509.VerifyOptions{
+		DNSName:       host,
+		Roots:         verifyRoots,
+		Intermediates: intermediates,
+	})
+	if err != nil {
+		if issue, ok := classifyTLSError(err); ok {
+			if issue == TLSUntrusted && isSelfSigned(leaf) {
+				issue = TLSSelfSigned
+			}
+			p.add(issue, err.Error())
+		}
+	}
+
+	if leaf.NotAfter.After(time.Now()) && time.Until(leaf.NotAfter) < expiryWindow {
+		p.add(TLSExpiringSoon, fmt.Sprintf("expires %s", leaf.NotAfter.Format(time.RFC3339)))
+	}
+	return p, nil
+}
+
+func (p *TLSProbe) add(issue TLSIssue, detail string) {
+	p.Issues = append(p.Issues, issue)
+	p.Detail[issue] = detail
+}
+
+func weakProtocolAccepted(ctx context.Context, host string) bool {
+	conn, err := dialTLS(ctx, host, &tls.Config{
+		ServerName:         host,
+		InsecureSkipVerify: true, //nolint:gosec // only the protocol version matters
+		MinVersion:         tls.VersionTLS10,
+		MaxVersion:         tls.VersionTLS11,
+	})
+	if err != nil {
+		return false
+	}
+	_ = conn.Close()
+	return true
+}
+
+func isSelfSigned(c *x509.Certificate) bool {
+	return c.Subject.String() == c.Issuer.String() && c.CheckSignatureFrom(c) == nil
+}
+
+// classifyTLSError maps handshake and verification errors to a TLSIssue.
+func classifyTLSError(err error) (TLSIssue, bool) {
+	var hostErr x509.HostnameError
+	if errors.As(err, &hostErr) {
+		return TLSHostnameMismatch, true
+	}
+	var invalidErr x509.CertificateInvalidError
+	if errors.As(err, &invalidErr) {
+		if invalidErr.Reason == x509.Expired {
+			return TLSExpired, true
+		}
+		return TLSUntrusted, true
+	}
+	var authErr x509.UnknownAuthorityError
+	if errors.As(err, &authErr) {
+		if authErr.Cert != nil && isSelfSigned(authErr.Cert) {
+			return TLSSelfSigned, true
+		}
+		return TLSUntrusted, true
+	}
+	var verifyErr *tls.CertificateVerificationError
+	if errors.As(err, &verifyErr) {
+		return TLSUntrusted, true
+	}
+	var alertErr tls.AlertError
+	if errors.As(err, &alertErr) {
+		return TLSNotConfigured, true
+	}
+	var recordErr tls.RecordHeaderError
+	if errors.As(err, &recordErr) {
+		return TLSNotConfigured, true
+	}
+	return "", false
+}
+
+func servesHTTPS(record rtypes.ResourceRecordSet) bool {
+	if isWildcard(aws.ToString(record.Name)) {
+		return false
+	}
+	switch record.Type {
+	case rtypes.RRTypeA, rtypes.RRTypeAaaa, rtypes.RRTypeCname:
+		return true
+	}
+	return false
+}
+
+// TLSCheck probes every name in rs that can serve HTTPS and records one
+// finding per issue found.
+func TLSCheck(ctx context.Context, f *Findings, rs []rtypes.ResourceRecordSet, expiryWindow time.Duration) {
+	byName := map[string]rtypes.ResourceRecordSet{}
+	for _, record := range rs {
+		if !servesHTTPS(record) {
+			continue
+		}
+		name := aws.ToString(record.Name)
+		if _, ok := byName[name]; !ok {
+			byName[name] = record
+		}
+	}
+	names := make([]string, 0, len(byName))
+	for n := range byName {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	var wg sync.WaitGroup
+	concurrentProbes := make(chan struct{}, 10)
+	results := make([][]TLSFinding, len(names))
+	for i, name := range names {
+		wg.Add(1)
+		concurrentProbes <- struct{}{}
+		go func(i int, record rtypes.ResourceRecordSet) {
+			defer wg.Done()
+			defer func() { <-concurrentProbes }()
+			p, err := ProbeTLS(ctx, aws.ToString(record.Name), expiryWindow)
+			if err != nil {
+				return
+			}
+			for _, issue := range p.Issues {
+				results[i] = append(results[i], TLSFinding{
+					ResourceRecord: RRFromAWS(record),
+					Issue:          issue,
+					Detail:         p.Detail[issue],
+					NotAfter:       p.NotAfter,
+				})
+				log.Printf("%s Zone %s has %s %s with TLS issue %s: %s\n", MISCONFIG, f.Name, record.Type, p.Host, issue, p.Detail[issue])
+			}
+		}(i, byName[name])
+	}
+	wg.Wait()
+
+	for _, r := range results {
+		f.TLSFindings = append(f.TLSFindings, r...)
+	}
+}
@@ -0,0 +1,132 @@
+package vuln
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	rtypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/stretchr/testify/require"
+)
+
+// useTLSServer points dialTLS at srv regardless of the probed host.
+func useTLSServer(t *testing.T, srv *httptest.Server, trusted bool) {
+	t.Helper()
+	oldDial, oldRoots := dialTLS, verifyRoots
+	t.Cleanup(func() { dialTLS = oldDial; verifyRoots = oldRoots })
+
+	dialTLS = func(ctx context.Context, host string, cfg *tls.Config) (*tls.Conn, error) {
+		d := &tls.Dialer{Config: cfg}
+		conn, err := d.DialContext(ctx, "tcp", srv.Listener.Addr().String())
+		if err != nil {
+			return nil, err
+		}
+		return conn.(*tls.Conn), nil
+	}
+	verifyRoots = nil
+	if trusted {
+		verifyRoots = x509.NewCertPool()
+		verifyRoots.AddCert(srv.Certificate())
+	}
+}
+
+func newTLSServer(t *testing.T, cfg *tls.Config) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.TLS = cfg
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestProbeTLS_SelfSigned(t *testing.T) {
+	srv := newTLSServer(t, nil)
+	useTLSServer(t, srv, false)
+
+	p, err := ProbeTLS(context.Background(), "example.com.", 0)
+	require.NoError(t, err)
+	require.Equal(t, []TLSIssue{TLSSelfSigned}, p.Issues)
+}
+
+func TestProbeTLS_TrustedAndExpiring(t *testing.T) {
+	srv := newTLSServer(t, nil)
+	useTLSServer(t, srv, true)
+
+	p, err := ProbeTLS(context.Background(), "example.com", 24*time.Hour)
+	require.NoError(t, err)
+	require.Empty(t, p.Issues)
+
+	p, err = ProbeTLS(context.Background(), "example.com", 100*365*24*time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, []TLSIssue{TLSExpiringSoon}, p.Issues)
+}
+
+func TestProbeTLS_HostnameMismatch(t *testing.T) {
+	srv := newTLSServer(t, nil)
+	useTLSServer(t, srv, true)
+
+	p, err := ProbeTLS(context.Background(), "other.example.net", 0)
+	require.NoError(t, err)
+	require.Equal(t, []TLSIssue{TLSHostnameMismatch}, p.Issues)
+}
+
+func TestProbeTLS_WeakProtocol(t *testing.T) {
+	srv := newTLSServer(t, &tls.Config{MinVersion: tls.VersionTLS10, MaxVersion: tls.VersionTLS11})
+	useTLSServer(t, srv, true)
+
+	p, err := ProbeTLS(context.Background(), "example.com", 0)
+	require.NoError(t, err)
+	require.Equal(t, []TLSIssue{TLSWeakProtocol}, p.Issues)
+}
+
+func TestProbeTLS_NotServing(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	require.NoError(t, ln.Close())
+
+	oldDial := dialTLS
+	t.Cleanup(func() { dialTLS = oldDial })
+	dialTLS = func(ctx context.Context, host string, cfg *tls.Config) (*tls.Conn, error) {
+		d := &tls.Dialer{Config: cfg}
+		conn, err := d.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return nil, err
+		}
+		return conn.(*tls.Conn), nil
+	}
+
+	_, err = ProbeTLS(context.Background(), "example.com", 0)
+	require.ErrorIs(t, err, errNotServingHTTPS)
+}
+
+func TestTLSCheck_RecordsFindings(t *testing.T) {
+	srv := newTLSServer(t, nil)
+	useTLSServer(t, srv, false)
+
+	f := NewFindings(ZoneMeta{Name: "example.com."})
+	rs := []rtypes.ResourceRecordSet{
+		{Name: aws.String("example.com."), Type: rtypes.RRTypeA, ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String("127.0.0.1")}}},
+		{Name: aws.String("example.com."), Type: rtypes.RRTypeAaaa, ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String("::1")}}},
+		{Name: aws.String("example.com."), Type: rtypes.RRTypeTxt, ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String(`"hello"`)}}},
+	}
+	TLSCheck(context.Background(), f, rs, 0)
+
+	require.Len(t, f.TLSFindings, 1)
+	require.Equal(t, TLSSelfSigned, f.TLSFindings[0].Issue)
+}
+
+func TestCheckError_TLSKind(t *testing.T) {
+	f := NewFindings(ZoneMeta{Name: "example.com."})
+	rs := rtypes.ResourceRecordSet{Name: aws.String("www.example.com."), Type: rtypes.RRTypeCname}
+	checkError(&HTTPError{Kind: HTTPErrorTLS, TLS: TLSExpired, Reason: tlsReasons[TLSExpired]}, "a CNAME", "S3", "www.example.com.", f, rs)
+
+	require.Len(t, f.MisconfigRecords, 1)
+	require.Equal(t, "Expired SSL certificate", f.MisconfigRecords[0].Reason)
+}
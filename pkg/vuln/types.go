@@ -3,11 +3,13 @@ package vuln
 import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	rtypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/pedrokiefer/route53copy/pkg/dig"
 )
 
 type ZoneMeta struct {
-	ZoneID string `json:"zone_id,omitempty"`
-	Name   string `json:"name,omitempty"`
+	Account string `json:"account,omitempty"`
+	ZoneID  string `json:"zone_id,omitempty"`
+	Name    string `json:"name,omitempty"`
 }
 
 type ResourceRecord struct {
@@ -19,18 +21,23 @@ type ResourceRecord struct {
 
 type MisConfigResourceRecord struct {
 	ResourceRecord
-	Reason string `json:"reason,omitempty"`
+	Reason   string   `json:"reason,omitempty"`
+	Evidence []string `json:"evidence,omitempty"`
 }
 
 type Findings struct {
+	Account           string                    `json:"account,omitempty"`
 	ZoneID            string                    `json:"zone_id,omitempty"`
 	Name              string                    `json:"name,omitempty"`
 	VulnerableRecords []ResourceRecord          `json:"vulnerable_records,omitempty"`
 	MisconfigRecords  []MisConfigResourceRecord `json:"misconfig_records,omitempty"`
+	Wildcards         []ResourceRecord          `json:"wildcards,omitempty"`
+	TLSFindings       []TLSFinding              `json:"tls_findings,omitempty"`
 }
 
 func NewFindings(zm ZoneMeta) *Findings {
 	return &Findings{
+		Account:           zm.Account,
 		ZoneID:            zm.ZoneID,
 		Name:              zm.Name,
 		VulnerableRecords: []ResourceRecord{},
@@ -59,3 +66,10 @@ func MisConfigRRFromAWS(awsRR rtypes.ResourceRecordSet, reason string) MisConfig
 	}
 	return rr
 }
+
+// MisConfigRRWithEvidence records the lookup that led to the finding.
+func MisConfigRRWithEvidence(awsRR rtypes.ResourceRecordSet, reason string, resp *dig.Response) MisConfigResourceRecord {
+	rr := MisConfigRRFromAWS(awsRR, reason)
+	rr.Evidence = resp.Evidence()
+	return rr
+}
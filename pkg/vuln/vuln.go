@@ -3,18 +3,32 @@ package vuln
 import (
 	"context"
 	"log"
+	"time"
 
 	rtypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
 )
 
-func Scan(ctx context.Context, zm ZoneMeta, rs []rtypes.ResourceRecordSet) *Findings {
+type ScanOptions struct {
+	// SkipTLS disables probing HTTPS endpoints.
+	SkipTLS bool
+	// TLSExpiryDays reports certificates expiring within this many days.
+	TLSExpiryDays int
+}
+
+func Scan(ctx context.Context, zm ZoneMeta, rs []rtypes.ResourceRecordSet, o ScanOptions) *Findings {
 	f := NewFindings(zm)
 	log.Printf("Checking zone %s:\n", WhiteBold.Sprint(zm.Name))
 	log.Printf(" - %s...\n", WhiteBold.Sprintf("Checking mail vulnerabilities"))
 	MailCheck(ctx, f, rs)
+	log.Printf(" - %s...\n", WhiteBold.Sprintf("Checking wildcard records"))
+	WildcardCheck(ctx, f, rs)
 	log.Printf(" - %s...\n", WhiteBold.Sprintf("Checking subdomain takeover"))
 	for _, entry := range rs {
 		SubDomainTakeoverCheck(ctx, f, entry)
 	}
+	if !o.SkipTLS {
+		log.Printf(" - %s...\n", WhiteBold.Sprintf("Checking TLS certificates"))
+		TLSCheck(ctx, f, rs, time.Duration(o.TLSExpiryDays)*24*time.Hour)
+	}
 	return f
 }
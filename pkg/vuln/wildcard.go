@@ -0,0 +1,150 @@
+package vuln
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	rtypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
+)
+
+// wildcardProbeLabel replaces the "*" label when a concrete hostname is
+// needed to exercise a wildcard record over HTTP.
+const wildcardProbeLabel = "r53tool-wildcard-probe"
+
+// isWildcard reports whether name is a wildcard owner. Route53 returns the
+// asterisk escaped as \052 in ListResourceRecordSets.
+func isWildcard(name string) bool {
+	return strings.HasPrefix(name, "*.") || strings.HasPrefix(name, `\052.`)
+}
+
+// wildcardParent returns the name a wildcard record covers, e.g.
+// "example.com." for "*.example.com.".
+func wildcardParent(name string) string {
+	_, parent, _ := strings.Cut(name, ".")
+	return parent
+}
+
+func wildcardTarget(record rtypes.ResourceRecordSet) string {
+	if record.AliasTarget != nil {
+		return aws.ToString(record.AliasTarget.DNSName)
+	}
+	if record.Type == rtypes.RRTypeCname && len(record.ResourceRecords) > 0 {
+		return aws.ToString(record.ResourceRecords[0].Value)
+	}
+	return ""
+}
+
+func isBucketTarget(dst string) bool {
+	dst = strings.TrimSuffix(dst, ".")
+	return (strings.HasSuffix(dst, "amazonaws.com") && strings.Contains(dst, "s3")) ||
+		strings.Contains(dst, ".s3-website") ||
+		strings.HasSuffix(dst, ".cloudfront.net")
+}
+
+func WildcardCheck(ctx context.Context, f *Findings, rs []rtypes.ResourceRecordSet) {
+	for _, record := range rs {
+		if !isWildcard(aws.ToString(record.Name)) {
+			continue
+		}
+		f.Wildcards = append(f.Wildcards, RRFromAWS(record))
+		checkWildcardTarget(ctx, f, record)
+		checkWildcardShadowsMail(f, record, rs)
+	}
+}
+
+func checkWildcardTarget(ctx context.Context, f *Findings, record rtypes.ResourceRecordSet) {
+	dst := wildcardTarget(record)
+	if dst == "" {
+		return
+	}
+	name := aws.ToString(record.Name)
+
+	if resp, missing := resolveMissing(ctx, dst); missing {
+		f.MisconfigRecords = append(f.MisconfigRecords, MisConfigRRWithEvidence(record, "Wildcard points to missing name", resp))
+		log.Printf("%s Zone %s has wildcard %s %s pointing to missing name %s\n", MISCONFIG, f.Name, record.Type, name, resp.Target())
+		return
+	}
+
+	if !isBucketTarget(dst) {
+		return
+	}
+
+	// CloudFront answers by Host header, so it must be probed through a
+	// name covered by the wildcard rather than the distribution itself.
+	host := strings.TrimSuffix(dst, ".")
+	if strings.HasSuffix(host, ".cloudfront.net") {
+		host = wildcardProbeLabel + "." + strings.TrimSuffix(wildcardParent(name), ".")
+	}
+	nok, err := checkNoSuchBucket(ctx, host)
+	if err != nil {
+		checkError(err, "a wildcard", string(record.Type), name, f, record)
+	}
+	if nok {
+		f.VulnerableRecords = append(f.VulnerableRecords, RRFromAWS(record))
+		log.Printf("%s Zone %s has wildcard %s %s to %s but the bucket does not exist\n", VULN, f.Name, record.Type, name, dst)
+	}
+}
+
+// isNullMX reports whether record is a null MX ("0 ."), which says the name
+// takes no mail (RFC 7505).
+func isNullMX(record rtypes.ResourceRecordSet) bool {
+	if record.Type != rtypes.RRTypeMx || len(record.ResourceRecords) != 1 {
+		return false
+	}
+	return slices.Equal(strings.Fields(aws.ToString(record.ResourceRecords[0].Value)), []string{"0", "."})
+}
+
+// acceptsMail reports whether any MX of the zone is not a null MX.
+func acceptsMail(rs []rtypes.ResourceRecordSet) bool {
+	for _, r := range findByType(rs, rtypes.RRTypeMx) {
+		if !isNullMX(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasNullMX reports whether name holds a null MX in the zone, so MX lookups
+// following a CNAME to it find no mail server.
+func hasNullMX(rs []rtypes.ResourceRecordSet, name string) bool {
+	name = strings.TrimSuffix(name, ".") + "."
+	for _, r := range findByType(rs, rtypes.RRTypeMx) {
+		if strings.EqualFold(aws.ToString(r.Name), name) && isNullMX(r) {
+			return true
+		}
+	}
+	return false
+}
+
+func checkWildcardShadowsMail(f *Findings, record rtypes.ResourceRecordSet, rs []rtypes.ResourceRecordSet) {
+	name := aws.ToString(record.Name)
+	parent := wildcardParent(name)
+
+	switch record.Type {
+	case rtypes.RRTypeMx:
+		f.MisconfigRecords = append(f.MisconfigRecords, MisConfigRRFromAWS(record, "Wildcard MX accepts mail for every subdomain"))
+		log.Printf("%s Zone %s has wildcard MX %s, every subdomain accepts mail\n", MISCONFIG, f.Name, name)
+		return
+	case rtypes.RRTypeCname:
+		if acceptsMail(rs) && !hasNullMX(rs, wildcardTarget(record)) {
+			f.MisconfigRecords = append(f.MisconfigRecords, MisConfigRRFromAWS(record, "Wildcard CNAME shadows MX lookups"))
+			log.Printf("%s Zone %s has wildcard CNAME %s, MX lookups for undefined subdomains follow it\n", MISCONFIG, f.Name, name)
+		}
+	case rtypes.RRTypeTxt:
+	default:
+		return
+	}
+
+	// A CNAME or TXT wildcard answers TXT queries for _dmarc.<parent> unless
+	// the record exists explicitly.
+	dmarc := fmt.Sprintf("_dmarc.%s", parent)
+	if len(findByName(rs, dmarc)) > 0 {
+		return
+	}
+	f.MisconfigRecords = append(f.MisconfigRecords, MisConfigRRFromAWS(record, "Wildcard shadows _dmarc lookup"))
+	log.Printf("%s Zone %s has wildcard %s %s answering %s lookups\n", MISCONFIG, f.Name, record.Type, name, dmarc)
+}
@@ -0,0 +1,155 @@
+package vuln
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	rtypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/jarcoal/httpmock"
+	"github.com/pedrokiefer/route53copy/pkg/dig"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsWildcard(t *testing.T) {
+	require.True(t, isWildcard("*.example.com."))
+	require.True(t, isWildcard(`\052.example.com.`))
+	require.False(t, isWildcard("www.example.com."))
+	require.Equal(t, "example.com.", wildcardParent(`\052.example.com.`))
+}
+
+func TestWildcardCheck_DanglingTarget(t *testing.T) {
+	t.Cleanup(func() { dig.CurrentResolver = dig.RealResolverForTest() })
+	dig.CurrentResolver = fakeNXResolver{}
+
+	f := NewFindings(ZoneMeta{Name: "example.com."})
+	rs := []rtypes.ResourceRecordSet{
+		{
+			Name:            aws.String(`\052.example.com.`),
+			Type:            rtypes.RRTypeCname,
+			ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String("gone.example.net")}},
+		},
+		{
+			Name:            aws.String("_dmarc.example.com."),
+			Type:            rtypes.RRTypeTxt,
+			ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String(`"v=DMARC1; p=reject;"`)}},
+		},
+	}
+	WildcardCheck(context.Background(), f, rs)
+
+	require.Len(t, f.Wildcards, 1)
+	require.Len(t, f.MisconfigRecords, 1)
+	require.Equal(t, "Wildcard points to missing name", f.MisconfigRecords[0].Reason)
+}
+
+func TestWildcardCheck_BucketTakeover(t *testing.T) {
+	t.Cleanup(func() { dig.CurrentResolver = dig.RealResolverForTest() })
+	dig.CurrentResolver = fakeNoDataResolver{}
+
+	httpmock.ActivateNonDefault(cli)
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("GET", "http://bucket.s3-website-us-east-1.amazonaws.com",
+		httpmock.NewStringResponder(404, "Code: NoSuchBucket"))
+
+	f := NewFindings(ZoneMeta{Name: "example.com."})
+	rs := []rtypes.ResourceRecordSet{
+		{
+			Name:        aws.String("*.example.com."),
+			Type:        rtypes.RRTypeA,
+			AliasTarget: &rtypes.AliasTarget{DNSName: aws.String("bucket.s3-website-us-east-1.amazonaws.com.")},
+		},
+	}
+	WildcardCheck(context.Background(), f, rs)
+
+	require.Len(t, f.VulnerableRecords, 1)
+	require.Empty(t, f.MisconfigRecords)
+}
+
+func TestWildcardCheck_ShadowsMail(t *testing.T) {
+	t.Cleanup(func() { dig.CurrentResolver = dig.RealResolverForTest() })
+	dig.CurrentResolver = fakeNoDataResolver{}
+
+	f := NewFindings(ZoneMeta{Name: "example.com."})
+	rs := []rtypes.ResourceRecordSet{
+		{
+			Name:            aws.String("example.com."),
+			Type:            rtypes.RRTypeMx,
+			ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String("10 mail.example.com.")}},
+		},
+		{
+			Name:            aws.String(`\052.example.com.`),
+			Type:            rtypes.RRTypeCname,
+			ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String("app.example.net")}},
+		},
+		{
+			Name:            aws.String(`\052.example.com.`),
+			Type:            rtypes.RRTypeMx,
+			ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String("10 mail.example.com.")}},
+		},
+	}
+	WildcardCheck(context.Background(), f, rs)
+
+	reasons := []string{}
+	for _, m := range f.MisconfigRecords {
+		reasons = append(reasons, m.Reason)
+	}
+	require.ElementsMatch(t, []string{
+		"Wildcard CNAME shadows MX lookups",
+		"Wildcard shadows _dmarc lookup",
+		"Wildcard MX accepts mail for every subdomain",
+	}, reasons)
+}
+
+func TestWildcardCheck_ParkedTemplate(t *testing.T) {
+	t.Cleanup(func() { dig.CurrentResolver = dig.RealResolverForTest() })
+	dig.CurrentResolver = fakeNoDataResolver{}
+
+	// The records park writes with its default template: a null MX at the
+	// apex and a wildcard CNAME to it.
+	f := NewFindings(ZoneMeta{Name: "example.com."})
+	rs := []rtypes.ResourceRecordSet{
+		{
+			Name:            aws.String("example.com."),
+			Type:            rtypes.RRTypeMx,
+			ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String("0 .")}},
+		},
+		{
+			Name:            aws.String("_dmarc.example.com."),
+			Type:            rtypes.RRTypeTxt,
+			ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String(`"v=DMARC1; p=reject; sp=reject; adkim=s; aspf=s"`)}},
+		},
+		{
+			Name:            aws.String(`\052.example.com.`),
+			Type:            rtypes.RRTypeCname,
+			ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String("example.com.")}},
+		},
+	}
+	WildcardCheck(context.Background(), f, rs)
+
+	require.Len(t, f.Wildcards, 1)
+	require.Empty(t, f.MisconfigRecords)
+
+	// A wildcard CNAME to a name that takes no mail is fine even when other
+	// names of the zone do.
+	rs = append(rs, rtypes.ResourceRecordSet{
+		Name:            aws.String("shop.example.com."),
+		Type:            rtypes.RRTypeMx,
+		ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String("10 mail.example.net.")}},
+	})
+	f = NewFindings(ZoneMeta{Name: "example.com."})
+	WildcardCheck(context.Background(), f, rs)
+	require.Empty(t, f.MisconfigRecords)
+}
+
+func TestSubDomainTakeoverCheck_SkipsWildcards(t *testing.T) {
+	t.Cleanup(func() { dig.CurrentResolver = dig.RealResolverForTest() })
+	dig.CurrentResolver = fakeNXResolver{}
+
+	f := NewFindings(ZoneMeta{Name: "example.com."})
+	SubDomainTakeoverCheck(context.Background(), f, rtypes.ResourceRecordSet{
+		Name:            aws.String(`\052.example.com.`),
+		Type:            rtypes.RRTypeCname,
+		ResourceRecords: []rtypes.ResourceRecord{{Value: aws.String("gone.example.net")}},
+	})
+	require.Empty(t, f.MisconfigRecords)
+}